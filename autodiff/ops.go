@@ -0,0 +1,248 @@
+package autodiff
+
+import (
+	"fmt"
+	"math"
+
+	"golang-neural-network/internal/numeric"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// 與 gonum 相同，形狀不符時 panic（屬於程式錯誤，不是執行期的資料錯誤）
+
+// MatMul a * b
+func (t *Tape) MatMul(a, b *Node) *Node {
+	var value mat.Dense
+	value.Mul(a.Value, b.Value)
+	var out *Node
+	out = t.record(&value, requiresGrad(a, b), func() {
+		if a.requiresGrad {
+			var ga mat.Dense
+			ga.Mul(out.Grad, b.Value.T())
+			a.accumulate(&ga)
+		}
+		if b.requiresGrad {
+			var gb mat.Dense
+			gb.Mul(a.Value.T(), out.Grad)
+			b.accumulate(&gb)
+		}
+	})
+	return out
+}
+
+// Add a + b，b 可以是 r x 1 的 column vector，會加到 a 的每一個 column（例如 bias）
+func (t *Tape) Add(a, b *Node) *Node {
+	r, c := a.Value.Dims()
+	br, bc := b.Value.Dims()
+	broadcast := bc == 1 && c != 1
+	if br != r || (bc != c && !broadcast) {
+		panic(fmt.Sprintf("autodiff: Add shape mismatch %dx%d + %dx%d", r, c, br, bc))
+	}
+	value := mat.NewDense(r, c, nil)
+	value.Apply(func(i, j int, v float64) float64 {
+		if broadcast {
+			return v + b.Value.At(i, 0)
+		}
+		return v + b.Value.At(i, j)
+	}, a.Value)
+	var out *Node
+	out = t.record(value, requiresGrad(a, b), func() {
+		a.accumulate(out.Grad)
+		if !broadcast {
+			b.accumulate(out.Grad)
+			return
+		}
+		gb := mat.NewDense(r, 1, nil)
+		for i := 0; i < r; i++ {
+			gb.Set(i, 0, mat.Sum(out.Grad.RowView(i)))
+		}
+		b.accumulate(gb)
+	})
+	return out
+}
+
+// Sub a - b（相同形狀）
+func (t *Tape) Sub(a, b *Node) *Node {
+	return t.Add(a, t.Scale(b, -1))
+}
+
+// Mul 逐元素相乘 a ⊙ b
+func (t *Tape) Mul(a, b *Node) *Node {
+	var value mat.Dense
+	value.MulElem(a.Value, b.Value)
+	var out *Node
+	out = t.record(&value, requiresGrad(a, b), func() {
+		if a.requiresGrad {
+			var ga mat.Dense
+			ga.MulElem(out.Grad, b.Value)
+			a.accumulate(&ga)
+		}
+		if b.requiresGrad {
+			var gb mat.Dense
+			gb.MulElem(out.Grad, a.Value)
+			b.accumulate(&gb)
+		}
+	})
+	return out
+}
+
+// Scale s * a
+func (t *Tape) Scale(a *Node, s float64) *Node {
+	var value mat.Dense
+	value.Scale(s, a.Value)
+	var out *Node
+	out = t.record(&value, a.requiresGrad, func() {
+		var ga mat.Dense
+		ga.Scale(s, out.Grad)
+		a.accumulate(&ga)
+	})
+	return out
+}
+
+// elementwise 逐元素函數 y = f(x)，df 以 x 與 y 計算導數
+func (t *Tape) elementwise(a *Node, f func(x float64) float64, df func(x, y float64) float64) *Node {
+	r, c := a.Value.Dims()
+	value := mat.NewDense(r, c, nil)
+	value.Apply(func(i, j int, x float64) float64 { return f(x) }, a.Value)
+	var out *Node
+	out = t.record(value, a.requiresGrad, func() {
+		ga := mat.NewDense(r, c, nil)
+		ga.Apply(func(i, j int, g float64) float64 {
+			return g * df(a.Value.At(i, j), value.At(i, j))
+		}, out.Grad)
+		a.accumulate(ga)
+	})
+	return out
+}
+
+// ReLU max(x, 0)，x = 0 時導數取 0
+func (t *Tape) ReLU(a *Node) *Node {
+	return t.elementwise(a,
+		func(x float64) float64 { return math.Max(x, 0) },
+		func(x, y float64) float64 {
+			if x > 0 {
+				return 1
+			}
+			return 0
+		})
+}
+
+func (t *Tape) Sigmoid(a *Node) *Node {
+	return t.elementwise(a,
+		func(x float64) float64 { return 1 / (1 + math.Exp(-x)) },
+		func(x, y float64) float64 { return y * (1 - y) })
+}
+
+func (t *Tape) Tanh(a *Node) *Node {
+	return t.elementwise(a, math.Tanh, func(x, y float64) float64 { return 1 - y*y })
+}
+
+func (t *Tape) Exp(a *Node) *Node {
+	return t.elementwise(a, math.Exp, func(x, y float64) float64 { return y })
+}
+
+// Log 自然對數，x <= 0 時結果為 -Inf 或 NaN，需要數值穩定時請用 LogSoftmax
+func (t *Tape) Log(a *Node) *Node {
+	return t.elementwise(a, math.Log, func(x, y float64) float64 { return 1 / x })
+}
+
+// Softmax 對每一個 column 做 softmax
+// backward: dx_i = y_i (g_i - Σ_k g_k y_k)
+func (t *Tape) Softmax(a *Node) *Node {
+	value := softmaxColumns(a.Value)
+	r, c := value.Dims()
+	var out *Node
+	out = t.record(value, a.requiresGrad, func() {
+		ga := mat.NewDense(r, c, nil)
+		for j := 0; j < c; j++ {
+			dot := 0.0
+			for i := 0; i < r; i++ {
+				dot += out.Grad.At(i, j) * value.At(i, j)
+			}
+			for i := 0; i < r; i++ {
+				ga.Set(i, j, value.At(i, j)*(out.Grad.At(i, j)-dot))
+			}
+		}
+		a.accumulate(ga)
+	})
+	return out
+}
+
+// LogSoftmax 對每一個 column 計算 x - logsumexp(x)
+// backward: dx_i = g_i - softmax(x)_i Σ_k g_k
+func (t *Tape) LogSoftmax(a *Node) *Node {
+	r, c := a.Value.Dims()
+	value := mat.NewDense(r, c, nil)
+	for j := 0; j < c; j++ {
+		max, logSum := numeric.ColumnLogSumExp(a.Value, j)
+		for i := 0; i < r; i++ {
+			value.Set(i, j, (a.Value.At(i, j)-max)-logSum)
+		}
+	}
+	var out *Node
+	out = t.record(value, a.requiresGrad, func() {
+		ga := mat.NewDense(r, c, nil)
+		for j := 0; j < c; j++ {
+			sum := 0.0
+			for i := 0; i < r; i++ {
+				sum += out.Grad.At(i, j)
+			}
+			for i := 0; i < r; i++ {
+				ga.Set(i, j, out.Grad.At(i, j)-math.Exp(value.At(i, j))*sum)
+			}
+		}
+		a.accumulate(ga)
+	})
+	return out
+}
+
+// Sum 所有元素的總和，結果為 1 x 1
+func (t *Tape) Sum(a *Node) *Node {
+	r, c := a.Value.Dims()
+	value := mat.NewDense(1, 1, []float64{mat.Sum(a.Value)})
+	var out *Node
+	out = t.record(value, a.requiresGrad, func() {
+		g := out.Grad.At(0, 0)
+		ga := mat.NewDense(r, c, nil)
+		ga.Apply(func(i, j int, v float64) float64 { return g }, ga)
+		a.accumulate(ga)
+	})
+	return out
+}
+
+// Reshape 以 row-major 順序改變形狀，元素數量必須相同
+func (t *Tape) Reshape(a *Node, rows, cols int) *Node {
+	r, c := a.Value.Dims()
+	if rows*cols != r*c {
+		panic(fmt.Sprintf("autodiff: can't reshape %dx%d to %dx%d", r, c, rows, cols))
+	}
+	value := mat.NewDense(rows, cols, flatten(a.Value))
+	var out *Node
+	out = t.record(value, a.requiresGrad, func() {
+		a.accumulate(mat.NewDense(r, c, flatten(out.Grad)))
+	})
+	return out
+}
+
+// flatten 以 row-major 順序複製矩陣的所有元素
+func flatten(m *mat.Dense) []float64 {
+	r, c := m.Dims()
+	data := make([]float64, 0, r*c)
+	for i := 0; i < r; i++ {
+		data = append(data, m.RawRowView(i)...)
+	}
+	return data
+}
+
+func softmaxColumns(m *mat.Dense) *mat.Dense {
+	r, c := m.Dims()
+	result := mat.NewDense(r, c, nil)
+	for j := 0; j < c; j++ {
+		max, logSum := numeric.ColumnLogSumExp(m, j)
+		for i := 0; i < r; i++ {
+			result.Set(i, j, math.Exp((m.At(i, j)-max)-logSum))
+		}
+	}
+	return result
+}
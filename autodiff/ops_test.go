@@ -0,0 +1,157 @@
+package autodiff
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func randomMatrix(rng *rand.Rand, r, c int) *mat.Dense {
+	m := mat.NewDense(r, c, nil)
+	m.Apply(func(i, j int, v float64) float64 { return rng.NormFloat64() }, m)
+	return m
+}
+
+// checkGradients 比較 Backward 的梯度與中央差分
+// 輸出先和固定的隨機矩陣逐元素相乘再加總，讓每個輸出元素的上游梯度都不同
+func checkGradients(t *testing.T, name string, build func(tape *Tape, inputs []*Node) *Node, inputs ...*mat.Dense) {
+	t.Helper()
+	rng := rand.New(rand.NewSource(1))
+	var projection *mat.Dense
+	loss := func() (*Tape, []*Node, *Node) {
+		tape := NewTape()
+		nodes := make([]*Node, len(inputs))
+		for i, input := range inputs {
+			nodes[i] = tape.Variable(input)
+		}
+		out := build(tape, nodes)
+		if projection == nil {
+			r, c := out.Value.Dims()
+			projection = randomMatrix(rng, r, c)
+		}
+		return tape, nodes, tape.Sum(tape.Mul(out, tape.Constant(projection)))
+	}
+
+	tape, nodes, out := loss()
+	if err := tape.Backward(out); err != nil {
+		t.Fatal(err)
+	}
+	const eps = 1e-6
+	for k, input := range inputs {
+		r, c := input.Dims()
+		for i := 0; i < r; i++ {
+			for j := 0; j < c; j++ {
+				original := input.At(i, j)
+				input.Set(i, j, original+eps)
+				_, _, plus := loss()
+				input.Set(i, j, original-eps)
+				_, _, minus := loss()
+				input.Set(i, j, original)
+
+				numeric := (plus.Value.At(0, 0) - minus.Value.At(0, 0)) / (2 * eps)
+				analytic := 0.0
+				if nodes[k].Grad != nil {
+					analytic = nodes[k].Grad.At(i, j)
+				}
+				if math.Abs(numeric-analytic) > 1e-6*math.Max(1, math.Abs(numeric)) {
+					t.Errorf("%s: d/dinput%d[%d,%d] = %v, finite difference %v", name, k, i, j, analytic, numeric)
+				}
+			}
+		}
+	}
+}
+
+func TestOpGradients(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	positive := randomMatrix(rng, 3, 2)
+	positive.Apply(func(i, j int, v float64) float64 { return math.Abs(v) + 0.5 }, positive)
+
+	tests := []struct {
+		name   string
+		build  func(tape *Tape, in []*Node) *Node
+		inputs []*mat.Dense
+	}{
+		{"matmul", func(tape *Tape, in []*Node) *Node { return tape.MatMul(in[0], in[1]) },
+			[]*mat.Dense{randomMatrix(rng, 3, 4), randomMatrix(rng, 4, 2)}},
+		{"add", func(tape *Tape, in []*Node) *Node { return tape.Add(in[0], in[1]) },
+			[]*mat.Dense{randomMatrix(rng, 3, 2), randomMatrix(rng, 3, 2)}},
+		{"add broadcast bias", func(tape *Tape, in []*Node) *Node { return tape.Add(in[0], in[1]) },
+			[]*mat.Dense{randomMatrix(rng, 3, 4), randomMatrix(rng, 3, 1)}},
+		{"sub", func(tape *Tape, in []*Node) *Node { return tape.Sub(in[0], in[1]) },
+			[]*mat.Dense{randomMatrix(rng, 2, 2), randomMatrix(rng, 2, 2)}},
+		{"mul", func(tape *Tape, in []*Node) *Node { return tape.Mul(in[0], in[1]) },
+			[]*mat.Dense{randomMatrix(rng, 2, 3), randomMatrix(rng, 2, 3)}},
+		{"scale", func(tape *Tape, in []*Node) *Node { return tape.Scale(in[0], -2.5) },
+			[]*mat.Dense{randomMatrix(rng, 2, 3)}},
+		{"relu", func(tape *Tape, in []*Node) *Node { return tape.ReLU(in[0]) },
+			[]*mat.Dense{randomMatrix(rng, 3, 3)}},
+		{"sigmoid", func(tape *Tape, in []*Node) *Node { return tape.Sigmoid(in[0]) },
+			[]*mat.Dense{randomMatrix(rng, 3, 2)}},
+		{"tanh", func(tape *Tape, in []*Node) *Node { return tape.Tanh(in[0]) },
+			[]*mat.Dense{randomMatrix(rng, 3, 2)}},
+		{"exp", func(tape *Tape, in []*Node) *Node { return tape.Exp(in[0]) },
+			[]*mat.Dense{randomMatrix(rng, 3, 2)}},
+		{"log", func(tape *Tape, in []*Node) *Node { return tape.Log(in[0]) },
+			[]*mat.Dense{positive}},
+		{"softmax", func(tape *Tape, in []*Node) *Node { return tape.Softmax(in[0]) },
+			[]*mat.Dense{randomMatrix(rng, 4, 3)}},
+		{"log softmax", func(tape *Tape, in []*Node) *Node { return tape.LogSoftmax(in[0]) },
+			[]*mat.Dense{randomMatrix(rng, 4, 3)}},
+		{"sum", func(tape *Tape, in []*Node) *Node { return tape.Sum(in[0]) },
+			[]*mat.Dense{randomMatrix(rng, 2, 3)}},
+		{"reshape", func(tape *Tape, in []*Node) *Node { return tape.MatMul(tape.Reshape(in[0], 3, 2), in[1]) },
+			[]*mat.Dense{randomMatrix(rng, 2, 3), randomMatrix(rng, 2, 2)}},
+		{"reused node", func(tape *Tape, in []*Node) *Node { return tape.Mul(tape.Tanh(in[0]), in[0]) },
+			[]*mat.Dense{randomMatrix(rng, 2, 2)}},
+		{"dense layer", func(tape *Tape, in []*Node) *Node {
+			return tape.LogSoftmax(tape.Add(tape.MatMul(in[0], tape.Sigmoid(in[1])), in[2]))
+		}, []*mat.Dense{randomMatrix(rng, 4, 3), randomMatrix(rng, 3, 5), randomMatrix(rng, 4, 1)}},
+	}
+	for _, tt := range tests {
+		checkGradients(t, tt.name, tt.build, tt.inputs...)
+	}
+}
+
+func TestCrossEntropyFromOps(t *testing.T) {
+	// -Σ target ⊙ log(softmax(z)) 與 LogSoftmax 的版本結果相同，梯度為 softmax(z) - target
+	logits := mat.NewDense(3, 1, []float64{0.5, -1, 2})
+	target := mat.NewDense(3, 1, []float64{0, 1, 0})
+
+	tape := NewTape()
+	z := tape.Variable(logits)
+	viaLog := tape.Scale(tape.Sum(tape.Mul(tape.Constant(target), tape.Log(tape.Softmax(z)))), -1)
+	viaLogSoftmax := tape.Scale(tape.Sum(tape.Mul(tape.Constant(target), tape.LogSoftmax(z))), -1)
+	if math.Abs(viaLog.Value.At(0, 0)-viaLogSoftmax.Value.At(0, 0)) > 1e-12 {
+		t.Errorf("loss %v vs %v", viaLog.Value.At(0, 0), viaLogSoftmax.Value.At(0, 0))
+	}
+	if err := tape.Backward(viaLogSoftmax); err != nil {
+		t.Fatal(err)
+	}
+	want := softmaxColumns(logits)
+	want.Sub(want, target)
+	if !mat.EqualApprox(z.Grad, want, 1e-12) {
+		t.Errorf("grad %v, want %v", mat.Formatted(z.Grad.T()), mat.Formatted(want.T()))
+	}
+}
+
+func TestBackwardErrors(t *testing.T) {
+	tape := NewTape()
+	x := tape.Variable(mat.NewDense(2, 1, []float64{1, 2}))
+	if err := tape.Backward(x); err == nil {
+		t.Error("Backward accepted a 2x1 output")
+	}
+	other := NewTape().Variable(mat.NewDense(1, 1, []float64{1}))
+	if err := tape.Backward(other); err == nil {
+		t.Error("Backward accepted a node from another tape")
+	}
+	constant := tape.Constant(mat.NewDense(1, 1, []float64{3}))
+	y := tape.Mul(tape.Sum(x), constant)
+	if err := tape.Backward(y); err != nil {
+		t.Fatal(err)
+	}
+	if constant.Grad != nil {
+		t.Error("constant node received a gradient")
+	}
+}
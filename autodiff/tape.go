@@ -0,0 +1,114 @@
+// Package autodiff 以 tape 記錄運算的 reverse-mode 自動微分，數值為 gonum 的矩陣
+//
+// 每個運算在 forward 時立即計算結果，並把對應的 backward 函數記錄在 tape 上；
+// Backward 依相反的順序呼叫這些函數，把梯度累加到每個節點的 Grad
+//
+//	tape := autodiff.NewTape()
+//	w := tape.Variable(weight)
+//	x := tape.Constant(input)
+//	y := tape.ReLU(tape.Add(tape.MatMul(w, x), tape.Variable(bias)))
+//	tape.Backward(tape.Sum(y))
+//	// w.Grad 為 d sum(y) / dW
+package autodiff
+
+import (
+	"fmt"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Node 計算圖中的一個矩陣
+type Node struct {
+	Value *mat.Dense
+	Grad  *mat.Dense // Backward 之後為 loss 對 Value 的梯度；不需要梯度或沒有被用到時為 nil
+
+	requiresGrad bool
+	backward     func() // 把 Grad 傳遞給輸入節點
+}
+
+// Tape 依序記錄所有節點，同一個 tape 只能給一個 goroutine 使用
+type Tape struct {
+	nodes []*Node
+}
+
+func NewTape() *Tape {
+	return &Tape{}
+}
+
+// Variable 需要計算梯度的葉節點（例如權重），不會複製 value
+func (t *Tape) Variable(value *mat.Dense) *Node {
+	return t.record(value, true, nil)
+}
+
+// Constant 不需要梯度的葉節點（例如輸入與 target）
+func (t *Tape) Constant(value *mat.Dense) *Node {
+	return t.record(value, false, nil)
+}
+
+func (t *Tape) record(value *mat.Dense, requiresGrad bool, backward func()) *Node {
+	n := &Node{Value: value, requiresGrad: requiresGrad, backward: backward}
+	t.nodes = append(t.nodes, n)
+	return n
+}
+
+// Reset 清空 tape，之後可以重新建立計算圖
+func (t *Tape) Reset() {
+	clear(t.nodes)
+	t.nodes = t.nodes[:0]
+}
+
+// Backward 從 1 x 1 的輸出（通常是 loss）開始反向傳播
+func (t *Tape) Backward(out *Node) error {
+	if r, c := out.Value.Dims(); r != 1 || c != 1 {
+		return fmt.Errorf("Backward expects a 1x1 output, got %dx%d, use BackwardWithGrad", r, c)
+	}
+	return t.BackwardWithGrad(out, mat.NewDense(1, 1, []float64{1}))
+}
+
+// BackwardWithGrad 以 grad 作為 loss 對 out 的梯度開始反向傳播
+// 例如 loss 在計算圖之外計算時，直接傳入 dL/d(out)
+func (t *Tape) BackwardWithGrad(out *Node, grad *mat.Dense) error {
+	r, c := out.Value.Dims()
+	if gr, gc := grad.Dims(); gr != r || gc != c {
+		return fmt.Errorf("Gradient is %dx%d but output is %dx%d", gr, gc, r, c)
+	}
+	start := -1
+	for i, n := range t.nodes {
+		n.Grad = nil
+		if n == out {
+			start = i
+		}
+	}
+	if start < 0 {
+		return fmt.Errorf("Output node is not recorded on this tape")
+	}
+	out.Grad = mat.DenseCopyOf(grad)
+	for i := start; i >= 0; i-- {
+		n := t.nodes[i]
+		if n.Grad != nil && n.backward != nil {
+			n.backward()
+		}
+	}
+	return nil
+}
+
+// accumulate n.Grad += g，不需要梯度的節點直接略過
+func (n *Node) accumulate(g mat.Matrix) {
+	if !n.requiresGrad {
+		return
+	}
+	if n.Grad == nil {
+		n.Grad = mat.DenseCopyOf(g)
+		return
+	}
+	n.Grad.Add(n.Grad, g)
+}
+
+func requiresGrad(nodes ...*Node) bool {
+	for _, n := range nodes {
+		if n.requiresGrad {
+			return true
+		}
+	}
+	return false
+}
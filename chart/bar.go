@@ -0,0 +1,71 @@
+package chart
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+)
+
+// BarChart 每個類別一根長條，例如模型輸出的機率分佈
+type BarChart struct {
+	Title          string
+	XLabel, YLabel string
+	Labels         []string // 長條下方的名稱，nil 時使用索引
+	Values         []float64
+	Colors         []color.Color // 每根長條的顏色，nil 或超出範圍時使用 Palette[0]
+	Annotations    []string      // 長條上方的文字，空字串不顯示
+	Max            float64       // y 軸上限，<= 0 時依資料決定
+}
+
+func (b BarChart) Draw(c Canvas, width, height float64) {
+	c.Text(width/2, 20, b.Title, AnchorMiddle, black)
+	left, top := float64(marginLeft), float64(marginTop)
+	plotW, plotH := width-marginLeft-marginRight, height-marginTop-marginBottom
+	n := len(b.Values)
+	if n == 0 || plotW <= 0 || plotH <= 0 {
+		c.Text(width/2, height/2, "no values", AnchorMiddle, gray)
+		return
+	}
+
+	yhi := b.Max
+	if yhi <= 0 {
+		for _, v := range b.Values {
+			yhi = math.Max(yhi, v)
+		}
+	}
+	if yhi <= 0 {
+		yhi = 1
+	}
+	yticks := niceTicks(0, yhi, 4)
+	yhi = math.Max(yhi, yticks[len(yticks)-1])
+	py := func(y float64) float64 { return top + plotH - max(0, min(y, yhi))/yhi*plotH }
+
+	for _, y := range yticks {
+		c.Line(left, py(y), left+plotW, py(y), lightGray, 1)
+		c.Text(left-6, py(y)+4, formatTick(y), AnchorEnd, gray)
+	}
+	slot := plotW / float64(n)
+	barW := math.Max(slot*0.7, 1)
+	for i, v := range b.Values {
+		x := left + float64(i)*slot + (slot-barW)/2
+		col := Palette[0]
+		if i < len(b.Colors) && b.Colors[i] != nil {
+			col = b.Colors[i]
+		}
+		if v > 0 {
+			c.Rect(x, py(v), barW, py(0)-py(v), col)
+		}
+		if i < len(b.Annotations) && b.Annotations[i] != "" {
+			c.Text(x+barW/2, py(v)-4, b.Annotations[i], AnchorMiddle, black)
+		}
+		label := fmt.Sprint(i)
+		if i < len(b.Labels) {
+			label = b.Labels[i]
+		}
+		c.Text(x+barW/2, top+plotH+16, label, AnchorMiddle, gray)
+	}
+	c.Line(left, top+plotH, left+plotW, top+plotH, black, 1)
+	c.Line(left, top, left, top+plotH, black, 1)
+	c.Text(left+plotW/2, height-8, b.XLabel, AnchorMiddle, black)
+	c.Text(8, top-8, b.YLabel, AnchorStart, black)
+}
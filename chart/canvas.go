@@ -0,0 +1,192 @@
+// Package chart 以純 Go 把訓練曲線、confusion matrix 與影像網格輸出成 SVG 或 PNG
+package chart
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// Anchor 文字的水平對齊方式
+type Anchor int
+
+const (
+	AnchorStart Anchor = iota
+	AnchorMiddle
+	AnchorEnd
+)
+
+// Canvas SVG 與 PNG 共用的繪圖介面，座標以像素為單位，原點在左上角
+// Text 的 y 為文字的基線
+type Canvas interface {
+	Line(x1, y1, x2, y2 float64, c color.Color, width float64)
+	Polyline(xs, ys []float64, c color.Color, width float64)
+	Rect(x, y, w, h float64, fill color.Color)
+	Text(x, y float64, s string, anchor Anchor, c color.Color)
+	Image(x, y, w, h float64, img image.Image)
+}
+
+// Chart 可以畫在指定大小的 Canvas 上
+type Chart interface {
+	Draw(c Canvas, width, height float64)
+}
+
+// Save 依副檔名（.svg 或 .png）把 chart 輸出成 width x height 的檔案
+func Save(path string, chart Chart, width, height int) error {
+	var data []byte
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".svg":
+		c := newSVGCanvas(width, height)
+		chart.Draw(c, float64(width), float64(height))
+		data = c.bytes()
+	case ".png":
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, Render(chart, width, height)); err != nil {
+			return err
+		}
+		data = buf.Bytes()
+	default:
+		return fmt.Errorf("Unsupported chart format %q, use .svg or .png", filepath.Ext(path))
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Render 把 chart 畫成 width x height 的影像，例如在 GUI 中直接顯示
+func Render(chart Chart, width, height int) *image.RGBA {
+	c := newPNGCanvas(width, height)
+	chart.Draw(c, float64(width), float64(height))
+	return c.img
+}
+
+// svgCanvas 以字串組出 SVG 文件
+type svgCanvas struct {
+	buf bytes.Buffer
+}
+
+func newSVGCanvas(width, height int) *svgCanvas {
+	c := &svgCanvas{}
+	fmt.Fprintf(&c.buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="12">`+"\n", width, height, width, height)
+	fmt.Fprintf(&c.buf, `<rect width="%d" height="%d" fill="white"/>`+"\n", width, height)
+	return c
+}
+
+func (c *svgCanvas) bytes() []byte {
+	return append(c.buf.Bytes(), "</svg>\n"...)
+}
+
+// svgColor 轉成 SVG 的 rgb() 與 opacity
+func svgColor(col color.Color) (string, float64) {
+	n := color.NRGBAModel.Convert(col).(color.NRGBA)
+	return fmt.Sprintf("rgb(%d,%d,%d)", n.R, n.G, n.B), float64(n.A) / 255
+}
+
+func (c *svgCanvas) Line(x1, y1, x2, y2 float64, col color.Color, width float64) {
+	stroke, opacity := svgColor(col)
+	fmt.Fprintf(&c.buf, `<line x1="%.2f" y1="%.2f" x2="%.2f" y2="%.2f" stroke="%s" stroke-opacity="%.3g" stroke-width="%.2g"/>`+"\n", x1, y1, x2, y2, stroke, opacity, width)
+}
+
+func (c *svgCanvas) Polyline(xs, ys []float64, col color.Color, width float64) {
+	points := make([]string, len(xs))
+	for i := range xs {
+		points[i] = fmt.Sprintf("%.2f,%.2f", xs[i], ys[i])
+	}
+	stroke, opacity := svgColor(col)
+	fmt.Fprintf(&c.buf, `<polyline points="%s" fill="none" stroke="%s" stroke-opacity="%.3g" stroke-width="%.2g" stroke-linejoin="round"/>`+"\n", strings.Join(points, " "), stroke, opacity, width)
+}
+
+func (c *svgCanvas) Rect(x, y, w, h float64, col color.Color) {
+	fill, opacity := svgColor(col)
+	fmt.Fprintf(&c.buf, `<rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" fill="%s" fill-opacity="%.3g"/>`+"\n", x, y, w, h, fill, opacity)
+}
+
+func (c *svgCanvas) Text(x, y float64, s string, anchor Anchor, col color.Color) {
+	fill, _ := svgColor(col)
+	anchors := [...]string{"start", "middle", "end"}
+	fmt.Fprintf(&c.buf, `<text x="%.2f" y="%.2f" text-anchor="%s" fill="%s">`, x, y, anchors[anchor], fill)
+	xml.EscapeText(&c.buf, []byte(s))
+	c.buf.WriteString("</text>\n")
+}
+
+// Image 以 base64 PNG 內嵌，放大時保持像素邊緣清楚
+func (c *svgCanvas) Image(x, y, w, h float64, img image.Image) {
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, img); err != nil {
+		return
+	}
+	fmt.Fprintf(&c.buf, `<image x="%.2f" y="%.2f" width="%.2f" height="%.2f" preserveAspectRatio="none" style="image-rendering:pixelated" href="data:image/png;base64,%s"/>`+"\n",
+		x, y, w, h, base64.StdEncoding.EncodeToString(encoded.Bytes()))
+}
+
+// pngCanvas 在 image.RGBA 上繪圖，線段以 vector.Rasterizer 填滿，文字使用 basicfont
+type pngCanvas struct {
+	img  *image.RGBA
+	face font.Face
+}
+
+func newPNGCanvas(width, height int) *pngCanvas {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	return &pngCanvas{img: img, face: basicfont.Face7x13}
+}
+
+// Line 把線段畫成寬 width 的四邊形
+func (c *pngCanvas) Line(x1, y1, x2, y2 float64, col color.Color, width float64) {
+	length := math.Hypot(x2-x1, y2-y1)
+	if length == 0 {
+		return
+	}
+	// 與線段垂直、長度為 width/2 的位移
+	nx, ny := -(y2-y1)/length*width/2, (x2-x1)/length*width/2
+	bounds := c.img.Bounds()
+	z := vector.NewRasterizer(bounds.Dx(), bounds.Dy())
+	z.MoveTo(float32(x1+nx), float32(y1+ny))
+	z.LineTo(float32(x2+nx), float32(y2+ny))
+	z.LineTo(float32(x2-nx), float32(y2-ny))
+	z.LineTo(float32(x1-nx), float32(y1-ny))
+	z.ClosePath()
+	z.Draw(c.img, bounds, image.NewUniform(col), image.Point{})
+}
+
+func (c *pngCanvas) Polyline(xs, ys []float64, col color.Color, width float64) {
+	for i := 1; i < len(xs); i++ {
+		c.Line(xs[i-1], ys[i-1], xs[i], ys[i], col, width)
+	}
+}
+
+func (c *pngCanvas) Rect(x, y, w, h float64, col color.Color) {
+	r := image.Rect(int(math.Round(x)), int(math.Round(y)), int(math.Round(x+w)), int(math.Round(y+h)))
+	draw.Draw(c.img, r, image.NewUniform(col), image.Point{}, draw.Over)
+}
+
+func (c *pngCanvas) Text(x, y float64, s string, anchor Anchor, col color.Color) {
+	d := &font.Drawer{Dst: c.img, Src: image.NewUniform(col), Face: c.face}
+	width := float64(d.MeasureString(s)) / 64
+	switch anchor {
+	case AnchorMiddle:
+		x -= width / 2
+	case AnchorEnd:
+		x -= width
+	}
+	d.Dot = fixed.P(int(math.Round(x)), int(math.Round(y)))
+	d.DrawString(s)
+}
+
+func (c *pngCanvas) Image(x, y, w, h float64, img image.Image) {
+	r := image.Rect(int(math.Round(x)), int(math.Round(y)), int(math.Round(x+w)), int(math.Round(y+h)))
+	xdraw.NearestNeighbor.Scale(c.img, r, img, img.Bounds(), draw.Over, nil)
+}
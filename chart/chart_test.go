@@ -0,0 +1,177 @@
+package chart
+
+import (
+	"encoding/xml"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNiceTicks(t *testing.T) {
+	cases := []struct {
+		lo, hi float64
+		n      int
+		want   []float64
+	}{
+		{0, 10, 5, []float64{0, 2, 4, 6, 8, 10}},
+		{0.13, 0.92, 5, []float64{0, 0.2, 0.4, 0.6, 0.8, 1}},
+		{-0.03, 0.07, 5, []float64{-0.04, -0.02, 0, 0.02, 0.04, 0.06, 0.08}},
+		{1, 3, 6, []float64{1, 1.5, 2, 2.5, 3}},
+	}
+	for _, tc := range cases {
+		got := niceTicks(tc.lo, tc.hi, tc.n)
+		if len(got) != len(tc.want) {
+			t.Errorf("niceTicks(%v, %v) = %v, want %v", tc.lo, tc.hi, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("niceTicks(%v, %v) = %v, want %v", tc.lo, tc.hi, got, tc.want)
+				break
+			}
+		}
+	}
+}
+
+func testCharts() map[string]Chart {
+	digit := image.NewGray(image.Rect(0, 0, 4, 4))
+	digit.SetGray(1, 1, color.Gray{Y: 255})
+	return map[string]Chart{
+		"loss": LineChart{
+			Title: "Loss <per epoch>", XLabel: "epoch", YLabel: "loss",
+			Series: []Series{
+				{Name: "train", X: []float64{1, 2, 3}, Y: []float64{0.9, 0.5, 0.3}},
+				{Name: "val", X: []float64{1, 2, 3}, Y: []float64{1.0, 0.6, 0.45}},
+			},
+		},
+		"constant": LineChart{Title: "Learning rate", Series: []Series{{Name: "lr", X: []float64{1, 2}, Y: []float64{0.01, 0.01}}}},
+		"confusion": Heatmap{
+			Title:  "Confusion matrix",
+			Values: [][]float64{{5, 1, 0}, {0, 7, 2}, {1, 0, 9}},
+		},
+		"grid": ImageGrid{Title: "Worst", Images: []image.Image{digit, digit, digit}, Captions: []string{"7 -> 1", "3 -> 8", "4 -> 9"}},
+		"bars": BarChart{
+			Title: "Probabilities", XLabel: "class", Max: 1,
+			Values:      []float64{0.05, 0.6, 0, 0.35},
+			Colors:      []color.Color{nil, Palette[0], nil, Palette[1]},
+			Annotations: []string{"", "60%", "", "35%"},
+		},
+		"panels": Panels{Title: "Weights", Columns: 2, Charts: []Chart{
+			Histogram{Title: "hidden0/weight", Values: []float64{-0.3, -0.1, 0, 0.05, 0.1, 0.1, 0.4}},
+			Histogram{Title: "hidden0/bias", Values: []float64{0, 0, 0}},
+		}},
+	}
+}
+
+func TestSaveSVG(t *testing.T) {
+	dir := t.TempDir()
+	for name, chart := range testCharts() {
+		path := filepath.Join(dir, name+".svg")
+		if err := Save(path, chart, 480, 320); err != nil {
+			t.Fatal(err)
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 整份文件必須是合法的 XML
+		decoder := xml.NewDecoder(file)
+		elements := 0
+		for {
+			token, err := decoder.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s: invalid SVG: %v", name, err)
+			}
+			if _, ok := token.(xml.StartElement); ok {
+				elements++
+			}
+		}
+		file.Close()
+		if elements < 5 {
+			t.Errorf("%s: only %d elements", name, elements)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "grid.svg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "data:image/png;base64,") || !strings.Contains(string(data), "7 -&gt; 1") {
+		t.Errorf("grid SVG is missing embedded images or captions")
+	}
+}
+
+func TestSavePNG(t *testing.T) {
+	dir := t.TempDir()
+	for name, chart := range testCharts() {
+		path := filepath.Join(dir, name+".png")
+		if err := Save(path, chart, 480, 320); err != nil {
+			t.Fatal(err)
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := png.Decode(file)
+		file.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if img.Bounds().Dx() != 480 || img.Bounds().Dy() != 320 {
+			t.Errorf("%s: size %v", name, img.Bounds())
+		}
+		// 除了白色背景之外一定有畫出東西
+		drawn := 0
+		for y := 0; y < 320; y++ {
+			for x := 0; x < 480; x++ {
+				if r, g, b, _ := img.At(x, y).RGBA(); r != 0xffff || g != 0xffff || b != 0xffff {
+					drawn++
+				}
+			}
+		}
+		if drawn < 500 {
+			t.Errorf("%s: only %d non-white pixels", name, drawn)
+		}
+	}
+}
+
+func TestSaveRejectsUnknownFormat(t *testing.T) {
+	if err := Save(filepath.Join(t.TempDir(), "chart.jpg"), testCharts()["loss"], 100, 100); err == nil {
+		t.Error("expected an error for .jpg")
+	}
+}
+
+func TestBinCounts(t *testing.T) {
+	got := binCounts([]float64{0, 0.1, 0.5, 0.99, 1}, 0, 1, 4)
+	want := []int{2, 0, 1, 2}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("binCounts = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestTile(t *testing.T) {
+	digit := image.NewGray(image.Rect(0, 0, 4, 3))
+	digit.SetGray(0, 0, color.Gray{Y: 255})
+	out := Tile([]image.Image{digit, digit, digit, digit, digit}, 0, 2, 1)
+	// 5 張取 3 欄 2 列，每格 8x6 加 1 像素分隔線
+	if out.Bounds().Dx() != 3*9+1 || out.Bounds().Dy() != 2*7+1 {
+		t.Fatalf("size %v", out.Bounds())
+	}
+	// 第二列第一張左上角放大成 2x2 的白點
+	if r, _, _, _ := out.At(2, 9).RGBA(); r != 0xffff {
+		t.Errorf("pixel not scaled into place")
+	}
+	if r, _, _, _ := out.At(3, 9).RGBA(); r != 0 {
+		t.Errorf("background pixel is not black")
+	}
+}
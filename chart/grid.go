@@ -0,0 +1,43 @@
+package chart
+
+import (
+	"image"
+	"math"
+)
+
+// ImageGrid 把多張影像排成網格，每張影像下方有一行說明（basicfont 只支援 ASCII）
+type ImageGrid struct {
+	Title    string
+	Images   []image.Image
+	Captions []string
+	Columns  int // <= 0 時取接近正方形的欄數
+}
+
+func (g ImageGrid) Draw(c Canvas, width, height float64) {
+	c.Text(width/2, 20, g.Title, AnchorMiddle, black)
+	n := len(g.Images)
+	if n == 0 {
+		c.Text(width/2, height/2, "no images", AnchorMiddle, gray)
+		return
+	}
+	cols := g.Columns
+	if cols <= 0 {
+		cols = int(math.Ceil(math.Sqrt(float64(n))))
+	}
+	rows := (n + cols - 1) / cols
+	const captionHeight, gap = 18.0, 8.0
+	top := 36.0
+	cellW := (width - gap) / float64(cols)
+	cellH := (height - top) / float64(rows)
+	side := min(cellW-gap, cellH-gap-captionHeight)
+
+	for i, img := range g.Images {
+		x := gap + float64(i%cols)*cellW
+		y := top + float64(i/cols)*cellH
+		c.Rect(x-1, y-1, side+2, side+2, lightGray)
+		c.Image(x, y, side, side, img)
+		if i < len(g.Captions) {
+			c.Text(x+side/2, y+side+14, g.Captions[i], AnchorMiddle, black)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package chart
+
+import (
+	"fmt"
+	"image/color"
+)
+
+// Heatmap 以顏色深淺顯示矩陣，例如 confusion matrix（row 為真實類別，column 為預測類別）
+// 顏色依每個 row 的比例計算，格子中顯示原始數值
+type Heatmap struct {
+	Title          string
+	XLabel, YLabel string
+	Labels         []string // 類別名稱，nil 時使用索引
+	Values         [][]float64
+}
+
+func (h Heatmap) Draw(c Canvas, width, height float64) {
+	c.Text(width/2, 20, h.Title, AnchorMiddle, black)
+	n := len(h.Values)
+	if n == 0 {
+		return
+	}
+	left, top := float64(marginLeft), float64(marginTop)+10
+	size := min(width-left-marginRight, height-top-marginBottom)
+	cell := size / float64(n)
+
+	label := func(i int) string {
+		if i < len(h.Labels) {
+			return h.Labels[i]
+		}
+		return fmt.Sprint(i)
+	}
+	for i, row := range h.Values {
+		sum := 0.0
+		for _, v := range row {
+			sum += v
+		}
+		for j, v := range row {
+			share := 0.0
+			if sum > 0 {
+				share = v / sum
+			}
+			fill := heatColor(share)
+			x, y := left+float64(j)*cell, top+float64(i)*cell
+			c.Rect(x, y, cell, cell, fill)
+			if cell >= 18 && v != 0 {
+				text := black
+				if share > 0.5 {
+					text = color.White
+				}
+				c.Text(x+cell/2, y+cell/2+4, formatTick(v), AnchorMiddle, text)
+			}
+		}
+		c.Text(left-6, top+float64(i)*cell+cell/2+4, label(i), AnchorEnd, gray)
+	}
+	for j := 0; j < n; j++ {
+		c.Text(left+float64(j)*cell+cell/2, top+size+16, label(j), AnchorMiddle, gray)
+	}
+	c.Text(left+size/2, top+size+34, h.XLabel, AnchorMiddle, black)
+	c.Text(8, top-8, h.YLabel, AnchorStart, black)
+}
+
+// heatColor 由白色（0）到深藍色（1）
+func heatColor(t float64) color.Color {
+	t = max(0, min(1, t))
+	lerp := func(a, b float64) uint8 { return uint8(a + (b-a)*t) }
+	return color.RGBA{R: lerp(255, 8), G: lerp(255, 48), B: lerp(255, 107), A: 255}
+}
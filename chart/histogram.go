@@ -0,0 +1,138 @@
+package chart
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// Histogram 數值分佈的長條圖，例如一層的權重
+type Histogram struct {
+	Title  string
+	XLabel string
+	Values []float64
+	Bins   int // <= 0 時使用 30
+}
+
+func (h Histogram) Draw(c Canvas, width, height float64) {
+	c.Text(width/2, 20, h.Title, AnchorMiddle, black)
+	left, top := float64(marginLeft), float64(marginTop)
+	plotW, plotH := width-marginLeft-marginRight, height-marginTop-marginBottom
+	if len(h.Values) == 0 || plotW <= 0 || plotH <= 0 {
+		c.Text(width/2, height/2, "no values", AnchorMiddle, gray)
+		return
+	}
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range h.Values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	if hi == lo {
+		pad := math.Max(math.Abs(lo)*0.1, 1e-3)
+		lo, hi = lo-pad, hi+pad
+	}
+	counts := binCounts(h.Values, lo, hi, h.Bins)
+	peak := 0
+	for _, n := range counts {
+		peak = max(peak, n)
+	}
+
+	xticks := niceTicks(lo, hi, 4)
+	xlo, xhi := math.Min(lo, xticks[0]), math.Max(hi, xticks[len(xticks)-1])
+	yticks := niceTicks(0, float64(peak), 4)
+	yhi := yticks[len(yticks)-1]
+	px := func(x float64) float64 { return left + (x-xlo)/(xhi-xlo)*plotW }
+	py := func(y float64) float64 { return top + plotH - y/yhi*plotH }
+
+	for _, y := range yticks {
+		c.Line(left, py(y), left+plotW, py(y), lightGray, 1)
+		c.Text(left-6, py(y)+4, formatTick(y), AnchorEnd, gray)
+	}
+	binWidth := (hi - lo) / float64(len(counts))
+	for i, n := range counts {
+		if n == 0 {
+			continue
+		}
+		x0, x1 := px(lo+float64(i)*binWidth), px(lo+float64(i+1)*binWidth)
+		c.Rect(x0, py(float64(n)), math.Max(x1-x0-1, 1), py(0)-py(float64(n)), Palette[0])
+	}
+	for _, x := range xticks {
+		c.Line(px(x), top+plotH, px(x), top+plotH+4, black, 1)
+		c.Text(px(x), top+plotH+16, formatTick(x), AnchorMiddle, gray)
+	}
+	c.Line(left, top+plotH, left+plotW, top+plotH, black, 1)
+	c.Line(left, top, left, top+plotH, black, 1)
+	c.Text(left+plotW/2, height-8, h.XLabel, AnchorMiddle, black)
+}
+
+// binCounts 把 [lo, hi] 分成等寬的 bins 個區間並計數，hi 算在最後一個區間
+func binCounts(values []float64, lo, hi float64, bins int) []int {
+	if bins <= 0 {
+		bins = 30
+	}
+	counts := make([]int, bins)
+	for _, v := range values {
+		i := int((v - lo) / (hi - lo) * float64(bins))
+		counts[max(0, min(bins-1, i))]++
+	}
+	return counts
+}
+
+// Panels 把多張圖表排成網格畫在同一張圖上
+type Panels struct {
+	Title   string
+	Charts  []Chart
+	Columns int // <= 0 時取接近正方形的欄數
+}
+
+func (p Panels) Draw(c Canvas, width, height float64) {
+	top := 0.0
+	if p.Title != "" {
+		c.Text(width/2, 20, p.Title, AnchorMiddle, black)
+		top = 28
+	}
+	n := len(p.Charts)
+	if n == 0 {
+		return
+	}
+	cols := p.Columns
+	if cols <= 0 {
+		cols = int(math.Ceil(math.Sqrt(float64(n))))
+	}
+	rows := (n + cols - 1) / cols
+	cellW, cellH := width/float64(cols), (height-top)/float64(rows)
+	for i, chart := range p.Charts {
+		x, y := float64(i%cols)*cellW, top+float64(i/cols)*cellH
+		chart.Draw(offsetCanvas{c, x, y}, cellW, cellH)
+	}
+}
+
+// offsetCanvas 把所有座標平移 (dx, dy)，讓子圖表可以使用自己的座標
+type offsetCanvas struct {
+	Canvas
+	dx, dy float64
+}
+
+func (o offsetCanvas) Line(x1, y1, x2, y2 float64, col color.Color, width float64) {
+	o.Canvas.Line(x1+o.dx, y1+o.dy, x2+o.dx, y2+o.dy, col, width)
+}
+
+func (o offsetCanvas) Polyline(xs, ys []float64, col color.Color, width float64) {
+	mx, my := make([]float64, len(xs)), make([]float64, len(ys))
+	for i := range xs {
+		mx[i], my[i] = xs[i]+o.dx, ys[i]+o.dy
+	}
+	o.Canvas.Polyline(mx, my, col, width)
+}
+
+func (o offsetCanvas) Rect(x, y, w, h float64, col color.Color) {
+	o.Canvas.Rect(x+o.dx, y+o.dy, w, h, col)
+}
+
+func (o offsetCanvas) Text(x, y float64, s string, anchor Anchor, col color.Color) {
+	o.Canvas.Text(x+o.dx, y+o.dy, s, anchor, col)
+}
+
+func (o offsetCanvas) Image(x, y, w, h float64, img image.Image) {
+	o.Canvas.Image(x+o.dx, y+o.dy, w, h, img)
+}
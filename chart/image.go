@@ -0,0 +1,41 @@
+package chart
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// TensorImage 把通道優先（C x H x W）排列的數值轉成影像，數值以最小值到最大值線性對應到 0-255
+// channels 為 1 時是灰階影像，3 時是 RGB 影像
+func TensorImage(values []float64, channels, height, width int) image.Image {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	scale := 0.0
+	if hi > lo {
+		scale = 255 / (hi - lo)
+	}
+	pixel := func(c, y, x int) uint8 {
+		return uint8(math.Round((values[(c*height+y)*width+x] - lo) * scale))
+	}
+
+	rect := image.Rect(0, 0, width, height)
+	if channels != 3 {
+		img := image.NewGray(rect)
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				img.SetGray(x, y, color.Gray{Y: pixel(0, y, x)})
+			}
+		}
+		return img
+	}
+	img := image.NewNRGBA(rect)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: pixel(0, y, x), G: pixel(1, y, x), B: pixel(2, y, x), A: 255})
+		}
+	}
+	return img
+}
@@ -0,0 +1,147 @@
+package chart
+
+import (
+	"image/color"
+	"math"
+	"strconv"
+)
+
+var (
+	black     = color.Black
+	gray      = color.RGBA{R: 120, G: 120, B: 120, A: 255}
+	lightGray = color.RGBA{R: 225, G: 225, B: 225, A: 255}
+
+	// Palette 預設的系列顏色
+	Palette = []color.Color{
+		color.RGBA{R: 31, G: 119, B: 180, A: 255},
+		color.RGBA{R: 255, G: 127, B: 14, A: 255},
+		color.RGBA{R: 44, G: 160, B: 44, A: 255},
+		color.RGBA{R: 214, G: 39, B: 40, A: 255},
+		color.RGBA{R: 148, G: 103, B: 189, A: 255},
+	}
+)
+
+// Series 一條折線，X 與 Y 長度相同
+type Series struct {
+	Name  string
+	X, Y  []float64
+	Color color.Color // nil 時依序使用 Palette
+}
+
+// LineChart 折線圖，例如每個 epoch 的 loss
+type LineChart struct {
+	Title          string
+	XLabel, YLabel string
+	Series         []Series
+}
+
+// 圖表四周的留白
+const (
+	marginLeft   = 64
+	marginRight  = 20
+	marginTop    = 36
+	marginBottom = 44
+)
+
+func (l LineChart) Draw(c Canvas, width, height float64) {
+	c.Text(width/2, 20, l.Title, AnchorMiddle, black)
+	left, top := float64(marginLeft), float64(marginTop)
+	plotW, plotH := width-marginLeft-marginRight, height-marginTop-marginBottom
+
+	xlo, xhi, ylo, yhi := l.bounds()
+	xticks, yticks := niceTicks(xlo, xhi, 6), niceTicks(ylo, yhi, 5)
+	xlo, xhi = math.Min(xlo, xticks[0]), math.Max(xhi, xticks[len(xticks)-1])
+	ylo, yhi = math.Min(ylo, yticks[0]), math.Max(yhi, yticks[len(yticks)-1])
+	px := func(x float64) float64 { return left + (x-xlo)/(xhi-xlo)*plotW }
+	py := func(y float64) float64 { return top + plotH - (y-ylo)/(yhi-ylo)*plotH }
+
+	// 格線與刻度
+	for _, x := range xticks {
+		c.Line(px(x), top, px(x), top+plotH, lightGray, 1)
+		c.Text(px(x), top+plotH+16, formatTick(x), AnchorMiddle, gray)
+	}
+	for _, y := range yticks {
+		c.Line(left, py(y), left+plotW, py(y), lightGray, 1)
+		c.Text(left-6, py(y)+4, formatTick(y), AnchorEnd, gray)
+	}
+	c.Line(left, top+plotH, left+plotW, top+plotH, black, 1)
+	c.Line(left, top, left, top+plotH, black, 1)
+	c.Text(left+plotW/2, height-8, l.XLabel, AnchorMiddle, black)
+	c.Text(8, top-8, l.YLabel, AnchorStart, black)
+
+	for i, s := range l.Series {
+		col := s.Color
+		if col == nil {
+			col = Palette[i%len(Palette)]
+		}
+		xs, ys := make([]float64, len(s.X)), make([]float64, len(s.Y))
+		for j := range s.X {
+			xs[j], ys[j] = px(s.X[j]), py(s.Y[j])
+		}
+		c.Polyline(xs, ys, col, 2)
+		// 點不多時畫出每個資料點
+		if len(xs) <= 50 {
+			for j := range xs {
+				c.Rect(xs[j]-2.5, ys[j]-2.5, 5, 5, col)
+			}
+		}
+		// 圖例在右上角
+		ly := top + 14 + float64(i)*16
+		c.Rect(left+plotW-130, ly-8, 12, 8, col)
+		c.Text(left+plotW-112, ly, s.Name, AnchorStart, black)
+	}
+}
+
+// bounds 所有系列的資料範圍，範圍為 0 時向兩側擴張
+func (l LineChart) bounds() (xlo, xhi, ylo, yhi float64) {
+	xlo, ylo = math.Inf(1), math.Inf(1)
+	xhi, yhi = math.Inf(-1), math.Inf(-1)
+	for _, s := range l.Series {
+		for j := range s.X {
+			if math.IsNaN(s.Y[j]) || math.IsInf(s.Y[j], 0) {
+				continue
+			}
+			xlo, xhi = math.Min(xlo, s.X[j]), math.Max(xhi, s.X[j])
+			ylo, yhi = math.Min(ylo, s.Y[j]), math.Max(yhi, s.Y[j])
+		}
+	}
+	if math.IsInf(xlo, 1) {
+		return 0, 1, 0, 1
+	}
+	if xhi == xlo {
+		xlo, xhi = xlo-1, xhi+1
+	}
+	if yhi == ylo {
+		pad := math.Max(math.Abs(ylo)*0.1, 1e-3)
+		ylo, yhi = ylo-pad, yhi+pad
+	}
+	return xlo, xhi, ylo, yhi
+}
+
+// niceTicks 在 [lo, hi] 附近取約 n 個間隔為 1、2、5 x 10^k 的刻度，刻度會涵蓋整個範圍
+func niceTicks(lo, hi float64, n int) []float64 {
+	raw := (hi - lo) / float64(n)
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := magnitude
+	for _, m := range []float64{1, 2, 5, 10} {
+		step = m * magnitude
+		if step >= raw {
+			break
+		}
+	}
+	// 以 step 的小數位數取整，避免出現 0.6000000000000001
+	scale := math.Pow(10, math.Max(0, -math.Floor(math.Log10(step))))
+	start := math.Floor(lo/step) * step
+	var ticks []float64
+	for i := 0; ; i++ {
+		v := math.Round((start+float64(i)*step)*scale) / scale
+		ticks = append(ticks, v)
+		if v >= hi-step*1e-9 && len(ticks) >= 2 {
+			return ticks
+		}
+	}
+}
+
+func formatTick(v float64) string {
+	return strconv.FormatFloat(v, 'g', 4, 64)
+}
@@ -0,0 +1,34 @@
+package chart
+
+import (
+	"image"
+	"image/draw"
+	"math"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// Tile 把大小相同的影像以 columns 欄排成一張影像，每張放大 scale 倍（最近鄰），之間以 gap 像素的白線分隔
+// columns <= 0 時取接近正方形的欄數
+func Tile(images []image.Image, columns, scale, gap int) *image.RGBA {
+	if len(images) == 0 {
+		return image.NewRGBA(image.Rect(0, 0, 0, 0))
+	}
+	if columns <= 0 {
+		columns = int(math.Ceil(math.Sqrt(float64(len(images)))))
+	}
+	scale = max(scale, 1)
+	rows := (len(images) + columns - 1) / columns
+	size := images[0].Bounds().Size().Mul(scale)
+	width := columns*(size.X+gap) + gap
+	height := rows*(size.Y+gap) + gap
+
+	out := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(out, out.Bounds(), image.White, image.Point{}, draw.Src)
+	for i, img := range images {
+		x := gap + (i%columns)*(size.X+gap)
+		y := gap + (i/columns)*(size.Y+gap)
+		xdraw.NearestNeighbor.Scale(out, image.Rect(x, y, x+size.X, y+size.Y), img, img.Bounds(), draw.Src, nil)
+	}
+	return out
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"golang-neural-network/nn"
+)
+
+// 對抗訓練的命令列參數
+var (
+	advAttack  string
+	advEpsilon float64
+	advRatio   float64
+	advMode    string
+	advNorm    string
+	advSteps   int
+)
+
+func init() {
+	flags := rootCmd.PersistentFlags()
+	flags.StringVar(&advAttack, "adversarial", "", "train on on-the-fly adversarial examples from this attack: fgsm or pgd (default: off)")
+	flags.Float64Var(&advEpsilon, "adv-epsilon", 0.1, "perturbation size of adversarial training")
+	flags.Float64Var(&advRatio, "adv-ratio", 0.5, "share of every mini-batch that is attacked")
+	flags.StringVar(&advMode, "adv-mode", string(nn.AdversarialReplace), "replace: attacked samples replace their clean version, mix: they are added to the clean batch")
+	flags.StringVar(&advNorm, "adv-norm", string(nn.NormLinf), "norm bounding the adversarial perturbation: linf or l2")
+	flags.IntVar(&advSteps, "adv-steps", nn.DefaultPGDSteps, "number of PGD steps")
+}
+
+// adversarialConfig 依命令列參數建立對抗訓練的設定，沒有指定 --adversarial 時回傳 nil
+func adversarialConfig() (*nn.AdversarialConfig, error) {
+	if advAttack == "" {
+		return nil, nil
+	}
+	config := &nn.AdversarialConfig{
+		Attack: nn.AttackConfig{
+			Method:      nn.AttackMethod(advAttack),
+			Epsilon:     advEpsilon,
+			Norm:        nn.AttackNorm(advNorm),
+			Steps:       advSteps,
+			RandomStart: advAttack == string(nn.AttackPGD),
+		},
+		Ratio: advRatio,
+		Mode:  nn.AdversarialMode(advMode),
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"golang-neural-network/nn"
+	"os"
+	"path/filepath"
+)
+
+const (
+	datasetMNIST   = "MNIST"
+	datasetCIFAR10 = "CIFAR-10"
+)
+
+// loadDataset 依資料集名稱讀取訓練集與驗證集，並回傳輸入影像的形狀
+func loadDataset(name string) ([]nn.TrainingData, []nn.TrainingData, nn.ImageShape, error) {
+	switch name {
+	case datasetCIFAR10:
+		trainingSet, valSet, err := loadCIFAR10()
+		return trainingSet, valSet, nn.CIFAR10Shape, err
+	default:
+		trainingSet, valSet, err := loadMNIST()
+		return trainingSet, valSet, nn.MNISTShape, err
+	}
+}
+
+func loadMNIST() ([]nn.TrainingData, []nn.TrainingData, error) {
+	// check if the user dont have train and test data
+	trainFilePath := "mnist_data/train.csv"
+	testFilePath := "mnist_data/test.csv"
+
+	if _, err := os.Stat(trainFilePath); errors.Is(err, os.ErrNotExist) {
+		fmt.Println("train CSV file not found, parsing MNIST images and labels")
+		err = nn.ConvertToCSV("mnist_data/train-images.idx3-ubyte", "mnist_data/train-labels.idx1-ubyte", trainFilePath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("converting training data: %v", err)
+		}
+	}
+	if _, err := os.Stat(testFilePath); errors.Is(err, os.ErrNotExist) {
+		fmt.Println("test CSV file not found, parsing MNIST images and labels")
+		err = nn.ConvertToCSV("mnist_data/t10k-images.idx3-ubyte", "mnist_data/t10k-labels.idx1-ubyte", testFilePath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("converting test data: %v", err)
+		}
+	}
+
+	// loading training set
+	trainingSet, err := nn.LoadingDataFromCSV(trainFilePath, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading training data: %v", err)
+	}
+	fmt.Printf("Loaded %d training samples\n", len(trainingSet))
+
+	// load validation set
+	fmt.Println("Loading test data...")
+	// 驗證集沿用訓練集的類別數
+	_, classes, err := nn.DatasetShape(trainingSet)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading training data: %v", err)
+	}
+	valSet, err := nn.LoadingDataFromCSV(testFilePath, classes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading test data: %v", err)
+	}
+	fmt.Printf("Loaded %d test samples\n", len(valSet))
+	return trainingSet, valSet, nil
+}
+
+// loadCIFAR10 讀取 cifar_data/ 下的 CIFAR-10 binary 版本
+// (data_batch_1.bin ~ data_batch_5.bin 與 test_batch.bin)
+func loadCIFAR10() ([]nn.TrainingData, []nn.TrainingData, error) {
+	cifarDir := "cifar_data"
+	trainFiles, err := filepath.Glob(filepath.Join(cifarDir, "data_batch_*.bin"))
+	if err != nil || len(trainFiles) == 0 {
+		return nil, nil, fmt.Errorf("no CIFAR-10 training batches found in %s", cifarDir)
+	}
+
+	trainingSet, err := nn.LoadingDataFromCIFAR10(trainFiles...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading training data: %v", err)
+	}
+	fmt.Printf("Loaded %d training samples\n", len(trainingSet))
+
+	fmt.Println("Loading test data...")
+	valSet, err := nn.LoadingDataFromCIFAR10(filepath.Join(cifarDir, "test_batch.bin"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading test data: %v", err)
+	}
+	fmt.Printf("Loaded %d test samples\n", len(valSet))
+	return trainingSet, valSet, nil
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"golang-neural-network/nn"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+)
+
+// exitInterrupted 訓練被 SIGINT/SIGTERM 中斷時的結束代碼（128 + SIGINT）
+const exitInterrupted = 130
+
+// interruptContext 收到 SIGINT 或 SIGTERM 時取消的 context
+// 第一個訊號只要求訓練在目前的 step 結束後停止，之後恢復預設行為，再按一次 Ctrl-C 會直接結束程式
+func interruptContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-signals:
+			fmt.Printf("\n%s received, finishing the current step... (press Ctrl-C again to quit immediately)\n", sig)
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(signals)
+	}()
+	return ctx, cancel
+}
+
+// trainingSummary 記錄訓練進度與驗證 loss 最低的模型，中斷時用來儲存與輸出摘要
+type trainingSummary struct {
+	nn.BaseCallback
+	state     *nn.TrainingState
+	epochs    int // 完成的 epoch 數
+	best      *nn.NeuralNetwork
+	bestEpoch int
+	bestLoss  float64
+}
+
+func (s *trainingSummary) OnTrainBegin(state *nn.TrainingState) error {
+	s.state = state
+	return nil
+}
+
+func (s *trainingSummary) OnEpochEnd(state *nn.TrainingState) error {
+	s.epochs++
+	loss, ok := state.Validation["loss"]
+	if ok && (s.best == nil || loss < s.bestLoss) {
+		s.best, s.bestEpoch, s.bestLoss = state.Network.Clone(), state.Epoch, loss
+	}
+	return nil
+}
+
+func (s *trainingSummary) print() {
+	if s.state == nil {
+		return
+	}
+	state := s.state
+	fmt.Println("\nTraining summary")
+	fmt.Printf("Stopped in epoch %d/%d after %d steps (%d/%d batches of this epoch)\n",
+		state.Epoch, state.Config.Epochs, state.Step, state.Batch, state.Batches)
+	if state.Validation != nil {
+		fmt.Printf("Last completed epoch | train loss %.4f | %s\n", state.TrainLoss, state.Validation)
+	}
+	if s.best != nil {
+		fmt.Printf("Best epoch %d | validation loss %.4f\n", s.bestEpoch, s.bestLoss)
+	}
+}
+
+// saveInterrupted 儲存中斷時的權重與目前最好的模型，回傳寫入的檔案
+func (s *trainingSummary) saveInterrupted(network *nn.NeuralNetwork, dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	checkpoint := filepath.Join(dir, "interrupted_checkpoint.json")
+	if err := nn.SaveModel(network, checkpoint); err != nil {
+		return nil, err
+	}
+	saved := []string{checkpoint}
+	if s.best != nil {
+		best := filepath.Join(dir, "interrupted_best.json")
+		if err := nn.SaveModel(s.best, best); err != nil {
+			return saved, err
+		}
+		saved = append(saved, best)
+	}
+	return saved, nil
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"golang-neural-network/nn"
+	"strconv"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+)
+
+const (
+	lossOptionCrossEntropy = "Cross-entropy"
+	lossOptionFocal        = "Focal loss"
+
+	classWeightsNone     = "none"
+	classWeightsBalanced = "balanced"
+)
+
+// lossSettings 使用者在 CLI 選擇的損失函數設定，讀完資料集後才轉成 nn.LossConfig
+type lossSettings struct {
+	Loss           string
+	FocalGamma     float64
+	LabelSmoothing float64
+	ClassWeights   string // none、balanced 或以逗號分隔的權重
+}
+
+func (s lossSettings) String() string {
+	name := s.Loss
+	if s.Loss == lossOptionFocal {
+		name = fmt.Sprintf("%s (gamma %.2f)", s.Loss, s.FocalGamma)
+	}
+	return fmt.Sprintf("%s, label smoothing %.2f, class weights %s", name, s.LabelSmoothing, s.ClassWeights)
+}
+
+func askLossSettings() lossSettings {
+	settings := lossSettings{Loss: lossOptionCrossEntropy, ClassWeights: classWeightsNone}
+	survey.AskOne(&survey.Select{
+		Message: "Choose loss function:",
+		Options: []string{lossOptionCrossEntropy, lossOptionFocal},
+		Default: lossOptionCrossEntropy,
+	}, &settings.Loss)
+
+	if settings.Loss == lossOptionFocal {
+		var gammaStr string
+		survey.AskOne(&survey.Input{
+			Message: "Enter focal loss gamma:",
+			Default: "2",
+		}, &gammaStr, survey.WithValidator(positiveFloatValidator))
+		settings.FocalGamma, _ = strconv.ParseFloat(gammaStr, 64)
+	}
+
+	var smoothingStr string
+	survey.AskOne(&survey.Input{
+		Message: "Enter label smoothing (0 = off):",
+		Default: "0",
+	}, &smoothingStr, survey.WithValidator(labelSmoothingValidator))
+	settings.LabelSmoothing, _ = strconv.ParseFloat(smoothingStr, 64)
+
+	survey.AskOne(&survey.Input{
+		Message: "Enter class weights (none, balanced, or comma-separated list):",
+		Default: classWeightsNone,
+	}, &settings.ClassWeights)
+	settings.ClassWeights = strings.TrimSpace(settings.ClassWeights)
+	return settings
+}
+
+func labelSmoothingValidator(val interface{}) error {
+	str, ok := val.(string)
+	if !ok || str == "" {
+		return fmt.Errorf("Please enter number")
+	}
+	num, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return fmt.Errorf("Invalid float")
+	}
+	if num < 0 || num >= 1 {
+		return fmt.Errorf("Must be in [0, 1)")
+	}
+	return nil
+}
+
+// lossConfig 轉成 nn.LossConfig，balanced 權重依訓練集的類別頻率計算
+func (s lossSettings) lossConfig(trainingSet []nn.TrainingData) (nn.LossConfig, error) {
+	config := nn.LossConfig{Type: nn.LossCrossEntropy, LabelSmoothing: s.LabelSmoothing}
+	if s.Loss == lossOptionFocal {
+		config.Type = nn.LossFocal
+		config.FocalGamma = s.FocalGamma
+	}
+
+	switch strings.ToLower(s.ClassWeights) {
+	case "", classWeightsNone:
+	case classWeightsBalanced:
+		weights, err := nn.BalancedClassWeights(trainingSet)
+		if err != nil {
+			return config, err
+		}
+		config.ClassWeights = weights
+	default:
+		for _, field := range strings.Split(s.ClassWeights, ",") {
+			weight, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+			if err != nil {
+				return config, fmt.Errorf("Invalid class weight %q", field)
+			}
+			config.ClassWeights = append(config.ClassWeights, weight)
+		}
+	}
+	return config, nil
+}
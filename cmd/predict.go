@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"fmt"
+	"golang-neural-network/drawing"
+	"golang-neural-network/nn"
+	"golang-neural-network/preprocess"
+	"golang-neural-network/visualize"
+	"image"
+	_ "image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gonum.org/v1/gonum/mat"
+)
+
+// predict 子命令的參數
+var (
+	predictIndex    int
+	predictDataset  string
+	predictExplain  string
+	predictClass    int
+	predictOutput   string
+	predictScale    int
+	predictPipeline string
+
+	predictOcclusion       bool
+	predictOcclusionOutput string
+	predictPatch           int
+	predictStride          int
+	predictFill            float64
+)
+
+var predictCmd = &cobra.Command{
+	Use:   "predict <model.json> [image]",
+	Short: "Predict the class of an image or a test sample and optionally save saliency and occlusion heatmaps",
+	Long: `Predict the class of a PNG or JPEG image, preprocessed like the drawing board
+(dark strokes on a light background), or of test sample --index of the dataset.
+
+With --explain and --occlusion, the input, the heatmap and the heatmap overlaid on
+the input are saved side by side as a PNG.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		imagePath := ""
+		if len(args) == 2 {
+			imagePath = args[1]
+		}
+		return predict(args[0], imagePath)
+	},
+}
+
+func init() {
+	predictCmd.Flags().IntVar(&predictIndex, "index", -1, "index of the test sample to predict when no image is given")
+	predictCmd.Flags().StringVar(&predictDataset, "dataset", "", "dataset of --index (default: inferred from the model input shape)")
+	predictCmd.Flags().StringVar(&predictExplain, "explain", "", "explanation method: "+strings.Join(visualize.Methods, ", "))
+	predictCmd.Flags().IntVar(&predictClass, "class", -1, "class to explain, -1 for the predicted class")
+	predictCmd.Flags().StringVarP(&predictOutput, "output", "o", "", "heatmap PNG path (default: <image or test sample>_<method>.png)")
+	predictCmd.Flags().IntVar(&predictScale, "scale", 8, "enlargement factor of the heatmap images")
+	predictCmd.Flags().StringVar(&predictPipeline, "preprocess", "mnist", "preprocessing of [image]: legacy, mnist or steps like mass,lanczos,deskew,stroke")
+	predictCmd.Flags().BoolVar(&predictOcclusion, "occlusion", false, "slide a patch over the input and map how much the class probability drops")
+	predictCmd.Flags().StringVar(&predictOcclusionOutput, "occlusion-output", "", "occlusion map PNG path (default: <image or test sample>_occlusion.png)")
+	predictCmd.Flags().IntVar(&predictPatch, "patch", 4, "occlusion patch size in pixels")
+	predictCmd.Flags().IntVar(&predictStride, "stride", 2, "occlusion patch stride in pixels")
+	predictCmd.Flags().Float64Var(&predictFill, "fill", 0, "value written into the occluded pixels (0 is the MNIST background)")
+	rootCmd.AddCommand(predictCmd)
+}
+
+func predict(modelPath, imagePath string) error {
+	model, err := nn.LoadModel(modelPath)
+	if err != nil {
+		return fmt.Errorf("loading model: %w", err)
+	}
+	dataset := predictDataset
+	if dataset == "" {
+		dataset = datasetForModel(model)
+	}
+
+	var input *mat.Dense
+	name := ""
+	switch {
+	case imagePath != "":
+		input, err = imageInput(imagePath, model.ImageShape())
+		if err != nil {
+			return err
+		}
+		name = strings.TrimSuffix(imagePath, filepath.Ext(imagePath))
+	case predictIndex >= 0:
+		_, testSet, _, err := loadDataset(dataset)
+		if err != nil {
+			return err
+		}
+		if predictIndex >= len(testSet) {
+			return fmt.Errorf("Index %d out of range, the test set has %d samples", predictIndex, len(testSet))
+		}
+		sample := testSet[predictIndex]
+		input = sample.Input
+		label, _ := nn.Argmax(sample.Target)
+		fmt.Printf("Test sample %d, label %d\n", predictIndex, label)
+		name = fmt.Sprintf("test%d", predictIndex)
+	default:
+		return fmt.Errorf("Give an image or --index")
+	}
+
+	probs, err := model.Predict(input)
+	if err != nil {
+		return err
+	}
+	labels := classLabels(dataset, model.OutputClass)
+	printProbabilities(probs, labels)
+
+	values := mat.Col(nil, 0, input)
+	if predictExplain != "" {
+		attribution, err := visualize.Explain(model, input, predictClass, predictExplain)
+		if err != nil {
+			return err
+		}
+		output := predictOutput
+		if output == "" {
+			output = name + "_" + predictExplain + ".png"
+		}
+		if err := writePNG(output, visualize.ExplanationStrip(values, attribution.Shape, attribution.Heatmap(), predictScale)); err != nil {
+			return err
+		}
+		fmt.Printf("Wrote %s (%s of class %s: input, heatmap, overlay)\n", output, predictExplain, labels[attribution.Class])
+	}
+	if predictOcclusion {
+		occlusion, err := nn.Occlusion(model, input, predictClass, predictPatch, predictStride, predictFill)
+		if err != nil {
+			return err
+		}
+		worst := occlusion.MostSensitive()
+		fmt.Printf("Occlusion: %d patches of %dx%d, covering (%d, %d) drops P(%s) from %.1f%% to %.1f%%\n",
+			len(occlusion.Patches), occlusion.Patch, occlusion.Patch, worst.X, worst.Y,
+			labels[occlusion.Class], occlusion.Probability*100, worst.Probability*100)
+		output := predictOcclusionOutput
+		if output == "" {
+			output = name + "_occlusion.png"
+		}
+		if err := writePNG(output, visualize.ExplanationStrip(values, occlusion.Shape, occlusion.Heatmap(), predictScale)); err != nil {
+			return err
+		}
+		fmt.Printf("Wrote %s (probability drop of class %s: input, heatmap, overlay)\n", output, labels[occlusion.Class])
+	}
+	return nil
+}
+
+// imageInput 讀取 PNG 或 JPEG 圖片並以手寫板相同的方式預處理
+func imageInput(path string, shape nn.ImageShape) (*mat.Dense, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", path, err)
+	}
+	options, err := preprocess.Parse(predictPipeline)
+	if err != nil {
+		return nil, err
+	}
+	_, input := drawing.PreprocessImage(img, shape, options)
+	return input, nil
+}
+
+// printProbabilities 依機率由高到低列出每個類別，第一行為預測結果
+func printProbabilities(probs *mat.Dense, labels []string) {
+	order := make([]int, len(labels))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return probs.At(order[a], 0) > probs.At(order[b], 0) })
+	fmt.Printf("Prediction: %s (Confidence: %.1f%%)\n", labels[order[0]], probs.At(order[0], 0)*100)
+	for _, class := range order {
+		p := probs.At(class, 0)
+		fmt.Printf("  %-10s %6.2f%% %s\n", labels[class], p*100, strings.Repeat("#", int(p*40+0.5)))
+	}
+}
+
+func writePNG(path string, img image.Image) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(file, img); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
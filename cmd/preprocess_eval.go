@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"golang-neural-network/nn"
+	"golang-neural-network/preprocess"
+
+	"github.com/spf13/cobra"
+)
+
+// preprocess-eval 子命令的參數
+var preprocessPipelines []string
+
+var preprocessEvalCmd = &cobra.Command{
+	Use:   "preprocess-eval <model.json> [drawings-dir]",
+	Short: "Compare the accuracy of preprocessing pipelines on saved drawings",
+	Long: `Preprocess every drawing in <drawings-dir>/<label>/*.png with each pipeline and
+report how many the model classifies correctly. The drawing board saves drawings
+to drawings/<label>/ with "Save drawing".
+
+A pipeline is legacy, mnist, or a comma-separated list of steps:
+  box | mass                   centering by bounding box or by center of mass
+  bilinear | area | lanczos    downscaling
+  deskew                       straighten slanted digits by image moments
+  stroke                       normalize the stroke width`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := "drawings"
+		if len(args) == 2 {
+			dir = args[1]
+		}
+		return evaluatePreprocessing(args[0], dir)
+	},
+}
+
+func init() {
+	preprocessEvalCmd.Flags().StringArrayVar(&preprocessPipelines, "pipeline",
+		[]string{"legacy", "mnist", "mass,lanczos", "mass,area,deskew", "mass,area,stroke", "mass,area,deskew,stroke"},
+		"pipeline to evaluate, repeat for several")
+	rootCmd.AddCommand(preprocessEvalCmd)
+}
+
+func evaluatePreprocessing(modelPath, dir string) error {
+	model, err := nn.LoadModel(modelPath)
+	if err != nil {
+		return fmt.Errorf("loading model: %w", err)
+	}
+	drawings, err := preprocess.LoadDrawings(dir)
+	if err != nil {
+		return err
+	}
+	pipelines := make([]preprocess.Options, len(preprocessPipelines))
+	for i, spec := range preprocessPipelines {
+		if pipelines[i], err = preprocess.Parse(spec); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Evaluating %d drawings from %s\n", len(drawings), dir)
+	fmt.Printf("%-28s %-9s %s\n", "PIPELINE", "ACCURACY", "CORRECT")
+	for _, options := range pipelines {
+		correct, err := preprocess.Evaluate(model, drawings, options)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%-28s %-9.4f %d/%d\n", options, float64(correct)/float64(len(drawings)), correct, len(drawings))
+	}
+	return nil
+}
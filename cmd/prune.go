@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang-neural-network/nn"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	pruneMethodGlobal  = "global"
+	pruneMethodLayer   = "layer"
+	pruneMethodNeurons = "neurons"
+)
+
+// prune 子命令的參數
+var (
+	pruneMethod         string
+	pruneLevels         string
+	pruneFinetuneEpochs int
+	pruneBatchSize      int
+	pruneLearningRate   float64
+	pruneDataset        string
+)
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune <model.json>",
+	Short: "Prune a trained model at several sparsity levels, fine-tune and report accuracy",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return pruneModel(args[0])
+	},
+}
+
+func init() {
+	pruneCmd.Flags().StringVar(&pruneMethod, "method", pruneMethodGlobal, "global or layer (unstructured magnitude pruning), or neurons (remove hidden neurons)")
+	pruneCmd.Flags().StringVar(&pruneLevels, "levels", "0.5,0.7,0.9", "comma separated sparsity levels (fraction of neurons per layer for --method neurons)")
+	pruneCmd.Flags().IntVar(&pruneFinetuneEpochs, "finetune-epochs", 1, "epochs of fine-tuning after pruning (0 to skip)")
+	pruneCmd.Flags().IntVar(&pruneBatchSize, "batch-size", 32, "mini-batch size for fine-tuning")
+	pruneCmd.Flags().Float64Var(&pruneLearningRate, "learning-rate", 0, "learning rate for fine-tuning (default: the model's learning rate)")
+	pruneCmd.Flags().StringVar(&pruneDataset, "dataset", "", "dataset the model was trained on (MNIST or CIFAR-10, default: inferred from the model input shape)")
+	rootCmd.AddCommand(pruneCmd)
+}
+
+// pruneResult 單一剪枝比例的結果
+type pruneResult struct {
+	level          float64
+	sparsity       float64
+	params         int
+	prunedAccuracy float64
+	tunedAccuracy  float64
+	path           string
+}
+
+func parseLevels(levels string) ([]float64, error) {
+	var result []float64
+	for _, part := range strings.Split(levels, ",") {
+		level, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || level < 0 || level >= 1 {
+			return nil, fmt.Errorf("Invalid level %q, must be in [0, 1)", part)
+		}
+		result = append(result, level)
+	}
+	return result, nil
+}
+
+func applyPruning(model *nn.NeuralNetwork, level float64) error {
+	switch pruneMethod {
+	case pruneMethodGlobal:
+		return model.PruneMagnitude(level, nn.PruneGlobal)
+	case pruneMethodLayer:
+		return model.PruneMagnitude(level, nn.PrunePerLayer)
+	case pruneMethodNeurons:
+		return model.PruneNeurons(level)
+	default:
+		return fmt.Errorf("Unknown prune method %q", pruneMethod)
+	}
+}
+
+func pruneModel(modelPath string) error {
+	levels, err := parseLevels(pruneLevels)
+	if err != nil {
+		return err
+	}
+	original, err := nn.LoadModel(modelPath)
+	if err != nil {
+		return fmt.Errorf("loading model: %w", err)
+	}
+	dataset := pruneDataset
+	if dataset == "" {
+		dataset = datasetForModel(original)
+	}
+	trainingSet, testSet, _, err := loadDataset(dataset)
+	if err != nil {
+		return err
+	}
+	baseline, err := nn.Evaluate(original, testSet)
+	if err != nil {
+		return err
+	}
+
+	var results []pruneResult
+	for _, level := range levels {
+		fmt.Printf("\nPruning (%s) at level %.2f...\n", pruneMethod, level)
+		model := original.Clone()
+		if err := applyPruning(model, level); err != nil {
+			return err
+		}
+		pruned, err := nn.Evaluate(model, testSet)
+		if err != nil {
+			return err
+		}
+		result := pruneResult{
+			level:          level,
+			prunedAccuracy: pruned["accuracy"],
+			tunedAccuracy:  pruned["accuracy"],
+		}
+
+		if pruneFinetuneEpochs > 0 {
+			if pruneLearningRate > 0 {
+				model.LearningRate = pruneLearningRate
+			}
+			summary := &trainingSummary{}
+			config := nn.TrainingConfig{
+				Epochs:    pruneFinetuneEpochs,
+				BatchSize: pruneBatchSize,
+				Workers:   trainWorkers,
+				Seed:      trainSeed,
+				Callbacks: []nn.Callback{nn.ProgressLogger{Out: os.Stdout}, summary},
+			}
+			ctx, cancel := interruptContext()
+			err := nn.TrainingLoop(ctx, model, config, trainingSet, testSet)
+			cancel()
+			if errors.Is(err, context.Canceled) {
+				// Ctrl-C：儲存正在 fine-tune 的模型與最好的模型，輸出目前為止的結果後以 exitInterrupted 結束
+				saved, saveErr := summary.saveInterrupted(model, filepath.Dir(modelPath))
+				summary.print()
+				for _, path := range saved {
+					fmt.Printf("Saved %s\n", path)
+				}
+				if saveErr != nil {
+					fmt.Printf("Error saving model: %v\n", saveErr)
+				}
+				fmt.Printf("Pruning interrupted at level %.2f\n", level)
+				printPruneResults(original, baseline, results)
+				os.Exit(exitInterrupted)
+			}
+			if err != nil {
+				return err
+			}
+			tuned, err := nn.Evaluate(model, testSet)
+			if err != nil {
+				return err
+			}
+			result.tunedAccuracy = tuned["accuracy"]
+		}
+		result.sparsity = model.Sparsity()
+		result.params = model.ParameterCount()
+		result.path = fmt.Sprintf("%s_pruned%02.0f.json", strings.TrimSuffix(modelPath, ".json"), level*100)
+		if err := nn.SaveModel(model, result.path); err != nil {
+			return fmt.Errorf("saving pruned model: %w", err)
+		}
+		results = append(results, result)
+	}
+
+	printPruneResults(original, baseline, results)
+	return nil
+}
+
+// printPruneResults 輸出每個剪枝比例的結果表
+func printPruneResults(original *nn.NeuralNetwork, baseline nn.Metrics, results []pruneResult) {
+	fmt.Printf("\nMethod: %s | Baseline accuracy: %.4f | Parameters: %d\n", pruneMethod, baseline["accuracy"], original.ParameterCount())
+	fmt.Printf("%-6s %-9s %-8s %-16s %-16s %s\n", "Level", "Sparsity", "Params", "Pruned acc", "Fine-tuned acc", "Saved to")
+	for _, r := range results {
+		fmt.Printf("%-6.2f %-9.4f %-8d %-16.4f %-16.4f %s\n", r.level, r.sparsity, r.params, r.prunedAccuracy, r.tunedAccuracy, r.path)
+	}
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"golang-neural-network/nn"
+	"math/rand"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// quantize 子命令的參數
+var (
+	quantizeOutput      string
+	quantizeCalibration int
+	quantizeDataset     string
+)
+
+var quantizeCmd = &cobra.Command{
+	Use:   "quantize <model.json>",
+	Short: "Convert a trained model into an int8 model for integer-only inference",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return quantizeModel(args[0])
+	},
+}
+
+func init() {
+	quantizeCmd.Flags().StringVarP(&quantizeOutput, "output", "o", "", "output file (default: the model path with a .q8 extension)")
+	quantizeCmd.Flags().IntVar(&quantizeCalibration, "calibration", 1000, "number of training samples used to calibrate activation ranges")
+	quantizeCmd.Flags().StringVar(&quantizeDataset, "dataset", "", "dataset the model was trained on (MNIST or CIFAR-10, default: inferred from the model input shape)")
+	rootCmd.AddCommand(quantizeCmd)
+}
+
+// datasetForModel 依模型的輸入形狀判斷使用的資料集
+func datasetForModel(model *nn.NeuralNetwork) string {
+	if model.ImageShape() == nn.CIFAR10Shape {
+		return datasetCIFAR10
+	}
+	return datasetMNIST
+}
+
+func quantizeModel(modelPath string) error {
+	model, err := nn.LoadModel(modelPath)
+	if err != nil {
+		return fmt.Errorf("loading model: %w", err)
+	}
+	dataset := quantizeDataset
+	if dataset == "" {
+		dataset = datasetForModel(model)
+	}
+	trainingSet, testSet, _, err := loadDataset(dataset)
+	if err != nil {
+		return err
+	}
+
+	// 從訓練集隨機取樣作為校正資料
+	seed := trainSeed
+	if seed == 0 {
+		seed = 1
+	}
+	count := min(max(quantizeCalibration, 1), len(trainingSet))
+	calibration := make([]nn.TrainingData, count)
+	for i, idx := range rand.New(rand.NewSource(seed)).Perm(len(trainingSet))[:count] {
+		calibration[i] = trainingSet[idx]
+	}
+	fmt.Printf("Calibrating on %d training samples...\n", count)
+	quantized, err := nn.QuantizeInt8(model, calibration)
+	if err != nil {
+		return err
+	}
+
+	output := quantizeOutput
+	if output == "" {
+		output = strings.TrimSuffix(modelPath, ".json") + ".q8"
+	}
+	if err := nn.SaveQuantized(quantized, output); err != nil {
+		return fmt.Errorf("saving quantized model: %w", err)
+	}
+
+	fmt.Println("\nEvaluating on the test set...")
+	floatMetrics, err := nn.Evaluate(model, testSet)
+	if err != nil {
+		return err
+	}
+	int8Accuracy, err := quantized.Accuracy(testSet)
+	if err != nil {
+		return err
+	}
+	floatAccuracy := floatMetrics["accuracy"]
+	fmt.Printf("Float accuracy: %.4f\n", floatAccuracy)
+	fmt.Printf("Int8 accuracy:  %.4f\n", int8Accuracy)
+	fmt.Printf("Accuracy delta: %+.4f\n", int8Accuracy-floatAccuracy)
+
+	if floatInfo, err := os.Stat(modelPath); err == nil {
+		if int8Info, err := os.Stat(output); err == nil {
+			fmt.Printf("Model size: %d bytes -> %d bytes\n", floatInfo.Size(), int8Info.Size())
+		}
+	}
+	fmt.Printf("Quantized model saved to %s\n", output)
+	return nil
+}
@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"fmt"
+	"golang-neural-network/chart"
+	"golang-neural-network/nn"
+	"golang-neural-network/runs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gonum.org/v1/gonum/mat"
+)
+
+// report 子命令的參數
+var (
+	reportFormat  string
+	reportOutput  string
+	reportWorst   int
+	reportDataset string
+)
+
+// 圖表大小
+const (
+	chartWidth  = 720
+	chartHeight = 420
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report <run-dir>",
+	Short: "Render training curves, confusion matrix and worst misclassified images of a run to SVG or PNG",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return renderReport(args[0])
+	},
+}
+
+func init() {
+	reportCmd.Flags().StringVar(&reportFormat, "format", "svg", "image format: svg or png")
+	reportCmd.Flags().StringVarP(&reportOutput, "output", "o", "", "output directory (default: <run-dir>/report)")
+	reportCmd.Flags().IntVar(&reportWorst, "worst", 16, "number of misclassified test images to show")
+	reportCmd.Flags().StringVar(&reportDataset, "dataset", "", "dataset to evaluate on (default: the dataset of the run)")
+	rootCmd.AddCommand(reportCmd)
+}
+
+// reportFile 一張已輸出的圖表
+type reportFile struct {
+	title string
+	path  string
+}
+
+func renderReport(runDir string) error {
+	if reportFormat != "svg" && reportFormat != "png" {
+		return fmt.Errorf("Unknown format %q, use svg or png", reportFormat)
+	}
+	run, err := runs.Load(runDir)
+	if err != nil {
+		return fmt.Errorf("loading run: %w", err)
+	}
+	records, err := run.Metrics()
+	if err != nil {
+		return fmt.Errorf("reading metrics: %w", err)
+	}
+	output := reportOutput
+	if output == "" {
+		output = filepath.Join(runDir, "report")
+	}
+	if err := os.MkdirAll(output, 0755); err != nil {
+		return err
+	}
+
+	var files []reportFile
+	save := func(name, title string, c chart.Chart) error {
+		path := filepath.Join(output, name+"."+reportFormat)
+		if err := chart.Save(path, c, chartWidth, chartHeight); err != nil {
+			return fmt.Errorf("rendering %s: %w", name, err)
+		}
+		files = append(files, reportFile{title: title, path: path})
+		return nil
+	}
+
+	for _, c := range curveCharts(records) {
+		if err := save(c.name, c.chart.Title, c.chart); err != nil {
+			return err
+		}
+	}
+
+	// confusion matrix 與分類錯誤的影像需要模型與測試集
+	model, err := nn.LoadModel(run.Path(runs.ModelFile))
+	switch {
+	case err != nil:
+		fmt.Printf("Skipping confusion matrix: %v\n", err)
+	case !model.IsClassifier():
+		fmt.Println("Skipping confusion matrix: the model is not a softmax classifier")
+	default:
+		dataset := reportDataset
+		if dataset == "" {
+			dataset = run.Config.Dataset
+		}
+		_, testSet, _, err := loadDataset(dataset)
+		if err != nil {
+			fmt.Printf("Skipping confusion matrix: %v\n", err)
+			break
+		}
+		predictions, err := nn.Predictions(model, testSet)
+		if err != nil {
+			return err
+		}
+		labels := classLabels(dataset, model.OutputClass)
+		if err := save("confusion_matrix", "Confusion matrix", confusionChart(predictions, labels)); err != nil {
+			return err
+		}
+		if err := save("worst_misclassified", "Worst misclassified", worstChart(model, testSet, predictions, labels)); err != nil {
+			return err
+		}
+	}
+
+	index := filepath.Join(output, "report.md")
+	if err := os.WriteFile(index, []byte(reportMarkdown(run, files, output)), 0644); err != nil {
+		return err
+	}
+	for _, f := range files {
+		fmt.Printf("Wrote %s\n", f.path)
+	}
+	fmt.Printf("Wrote %s\n", index)
+	return nil
+}
+
+type namedChart struct {
+	name  string
+	chart chart.LineChart
+}
+
+// curveCharts 每個 epoch 的 loss 與 accuracy，以及每個 step 的 learning rate
+func curveCharts(records []nn.MetricsRecord) []namedChart {
+	epochs := nn.EpochRecords(records)
+	var x, trainLoss, valLoss, trainAcc, valAcc, advAcc []float64
+	hasAcc, hasAdv := false, false
+	for _, r := range epochs {
+		x = append(x, float64(r.Epoch))
+		trainLoss = append(trainLoss, r.Loss)
+		valLoss = append(valLoss, r.Validation["loss"])
+		trainAcc = append(trainAcc, r.Accuracy)
+		acc, ok := r.Validation["accuracy"]
+		hasAcc = hasAcc || ok
+		valAcc = append(valAcc, acc)
+		// 對抗訓練時另外記錄對抗樣本上的 validation accuracy
+		adv, ok := r.Validation["adversarial_accuracy"]
+		hasAdv = hasAdv || ok
+		advAcc = append(advAcc, adv)
+	}
+	hasVal := len(epochs) > 0 && epochs[0].Validation != nil
+
+	loss := chart.LineChart{Title: "Loss per epoch", XLabel: "epoch", YLabel: "loss",
+		Series: []chart.Series{{Name: "train", X: x, Y: trainLoss}}}
+	if hasVal {
+		loss.Series = append(loss.Series, chart.Series{Name: "validation", X: x, Y: valLoss})
+	}
+	charts := []namedChart{{"loss", loss}}
+	if hasAcc {
+		accuracy := chart.LineChart{Title: "Accuracy per epoch", XLabel: "epoch", YLabel: "accuracy",
+			Series: []chart.Series{{Name: "train", X: x, Y: trainAcc}, {Name: "validation", X: x, Y: valAcc}}}
+		if hasAdv {
+			accuracy.Series = append(accuracy.Series, chart.Series{Name: "adversarial", X: x, Y: advAcc})
+		}
+		charts = append(charts, namedChart{"accuracy", accuracy})
+	}
+
+	var steps, rates []float64
+	for _, r := range records {
+		if r.Type == nn.RecordStep {
+			steps = append(steps, float64(r.Step))
+			rates = append(rates, r.LearningRate)
+		}
+	}
+	if len(steps) > 0 {
+		charts = append(charts, namedChart{"learning_rate", chart.LineChart{Title: "Learning rate schedule", XLabel: "step", YLabel: "learning rate",
+			Series: []chart.Series{{Name: "learning rate", X: steps, Y: rates}}}})
+	}
+	return charts
+}
+
+func confusionChart(predictions []nn.Prediction, labels []string) chart.Heatmap {
+	counts := nn.ConfusionMatrix(predictions, len(labels))
+	values := make([][]float64, len(counts))
+	for i, row := range counts {
+		values[i] = make([]float64, len(row))
+		for j, n := range row {
+			values[i][j] = float64(n)
+		}
+	}
+	return chart.Heatmap{Title: "Confusion matrix", XLabel: "predicted", YLabel: "true", Labels: labels, Values: values}
+}
+
+func worstChart(model *nn.NeuralNetwork, testSet []nn.TrainingData, predictions []nn.Prediction, labels []string) chart.ImageGrid {
+	shape := model.ImageShape()
+	grid := chart.ImageGrid{Title: "Most confident mistakes (true -> predicted, confidence)"}
+	for _, p := range nn.WorstMisclassified(predictions, reportWorst) {
+		values := mat.Col(nil, 0, testSet[p.Index].Input)
+		grid.Images = append(grid.Images, chart.TensorImage(values, shape.Channels, shape.Height, shape.Width))
+		grid.Captions = append(grid.Captions, fmt.Sprintf("%s -> %s %.0f%%", labels[p.Label], labels[p.Predicted], p.Confidence*100))
+	}
+	return grid
+}
+
+// classLabels CIFAR-10 使用類別名稱，其他資料集使用數字
+func classLabels(dataset string, classes int) []string {
+	if dataset == datasetCIFAR10 && classes == len(nn.CIFAR10Labels) {
+		return nn.CIFAR10Labels
+	}
+	labels := make([]string, classes)
+	for i := range labels {
+		labels[i] = fmt.Sprint(i)
+	}
+	return labels
+}
+
+// reportMarkdown 可以直接附在 model review 的摘要，圖片以相對路徑引用
+func reportMarkdown(run *runs.Run, files []reportFile, output string) string {
+	var b strings.Builder
+	c := run.Config
+	fmt.Fprintf(&b, "# Run %s\n\n", run.ID)
+	fmt.Fprintf(&b, "| Dataset | Hidden | Learning rate | Batch | Epochs | Precision | Loss |\n|---|---|---|---|---|---|---|\n")
+	fmt.Fprintf(&b, "| %s | %s | %g | %d | %d | %s | %s |\n\n", c.Dataset, formatLayers(c.Hidden), c.LearningRate, c.BatchSize, c.Epochs, c.Precision, c.Loss)
+	if c.Adversarial != nil {
+		fmt.Fprintf(&b, "Adversarial training: %s\n\n", c.Adversarial)
+	}
+	if r := run.Report; r != nil {
+		fmt.Fprintf(&b, "Status: %s after %d epochs (%d steps), final training loss %.4f\n\n", r.Status, r.Epochs, r.Steps, r.TrainLoss)
+		if r.Validation != nil {
+			fmt.Fprintf(&b, "Validation: %s\n\n", r.Validation)
+		}
+	}
+	for _, f := range files {
+		rel, err := filepath.Rel(output, f.path)
+		if err != nil {
+			rel = f.path
+		}
+		fmt.Fprintf(&b, "## %s\n\n![%s](%s)\n\n", f.title, f.title, filepath.ToSlash(rel))
+	}
+	return b.String()
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+	"golang-neural-network/chart"
+	"golang-neural-network/nn"
+	"math/rand"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gonum.org/v1/gonum/mat"
+)
+
+// robustness 子命令的參數
+var (
+	robustnessAttack   string
+	robustnessEpsilons []float64
+	robustnessNorm     string
+	robustnessSteps    int
+	robustnessStepSize float64
+	robustnessSamples  int
+	robustnessExamples int
+	robustnessDataset  string
+	robustnessOutput   string
+)
+
+var robustnessCmd = &cobra.Command{
+	Use:   "robustness <model.json>",
+	Short: "Report test accuracy under FGSM or PGD attacks at several epsilon values and save adversarial examples",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return evaluateRobustness(args[0])
+	},
+}
+
+func init() {
+	robustnessCmd.Flags().StringVar(&robustnessAttack, "attack", "fgsm", "attack: fgsm or pgd")
+	robustnessCmd.Flags().Float64SliceVar(&robustnessEpsilons, "epsilons", []float64{0, 0.05, 0.1, 0.15, 0.2, 0.3}, "perturbation sizes to evaluate")
+	robustnessCmd.Flags().StringVar(&robustnessNorm, "norm", "linf", "norm bounding the perturbation: linf or l2")
+	robustnessCmd.Flags().IntVar(&robustnessSteps, "steps", nn.DefaultPGDSteps, "number of PGD steps")
+	robustnessCmd.Flags().Float64Var(&robustnessStepSize, "step-size", 0, "PGD step size (default: 2.5 * epsilon / steps)")
+	robustnessCmd.Flags().IntVar(&robustnessSamples, "samples", 1000, "number of test samples to attack (0 for the whole test set)")
+	robustnessCmd.Flags().IntVar(&robustnessExamples, "examples", 8, "number of test samples shown in the adversarial example image")
+	robustnessCmd.Flags().StringVar(&robustnessDataset, "dataset", "", "dataset the model was trained on (default: inferred from the model input shape)")
+	robustnessCmd.Flags().StringVarP(&robustnessOutput, "output", "o", "", "adversarial example image (default: the model path with a _adversarial.png suffix)")
+	rootCmd.AddCommand(robustnessCmd)
+}
+
+func evaluateRobustness(modelPath string) error {
+	model, err := nn.LoadModel(modelPath)
+	if err != nil {
+		return fmt.Errorf("loading model: %w", err)
+	}
+	dataset := robustnessDataset
+	if dataset == "" {
+		dataset = datasetForModel(model)
+	}
+	_, testSet, _, err := loadDataset(dataset)
+	if err != nil {
+		return err
+	}
+	if robustnessSamples > 0 && robustnessSamples < len(testSet) {
+		testSet = testSet[:robustnessSamples]
+	}
+
+	fmt.Printf("Attacking %d test samples\n", len(testSet))
+
+	// PGD 的隨機起點
+	seed := trainSeed
+	if seed == 0 {
+		seed = 1
+	}
+	rng := rand.New(rand.NewSource(seed))
+	examples := min(robustnessExamples, len(testSet))
+	labels := classLabels(dataset, model.OutputClass)
+	shape := model.ImageShape()
+
+	// 每個 epsilon 保留前 examples 個對抗樣本與預測，最後依樣本排成列
+	predictions := make([][]nn.Prediction, len(robustnessEpsilons))
+	inputs := make([][]nn.TrainingData, len(robustnessEpsilons))
+	stepSize := "2.5 * epsilon / steps"
+	if robustnessStepSize > 0 {
+		stepSize = fmt.Sprint(robustnessStepSize)
+	}
+	attack := fmt.Sprintf("%s, %s norm", robustnessAttack, robustnessNorm)
+	if robustnessAttack == string(nn.AttackPGD) {
+		attack += fmt.Sprintf(", %d steps of %s, random start", robustnessSteps, stepSize)
+	}
+	fmt.Printf("Attack: %s\n", attack)
+	// 每個 epsilon 算完就輸出一列，PGD 在大的測試集上需要一段時間
+	fmt.Printf("%-9s %-9s %s\n", "EPSILON", "ACCURACY", "LOSS")
+	for i, epsilon := range robustnessEpsilons {
+		config := nn.AttackConfig{
+			Method:      nn.AttackMethod(robustnessAttack),
+			Epsilon:     epsilon,
+			Norm:        nn.AttackNorm(robustnessNorm),
+			Steps:       robustnessSteps,
+			StepSize:    robustnessStepSize,
+			RandomStart: robustnessAttack == string(nn.AttackPGD),
+		}
+		if err := config.Validate(); err != nil {
+			return err
+		}
+		adversarial, err := nn.AdversarialExamples(model, testSet, config, rng)
+		if err != nil {
+			return err
+		}
+		metrics, err := nn.Evaluate(model, adversarial)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%-9g %-9s %s\n", epsilon, formatMetric(metrics, "accuracy"), formatMetric(metrics, "loss"))
+		if examples > 0 && model.IsClassifier() {
+			inputs[i] = adversarial[:examples]
+			predictions[i], err = nn.Predictions(model, adversarial[:examples])
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if examples == 0 || !model.IsClassifier() {
+		return nil
+	}
+
+	grid := chart.ImageGrid{Title: "Adversarial examples (columns: epsilon, caption: prediction, * = wrong)", Columns: len(robustnessEpsilons)}
+	for s := 0; s < examples; s++ {
+		for i, epsilon := range robustnessEpsilons {
+			p := predictions[i][s]
+			caption := fmt.Sprintf("%g: %s", epsilon, labels[p.Predicted])
+			if !p.Correct() {
+				caption += " *"
+			}
+			grid.Images = append(grid.Images, chart.TensorImage(mat.Col(nil, 0, inputs[i][s].Input), shape.Channels, shape.Height, shape.Width))
+			grid.Captions = append(grid.Captions, caption)
+		}
+	}
+	output := robustnessOutput
+	if output == "" {
+		output = strings.TrimSuffix(modelPath, filepath.Ext(modelPath)) + "_adversarial.png"
+	}
+	// 讓每一格接近正方形：標題 36 像素，每列多留 26 像素給說明文字與間隔
+	cell := (chartWidth - 8) / len(robustnessEpsilons)
+	if err := chart.Save(output, grid, chartWidth, 36+examples*(cell+26)); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %s (rows: test samples, columns: epsilon)\n", output)
+	return nil
+}
@@ -1,12 +1,15 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"golang-neural-network/drawing"
 	"golang-neural-network/nn"
+	"golang-neural-network/runs"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strconv"
 
 	"github.com/AlecAivazis/survey/v2"
@@ -24,6 +27,19 @@ var rootCmd = &cobra.Command{
 	},
 }
 
+// 訓練相關的命令列參數
+var (
+	trainWorkers   int    // 計算梯度的 goroutine 數量
+	trainSeed      int64  // 打亂訓練集順序的 seed，0 代表不打亂
+	trainPrecision string // float64 或 float32
+)
+
+func init() {
+	rootCmd.PersistentFlags().IntVar(&trainWorkers, "workers", runtime.NumCPU(), "number of goroutines computing gradients for each mini-batch")
+	rootCmd.PersistentFlags().Int64Var(&trainSeed, "seed", 0, "seed for shuffling the training set every epoch (0 keeps the file order)")
+	rootCmd.PersistentFlags().StringVar(&trainPrecision, "precision", string(nn.Float64), "compute precision for training and inference: float64 or float32")
+}
+
 // main.go 會調用這個函數來啟動 CLI
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
@@ -108,7 +124,14 @@ func positiveFloatValidator(val interface{}) error {
 }
 
 func trainingFlow(){
-	//接收參數：幾層hidden, [hiddenint], learningRate, epoch
+	//接收參數：資料集, 幾層hidden, [hiddenint], learningRate, epoch
+	var dataset string
+	survey.AskOne(&survey.Select{
+		Message: "Choose dataset:",
+		Options: []string{datasetMNIST, datasetCIFAR10},
+		Default: datasetMNIST,
+	}, &dataset)
+
 	var hiddenLayerNumStr string
 	var hiddenLayerNum int
 	survey.AskOne(&survey.Input{
@@ -149,69 +172,136 @@ func trainingFlow(){
     
     epoch, _ = strconv.Atoi(epochStr)
 
+	var batchSizeStr string
+	survey.AskOne(&survey.Input{
+		Message: "Enter Batch Size:",
+		Default: "1",
+	}, &batchSizeStr, survey.WithValidator(positiveIntValidator))
+	batchSize, _ := strconv.Atoi(batchSizeStr)
+
+	lossSettings := askLossSettings()
+
 	fmt.Printf("\nTraining Configuration:\n")
+	fmt.Printf("Dataset: %s\n", dataset)
 	fmt.Printf("Hidden Layers: %d\n", hiddenLayerNum)
 	fmt.Printf("Layer Nodes: %v\n", layerNodesAmount)
 	fmt.Printf("Learning Rate: %f\n", learningRate)
 	fmt.Printf("Epochs: %d\n", epoch)
+	fmt.Printf("Batch Size: %d (workers: %d)\n", batchSize, trainWorkers)
+	fmt.Printf("Precision: %s\n", trainPrecision)
+	fmt.Printf("Loss: %s\n", lossSettings)
 	
-	// Create network
-	network, err := nn.NewNeuralNetwork(784, 10, layerNodesAmount, learningRate)
+	// Load training set
+	fmt.Println("\nLoading training data...")
+	trainingSet, valSet, shape, err := loadDataset(dataset)
+	if err != nil {
+		fmt.Printf("Error loading data: %v\n", err)
+		return
+	}
+
+	// Create network（輸入大小與類別數由資料集決定）
+	inputs, classes, err := nn.DatasetShape(trainingSet)
+	if err != nil {
+		fmt.Printf("Error reading dataset shape: %v\n", err)
+		return
+	}
+	network, err := nn.NewNeuralNetwork(inputs, classes, layerNodesAmount, learningRate)
 	if err != nil {
 		fmt.Printf("Error creating neural network: %v\n", err)
 		return
 	}
-	
-	fmt.Println("\nNeural network created successfully!")
-	
-	// Load training set
-	fmt.Println("\nLoading training data...")
+	if shape.Size() == inputs {
+		network.InputShape = shape
+	}
+	if err := network.SetPrecision(nn.Precision(trainPrecision)); err != nil {
+		fmt.Printf("Error setting precision: %v\n", err)
+		return
+	}
+	fmt.Printf("Input size: %d, Classes: %d\n", inputs, classes)
 
-	// check if the user dont have train and test data
-	trainFilePath := "mnist_data/train.csv"
-	testFilePath := "mnist_data/test.csv"
+	lossConfig, err := lossSettings.lossConfig(trainingSet)
+	if err != nil {
+		fmt.Printf("Error configuring loss: %v\n", err)
+		return
+	}
+	if err := network.SetOutputHead(nn.OutputSoftmax, lossConfig); err != nil {
+		fmt.Printf("Error configuring loss: %v\n", err)
+		return
+	}
+	if lossConfig.ClassWeights != nil {
+		fmt.Printf("Class weights: %.3f\n", lossConfig.ClassWeights)
+	}
 	
-	if _, err := os.Stat(trainFilePath); errors.Is(err, os.ErrNotExist) {
-		fmt.Println("train CSV file not found, parsing MNIST images and labels")
-		err = nn.ConvertToCSV("mnist_data/train-images.idx3-ubyte", "mnist_data/train-labels.idx1-ubyte", "mnist_data/train.csv")
-		if err != nil {
-			fmt.Printf("Error converting training data: %v\n", err)
-			return
-		}
-	} 
-	if _, err := os.Stat(testFilePath); errors.Is(err, os.ErrNotExist) {
-		fmt.Println("test CSV file not found, parsing MNIST images and labels")
-		err = nn.ConvertToCSV("mnist_data/t10k-images.idx3-ubyte", "mnist_data/t10k-labels.idx1-ubyte", "mnist_data/test.csv")
-		if err != nil {
-			fmt.Printf("Error converting test data: %v\n", err)
-			return
-		}
-	} 
+	fmt.Println("\nNeural network created successfully!")
 
-	// loading training set
-	trainingSet, err := nn.LoadingDataFromCSV("mnist_data/train.csv")
+	adversarial, err := adversarialConfig()
 	if err != nil {
-		fmt.Printf("Error loading training data: %v\n", err)
+		fmt.Printf("Error configuring adversarial training: %v\n", err)
 		return
 	}
-	fmt.Printf("Loaded %d training samples\n", len(trainingSet))
+	if adversarial != nil {
+		fmt.Printf("Adversarial training: %s\n", adversarial)
+	}
 	
-	// load validation set
-	fmt.Println("Loading test data...")
-	valSet, err := nn.LoadingDataFromCSV("mnist_data/test.csv")
+	recorder, err := newRunRecorder(runs.Config{
+		Dataset:      dataset,
+		Inputs:       inputs,
+		Classes:      classes,
+		Hidden:       layerNodesAmount,
+		LearningRate: learningRate,
+		Epochs:       epoch,
+		BatchSize:    batchSize,
+		Workers:      trainWorkers,
+		Seed:         trainSeed,
+		Precision:    trainPrecision,
+		Loss:         lossSettings.String(),
+		Adversarial:  adversarial,
+	})
 	if err != nil {
-		fmt.Printf("Error loading test data: %v\n", err)
+		fmt.Printf("Error creating run: %v\n", err)
 		return
 	}
-	fmt.Printf("Loaded %d test samples\n", len(valSet))
-	
-	fmt.Println("\nStarting training...")
-	err = nn.TrainingLoop(network, epoch, trainingSet, valSet)
+	fmt.Printf("Recording run in %s\n", recorder.run.Dir)
+
+	fmt.Println("\nStarting training... (Ctrl-C stops after the current step and saves the model)")
+	summary := &trainingSummary{}
+	config := nn.TrainingConfig{
+		Epochs:      epoch,
+		BatchSize:   batchSize,
+		Workers:     trainWorkers,
+		Seed:        trainSeed,
+		Adversarial: adversarial,
+		Callbacks: append([]nn.Callback{
+			nn.ProgressBar{Out: os.Stdout},
+			nn.ProgressLogger{Out: os.Stdout},
+			summary,
+		}, recorder.callbacks()...),
+	}
+	ctx, cancel := interruptContext()
+	defer cancel()
+	err = nn.TrainingLoop(ctx, network, config, trainingSet, valSet)
+	if runErr := recorder.finish(network, valSet, summary, err); runErr != nil {
+		fmt.Printf("Error writing run report: %v\n", runErr)
+	}
+	if errors.Is(err, context.Canceled) {
+		// Ctrl-C：儲存目前的權重與最好的模型後以 exitInterrupted 結束
+		saved, saveErr := summary.saveInterrupted(network, "models")
+		summary.print()
+		for _, path := range saved {
+			fmt.Printf("Saved %s\n", path)
+		}
+		fmt.Printf("Run recorded in %s\n", recorder.run.Dir)
+		if saveErr != nil {
+			fmt.Printf("Error saving model: %v\n", saveErr)
+		}
+		os.Exit(exitInterrupted)
+	}
 	if err != nil {
 		fmt.Printf("Error during training: %v\n", err)
 		return
 	}
 	fmt.Println("\nTraining completed!")
+	fmt.Printf("Run recorded in %s (see `runs list`)\n", recorder.run.Dir)
 	
 
 
@@ -238,6 +328,12 @@ func trainingFlow(){
 	}
 	
 	fmt.Printf("Model saved successfully to %s\n", modelPath)
+	if report := recorder.run.Report; report != nil {
+		report.Model = modelPath
+		if err := recorder.run.WriteReport(*report); err != nil {
+			fmt.Printf("Error writing run report: %v\n", err)
+		}
+	}
 }
 
 func loadModelFlow() {
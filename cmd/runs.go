@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang-neural-network/nn"
+	"golang-neural-network/runs"
+	"golang-neural-network/tensorboard"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// run 目錄相關的命令列參數
+var (
+	runsDir     string // 存放每次訓練紀錄的目錄
+	tensorBoard bool   // 在 run 目錄另外寫出 TensorBoard event 檔
+)
+
+var runsCmd = &cobra.Command{
+	Use:   "runs",
+	Short: "Inspect past training runs",
+}
+
+var runsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show past training runs side by side",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listRuns()
+	},
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&runsDir, "runs-dir", "runs", "directory where every training run records its config, metrics, model and report")
+	rootCmd.PersistentFlags().BoolVar(&tensorBoard, "tensorboard", false, "also write TensorBoard event files into the run directory (view with: tensorboard --logdir runs)")
+	runsCmd.AddCommand(runsListCmd)
+	rootCmd.AddCommand(runsCmd)
+}
+
+func listRuns() error {
+	list, err := runs.List(runsDir)
+	if err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		fmt.Printf("No runs found in %s\n", runsDir)
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RUN\tSTATUS\tHIDDEN\tLR\tBATCH\tEPOCHS\tPRECISION\tLOSS\tTRAIN LOSS\tVAL LOSS\tVAL ACC\tTIME")
+	for _, run := range list {
+		c := run.Config
+		status, epochs, trainLoss, valLoss, valAcc, duration := "running?", "-", "-", "-", "-", "-"
+		if r := run.Report; r != nil {
+			status = r.Status
+			epochs = fmt.Sprintf("%d/%d", r.Epochs, c.Epochs)
+			trainLoss = fmt.Sprintf("%.4f", r.TrainLoss)
+			valLoss = formatMetric(r.Validation, "loss")
+			valAcc = formatMetric(r.Validation, "accuracy")
+			duration = time.Duration(r.Duration * float64(time.Second)).Round(time.Second).String()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%g\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			run.ID, status, formatLayers(c.Hidden), c.LearningRate, c.BatchSize, epochs, c.Precision, c.Loss,
+			trainLoss, valLoss, valAcc, duration)
+	}
+	return w.Flush()
+}
+
+func formatMetric(metrics nn.Metrics, name string) string {
+	value, ok := metrics[name]
+	if !ok {
+		return "-"
+	}
+	return fmt.Sprintf("%.4f", value)
+}
+
+func formatLayers(hidden []int) string {
+	parts := make([]string, len(hidden))
+	for i, n := range hidden {
+		parts[i] = fmt.Sprint(n)
+	}
+	return strings.Join(parts, "-")
+}
+
+// runRecorder 把一次訓練記錄到 run 目錄：metrics log 由 nn.MetricsLog 寫入，結束時寫入模型與報告
+type runRecorder struct {
+	run     *runs.Run
+	metrics *os.File
+	log     *nn.MetricsLog
+	events  *tensorboard.Writer // 沒有 --tensorboard 時為 nil
+	started time.Time
+}
+
+func newRunRecorder(config runs.Config) (*runRecorder, error) {
+	run, err := runs.Create(runsDir, config)
+	if err != nil {
+		return nil, fmt.Errorf("Creating run directory: %w", err)
+	}
+	metrics, err := os.Create(run.Path(runs.MetricsFile))
+	if err != nil {
+		return nil, err
+	}
+	recorder := &runRecorder{run: run, metrics: metrics, log: &nn.MetricsLog{Out: metrics}, started: time.Now()}
+	if tensorBoard {
+		if recorder.events, err = tensorboard.NewWriter(run.Dir); err != nil {
+			metrics.Close()
+			return nil, fmt.Errorf("Creating TensorBoard writer: %w", err)
+		}
+	}
+	return recorder, nil
+}
+
+// callbacks 訓練時需要加入的 callback
+func (r *runRecorder) callbacks() []nn.Callback {
+	callbacks := []nn.Callback{r.log}
+	if r.events != nil {
+		callbacks = append(callbacks, &tensorboard.Callback{Writer: r.events, Images: 8})
+	}
+	return callbacks
+}
+
+// finish 儲存最終模型，在驗證集上評估並寫入 report.json
+func (r *runRecorder) finish(network *nn.NeuralNetwork, valSet []nn.TrainingData, summary *trainingSummary, trainErr error) error {
+	r.metrics.Close()
+	if r.events != nil {
+		if err := r.events.Close(); err != nil {
+			return err
+		}
+	}
+	report := r.report(summary, trainErr)
+	if err := nn.SaveModel(network, r.run.Path(runs.ModelFile)); err != nil {
+		return err
+	}
+	if len(valSet) > 0 {
+		metrics, err := nn.Evaluate(network, valSet)
+		if err != nil {
+			return err
+		}
+		report.Validation = metrics
+	}
+	return r.run.WriteReport(report)
+}
+
+// report 依訓練結果建立報告的基本欄位
+func (r *runRecorder) report(summary *trainingSummary, trainErr error) runs.Report {
+	report := runs.Report{
+		Status:   runs.StatusCompleted,
+		Epochs:   summary.epochs,
+		Duration: time.Since(r.started).Seconds(),
+	}
+	switch {
+	case errors.Is(trainErr, context.Canceled):
+		report.Status = runs.StatusInterrupted
+	case trainErr != nil:
+		report.Status = runs.StatusFailed
+		report.Error = trainErr.Error()
+	}
+	if summary.state != nil {
+		report.Steps = summary.state.Step
+		report.TrainLoss = summary.state.TrainLoss
+	}
+	return report
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"golang-neural-network/chart"
+	"golang-neural-network/nn"
+	"golang-neural-network/visualize"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// weights 子命令的參數
+var (
+	weightsOutput string
+	weightsScale  int
+	weightsFormat string
+)
+
+var weightsCmd = &cobra.Command{
+	Use:   "weights <model.json>",
+	Short: "Render first-layer features as an image grid and histograms of every layer's weights and biases",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return renderWeights(args[0])
+	},
+}
+
+func init() {
+	weightsCmd.Flags().StringVarP(&weightsOutput, "output", "o", "", "output directory (default: the model path with a _weights suffix)")
+	weightsCmd.Flags().IntVar(&weightsScale, "scale", 3, "enlargement factor of every feature image")
+	weightsCmd.Flags().StringVar(&weightsFormat, "format", "png", "histogram format: svg or png")
+	rootCmd.AddCommand(weightsCmd)
+}
+
+func renderWeights(modelPath string) error {
+	if weightsFormat != "svg" && weightsFormat != "png" {
+		return fmt.Errorf("Unknown format %q, use svg or png", weightsFormat)
+	}
+	model, err := nn.LoadModel(modelPath)
+	if err != nil {
+		return fmt.Errorf("loading model: %w", err)
+	}
+	output := weightsOutput
+	if output == "" {
+		output = strings.TrimSuffix(modelPath, filepath.Ext(modelPath)) + "_weights"
+	}
+	if err := os.MkdirAll(output, 0755); err != nil {
+		return err
+	}
+
+	// 第一層的特徵需要影像輸入，其他模型只輸出直方圖
+	grid, err := visualize.FeatureGrid(model, weightsScale)
+	if err != nil {
+		fmt.Printf("Skipping features: %v\n", err)
+	} else {
+		path := filepath.Join(output, "features.png")
+		if err := writePNG(path, grid); err != nil {
+			return err
+		}
+		fmt.Printf("Wrote %s (%d units)\n", path, model.Parameters()[0].Rows)
+	}
+
+	path := filepath.Join(output, "histograms."+weightsFormat)
+	if err := chart.Save(path, visualize.WeightHistograms(model), chartWidth, visualize.HistogramHeight(model)); err != nil {
+		return fmt.Errorf("rendering histograms: %w", err)
+	}
+	fmt.Printf("Wrote %s\n", path)
+	return nil
+}
@@ -3,8 +3,11 @@ package drawing
 import (
 	"fmt"
 	"golang-neural-network/nn"
+	"golang-neural-network/preprocess"
+	"golang-neural-network/visualize"
 	"image"
 	"image/color"
+	"image/draw"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
@@ -21,6 +24,10 @@ type DrawingCanvas struct {
 	raster    *canvas.Raster
 	drawing   bool
 	lastPoint fyne.Position
+	blank     bool
+
+	// OnChanged 每次筆跡改變時呼叫
+	OnChanged func()
 }
 
 // NewDrawingCanvas 創建新的繪圖畫布
@@ -55,6 +62,7 @@ func (dc *DrawingCanvas) Clear() {
 			dc.img.Set(x, y, color.White)
 		}
 	}
+	dc.blank = true
 	dc.raster.Refresh()
 }
 
@@ -70,6 +78,7 @@ func (dc *DrawingCanvas) Dragged(ev *fyne.DragEvent) {
 	dc.drawLine(dc.lastPoint, ev.Position)
 	dc.lastPoint = ev.Position
 	dc.raster.Refresh()
+	dc.changed()
 }
 
 // DragEnd 拖曳結束
@@ -81,6 +90,20 @@ func (dc *DrawingCanvas) DragEnd() {
 func (dc *DrawingCanvas) Tapped(ev *fyne.PointEvent) {
 	dc.drawPoint(ev.Position)
 	dc.raster.Refresh()
+	dc.changed()
+}
+
+// changed 標記畫布已有筆跡並通知 OnChanged
+func (dc *DrawingCanvas) changed() {
+	dc.blank = false
+	if dc.OnChanged != nil {
+		dc.OnChanged()
+	}
+}
+
+// IsBlank 畫布上還沒有任何筆跡
+func (dc *DrawingCanvas) IsBlank() bool {
+	return dc.blank
 }
 
 // drawLine 畫線（Bresenham 算法）
@@ -155,197 +178,17 @@ func abs(x int) int {
 	return x
 }
 
-// findBoundingBox 找到圖像中非白色像素的邊界框
-func findBoundingBox(img *image.RGBA) (minX, minY, maxX, maxY int, found bool) {
-	bounds := img.Bounds()
-	minX, minY = bounds.Max.X, bounds.Max.Y
-	maxX, maxY = bounds.Min.X, bounds.Min.Y
-	found = false
-
-	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
-		for x := bounds.Min.X; x < bounds.Max.X; x++ {
-			r, g, b, _ := img.At(x, y).RGBA()
-			// 檢查是否為非白色像素（有筆跡）
-			if r < 60000 || g < 60000 || b < 60000 {
-				found = true
-				if x < minX {
-					minX = x
-				}
-				if x > maxX {
-					maxX = x
-				}
-				if y < minY {
-					minY = y
-				}
-				if y > maxY {
-					maxY = y
-				}
-			}
-		}
-	}
-	return
-}
-
-// resizeBilinear 使用雙線性插值縮放圖片
-func resizeBilinear(src *image.RGBA, newWidth, newHeight int) *image.RGBA {
-	srcBounds := src.Bounds()
-	srcWidth := srcBounds.Dx()
-	srcHeight := srcBounds.Dy()
-
-	dst := image.NewRGBA(image.Rect(0, 0, newWidth, newHeight))
-
-	xRatio := float64(srcWidth-1) / float64(newWidth)
-	yRatio := float64(srcHeight-1) / float64(newHeight)
-
-	for y := 0; y < newHeight; y++ {
-		for x := 0; x < newWidth; x++ {
-			srcX := float64(x) * xRatio
-			srcY := float64(y) * yRatio
-
-			x0 := int(srcX)
-			y0 := int(srcY)
-			x1 := x0 + 1
-			y1 := y0 + 1
-
-			if x1 >= srcWidth {
-				x1 = srcWidth - 1
-			}
-			if y1 >= srcHeight {
-				y1 = srcHeight - 1
-			}
-
-			xFrac := srcX - float64(x0)
-			yFrac := srcY - float64(y0)
-
-			// 獲取四個角的像素值
-			r00, g00, b00, a00 := src.At(x0, y0).RGBA()
-			r01, g01, b01, a01 := src.At(x0, y1).RGBA()
-			r10, g10, b10, a10 := src.At(x1, y0).RGBA()
-			r11, g11, b11, a11 := src.At(x1, y1).RGBA()
-
-			// 雙線性插值
-			r := uint8((float64(r00)*(1-xFrac)*(1-yFrac) +
-				float64(r10)*xFrac*(1-yFrac) +
-				float64(r01)*(1-xFrac)*yFrac +
-				float64(r11)*xFrac*yFrac) / 256)
-			g := uint8((float64(g00)*(1-xFrac)*(1-yFrac) +
-				float64(g10)*xFrac*(1-yFrac) +
-				float64(g01)*(1-xFrac)*yFrac +
-				float64(g11)*xFrac*yFrac) / 256)
-			b := uint8((float64(b00)*(1-xFrac)*(1-yFrac) +
-				float64(b10)*xFrac*(1-yFrac) +
-				float64(b01)*(1-xFrac)*yFrac +
-				float64(b11)*xFrac*yFrac) / 256)
-			a := uint8((float64(a00)*(1-xFrac)*(1-yFrac) +
-				float64(a10)*xFrac*(1-yFrac) +
-				float64(a01)*(1-xFrac)*yFrac +
-				float64(a11)*xFrac*yFrac) / 256)
-
-			dst.Set(x, y, color.RGBA{r, g, b, a})
-		}
-	}
-
-	return dst
+// PreprocessImage 以手寫板相同的方式（淺色背景、深色筆跡）把任意圖片依 options 轉成模型輸入
+// 彩色模型則保留 R、G、B 直接縮放整張圖片，回傳預處理後的圖片與輸入矩陣
+func PreprocessImage(img image.Image, shape nn.ImageShape, options preprocess.Options) (*image.RGBA, *mat.Dense) {
+	// 透明的部分視為白色背景
+	rgba := image.NewRGBA(img.Bounds())
+	draw.Draw(rgba, rgba.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Over)
+	preprocessed, _ := preprocess.Process(rgba, shape, options)
+	return preprocessed, preprocess.Input(preprocessed, shape)
 }
 
-// preprocessForMNIST 將手寫圖片預處理成 MNIST 格式（居中 + 縮放到 20x20 並放在 28x28 中心）
-func preprocessForMNIST(src *image.RGBA) *image.RGBA {
-	// 1. 找到數字的邊界框
-	minX, minY, maxX, maxY, found := findBoundingBox(src)
-	if !found {
-		// 沒有找到任何筆跡，返回空白圖片
-		return image.NewRGBA(image.Rect(0, 0, 28, 28))
-	}
-
-	// 2. 裁剪出數字區域
-	digitWidth := maxX - minX + 1
-	digitHeight := maxY - minY + 1
-
-	cropped := image.NewRGBA(image.Rect(0, 0, digitWidth, digitHeight))
-	for y := 0; y < digitHeight; y++ {
-		for x := 0; x < digitWidth; x++ {
-			cropped.Set(x, y, src.At(minX+x, minY+y))
-		}
-	}
-
-	// 3. 計算縮放比例，保持長寬比，放入 20x20 區域
-	targetSize := 20.0
-	scale := targetSize / float64(max(digitWidth, digitHeight))
-
-	newWidth := int(float64(digitWidth) * scale)
-	newHeight := int(float64(digitHeight) * scale)
-
-	if newWidth < 1 {
-		newWidth = 1
-	}
-	if newHeight < 1 {
-		newHeight = 1
-	}
-
-	// 4. 縮放數字
-	resized := resizeBilinear(cropped, newWidth, newHeight)
-
-	// 5. 創建 28x28 白色背景圖片，將數字放在中心
-	result := image.NewRGBA(image.Rect(0, 0, 28, 28))
-	// 初始化為白色
-	for y := 0; y < 28; y++ {
-		for x := 0; x < 28; x++ {
-			result.Set(x, y, color.White)
-		}
-	}
-
-	// 計算偏移量使數字居中
-	offsetX := (28 - newWidth) / 2
-	offsetY := (28 - newHeight) / 2
-
-	// 複製縮放後的數字到中心
-	for y := 0; y < newHeight; y++ {
-		for x := 0; x < newWidth; x++ {
-			result.Set(offsetX+x, offsetY+y, resized.At(x, y))
-		}
-	}
-
-	return result
-}
-
-func max(a, b int) int {
-	if a > b {
-		return a
-	}
-	return b
-}
-
-// imageToGrayscaleMatrix 將圖片轉為灰階並標準化到 0-1，返回 784x1 矩陣
-func imageToGrayscaleMatrix(img *image.RGBA) *mat.Dense {
-	bounds := img.Bounds()
-	width := bounds.Dx()
-	height := bounds.Dy()
-	
-	// 創建 784x1 的矩陣（28*28 = 784）
-	data := make([]float64, width*height)
-	
-	for y := 0; y < height; y++ {
-		for x := 0; x < width; x++ {
-			// 獲取像素
-			r, g, b, _ := img.At(x, y).RGBA()
-			
-			// 轉為灰階（0-65535 範圍）
-			// 白色背景(255,255,255) -> 65535，黑色筆跡(0,0,0) -> 0
-			gray := (r + g + b) / 3
-			
-			// 反轉：MNIST 是黑底白字，user畫的是白底黑字
-			// 標準化到 0-1：黑色筆跡 -> 1.0，白色背景 -> 0.0
-			normalized := 1.0 - float64(gray)/65535.0
-			
-			// 存入矩陣（按行優先順序）
-			data[y*width+x] = normalized
-		}
-	}
-	
-	return mat.NewDense(width*height, 1, data)
-}
-
-
 // ShowDrawingBoard 顯示手寫板界面
 func ShowDrawingBoard() {
 	model, err := nn.LoadModel("/Users/user/github-projects/golang-neural-network/models/basic.json")
@@ -368,37 +211,53 @@ func ShowDrawingBoardWithModel(model *nn.NeuralNetwork) {
 	drawingCanvas := NewDrawingCanvas(560, 560)
 	
 	// 結果標籤
-	resultLabel := widget.NewLabel("Draw a digit (0-9) and click Predict")
-	
-	// 清除按鈕
-	clearBtn := widget.NewButton("Clear", func() {
-		drawingCanvas.Clear()
-		resultLabel.SetText("Draw a digit (0-9) and click Predict")
-	})
-	
-	// 預測按鈕
-	predictBtn := widget.NewButton("Predict", func() {
-		if model == nil {
-			resultLabel.SetText("Error: No model loaded")
+	resultLabel := widget.NewLabel("Draw a digit (0-9)")
+
+	// 所有類別的機率
+	probabilities := newProbabilityPanel(model)
+
+	// 預測類別的 heatmap
+	explain := newExplainPanel(model)
+
+	// 實際送進模型的輸入
+	preview := newPreviewPanel()
+
+	// 預處理的步驟與存下手寫圖片
+	var pipeline *pipelinePanel
+
+	// 預測：畫布改變後延遲執行，連續畫線時只在停筆後預測一次
+	predict := func() {
+		if drawingCanvas.IsBlank() {
+			resultLabel.SetText("Draw a digit (0-9)")
+			preview.clear()
+			probabilities.clear()
+			explain.clear()
 			return
 		}
 
-		// 1. 預處理：找到數字邊界、居中、縮放到 20x20 並放在 28x28 中心
-		preprocessed := preprocessForMNIST(drawingCanvas.GetImage())
+		// 1. 預處理：找到數字邊界、縮放並依選擇的方式置中（沒有模型時使用 MNIST 大小）
+		shape := nn.MNISTShape
+		if model != nil {
+			shape = model.ImageShape()
+		}
+		preprocessed, info := preprocess.Process(drawingCanvas.GetImage(), shape, pipeline.options())
+
+		// 2. 轉為模型需要的通道格式並標準化（灰階 784x1 或 RGB 3072x1）
+		inputMatrix := preprocess.Input(preprocessed, shape)
+		preview.update(inputMatrix, shape, info)
 
-		// 2. 轉為灰階並標準化（784x1 矩陣）
-		inputMatrix := imageToGrayscaleMatrix(preprocessed)
+		if model == nil {
+			resultLabel.SetText("Error: No model loaded")
+			return
+		}
 		
-		// 3. 模型預測
-		logits, err := model.Forward(inputMatrix)
+		// 3. 模型預測，套用輸出層激活函數獲得概率分佈
+		probs, err := model.Predict(inputMatrix)
 		if err != nil {
 			resultLabel.SetText(fmt.Sprintf("Prediction Error: %v", err))
 			return
 		}
 		
-		// softmax 獲得概率分佈
-		probs := nn.Softmax(logits)
-		
 		// 5. argmax最大值索引 
 		prediction, err := nn.Argmax(probs)
 		if err != nil {
@@ -406,23 +265,38 @@ func ShowDrawingBoardWithModel(model *nn.NeuralNetwork) {
 			return
 		}
 		
-		// 顯示結果
-		maxValue := probs.At(prediction, 0)
-		confidence := maxValue * 100
-		resultLabel.SetText(fmt.Sprintf("Prediction: %d (Confidence: %.1f%%)", prediction, confidence))
+		// 顯示結果，不確定時同時列出候補類別
+		resultLabel.SetText(visualize.ProbabilitySummary(mat.Col(nil, 0, probs), probabilities.labels))
+		probabilities.update(probs)
+		explain.update(inputMatrix, prediction)
+	}
+	live := &debouncer{delay: predictDelay}
+	drawingCanvas.OnChanged = func() {
+		live.trigger(predict)
+	}
+	pipeline = newPipelinePanel(drawingCanvas, predict)
+	
+	// 清除按鈕
+	clearBtn := widget.NewButton("Clear", func() {
+		live.cancel()
+		drawingCanvas.Clear()
+		predict()
 	})
 	
 	// layout
-	buttons := container.NewHBox(clearBtn, predictBtn)
 	content := container.NewBorder(
 		nil,                    // top
-		container.NewVBox(buttons, resultLabel), // bottom
-		nil,                    // left
-		nil,                    // right
+		container.NewVBox(clearBtn, resultLabel), // bottom
+		container.NewVBox(preview.content(), pipeline.content()), // left
+		container.NewVBox(probabilities.content(), explain.content()), // right
 		drawingCanvas,          // center
 	)
 	
-	w.SetContent(content)
-	w.Resize(fyne.NewSize(600, 700))
+	tabs := container.NewAppTabs(
+		container.NewTabItem("Draw", content),
+		container.NewTabItem("Weights", weightsTab(model)),
+	)
+	w.SetContent(tabs)
+	w.Resize(fyne.NewSize(1100, 760))
 	w.ShowAndRun()
 }
\ No newline at end of file
@@ -0,0 +1,75 @@
+package drawing
+
+import (
+	"fmt"
+	"golang-neural-network/nn"
+	"golang-neural-network/visualize"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"gonum.org/v1/gonum/mat"
+)
+
+// explainOff 選單中不顯示解釋的選項
+const explainOff = "off"
+
+// explainPanel 把預測類別的 heatmap 疊在預處理後的模型輸入上
+type explainPanel struct {
+	model  *nn.NeuralNetwork
+	method *widget.Select
+	image  *canvas.Image
+	label  *widget.Label
+	input  *mat.Dense // 最近一次預測的輸入，切換方法時重新計算
+	class  int
+}
+
+func newExplainPanel(model *nn.NeuralNetwork) *explainPanel {
+	p := &explainPanel{
+		model: model,
+		image: canvas.NewImageFromImage(nil),
+		label: widget.NewLabel(""),
+	}
+	p.image.FillMode = canvas.ImageFillContain
+	p.image.ScaleMode = canvas.ImageScalePixels
+	p.image.SetMinSize(fyne.NewSize(168, 168))
+	p.method = widget.NewSelect(append([]string{explainOff}, visualize.Methods...), func(string) {
+		p.refresh()
+	})
+	p.method.SetSelected(explainOff)
+	return p
+}
+
+func (p *explainPanel) content() fyne.CanvasObject {
+	return container.NewVBox(widget.NewLabel("Explanation"), p.method, p.image, p.label)
+}
+
+// update 記住這次預測的輸入與類別並重新計算 heatmap
+func (p *explainPanel) update(input *mat.Dense, class int) {
+	p.input, p.class = input, class
+	p.refresh()
+}
+
+func (p *explainPanel) clear() {
+	p.input = nil
+	p.refresh()
+}
+
+func (p *explainPanel) refresh() {
+	method := p.method.Selected
+	if p.model == nil || p.input == nil || method == explainOff {
+		p.image.Image = nil
+		p.image.Refresh()
+		p.label.SetText("")
+		return
+	}
+	attribution, err := visualize.Explain(p.model, p.input, p.class, method)
+	if err != nil {
+		p.label.SetText(fmt.Sprintf("Error: %v", err))
+		return
+	}
+	p.image.Image = visualize.Overlay(mat.Col(nil, 0, p.input), attribution.Shape, attribution.Heatmap())
+	p.image.Refresh()
+	p.label.SetText(fmt.Sprintf("Pixels supporting %d", attribution.Class))
+}
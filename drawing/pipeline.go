@@ -0,0 +1,101 @@
+package drawing
+
+import (
+	"fmt"
+	"golang-neural-network/preprocess"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// drawingsDir 手寫圖片存放的目錄，每個標籤一個子目錄，可用 preprocess-eval 評估
+const drawingsDir = "drawings"
+
+// pipelinePanel 選擇預處理的步驟，並把目前的手寫圖片連同標籤存下來
+type pipelinePanel struct {
+	centering  *widget.Select
+	resampling *widget.Select
+	deskew     *widget.Check
+	stroke     *widget.Check
+	label      *widget.Entry
+	save       *widget.Button
+	status     *widget.Label
+}
+
+// newPipelinePanel 步驟改變時呼叫 changed 重新預測
+func newPipelinePanel(dc *DrawingCanvas, changed func()) *pipelinePanel {
+	p := &pipelinePanel{
+		status: widget.NewLabel(""),
+		label:  widget.NewEntry(),
+	}
+	// 先選好預設值再設定 OnChanged，避免建立時就觸發預測
+	onChange := func(string) { changed() }
+	p.centering = widget.NewSelect([]string{string(preprocess.CenterMass), string(preprocess.CenterBox)}, nil)
+	p.centering.SetSelected(string(preprocess.MNIST.Centering))
+	p.centering.OnChanged = onChange
+	p.resampling = widget.NewSelect([]string{string(preprocess.ResampleArea), string(preprocess.ResampleLanczos), string(preprocess.ResampleBilinear)}, nil)
+	p.resampling.SetSelected(string(preprocess.MNIST.Resampling))
+	p.resampling.OnChanged = onChange
+	p.deskew = widget.NewCheck("Deskew", func(bool) { changed() })
+	p.stroke = widget.NewCheck("Normalize stroke", func(bool) { changed() })
+
+	p.label.SetPlaceHolder("Label")
+	p.save = widget.NewButton("Save drawing", func() {
+		if dc.IsBlank() {
+			p.status.SetText("Nothing to save")
+			return
+		}
+		path, err := saveDrawing(dc, p.label.Text)
+		if err != nil {
+			p.status.SetText(fmt.Sprintf("Error: %v", err))
+			return
+		}
+		p.status.SetText("Saved " + path)
+	})
+	return p
+}
+
+func (p *pipelinePanel) content() fyne.CanvasObject {
+	return container.NewVBox(
+		widget.NewLabel("Preprocessing"),
+		container.NewHBox(widget.NewLabel("Centering"), p.centering),
+		container.NewHBox(widget.NewLabel("Resampling"), p.resampling),
+		p.deskew, p.stroke,
+		container.NewBorder(nil, nil, nil, p.save, p.label),
+		p.status,
+	)
+}
+
+// options 目前選擇的預處理步驟
+func (p *pipelinePanel) options() preprocess.Options {
+	return preprocess.Options{
+		Centering:  preprocess.Centering(p.centering.Selected),
+		Resampling: preprocess.Resampling(p.resampling.Selected),
+		Deskew:     p.deskew.Checked,
+		Stroke:     p.stroke.Checked,
+	}
+}
+
+// saveDrawing 把畫布存成 drawings/<label>/<時間>.png
+func saveDrawing(dc *DrawingCanvas, label string) (string, error) {
+	if _, err := strconv.Atoi(label); err != nil {
+		return "", fmt.Errorf("Label %q is not a class number", label)
+	}
+	dir := filepath.Join(drawingsDir, label)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%d.png", time.Now().UnixNano()))
+	file, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	return path, png.Encode(file, dc.GetImage())
+}
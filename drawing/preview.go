@@ -0,0 +1,120 @@
+package drawing
+
+import (
+	"fmt"
+	"golang-neural-network/chart"
+	"golang-neural-network/nn"
+	"golang-neural-network/preprocess"
+	"image"
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"gonum.org/v1/gonum/mat"
+)
+
+// previewScale 模型輸入預覽放大的倍數
+const previewScale = 6
+
+// 預覽上的標記：輸入的中心線，以及縮放後數字的邊界框與對齊到中心的點
+var (
+	centerColor = color.RGBA{R: 220, G: 40, B: 40, A: 255}
+	boxColor    = color.RGBA{R: 40, G: 200, B: 70, A: 255}
+)
+
+// previewPanel 放大顯示實際送進模型的輸入，可切換是否標出預處理套用的邊界框與置中
+type previewPanel struct {
+	image  *canvas.Image
+	guides *widget.Check
+	label  *widget.Label
+	input  []float64 // 最近一次的模型輸入，切換標記時重畫
+	shape  nn.ImageShape
+	info   preprocess.Info
+}
+
+func newPreviewPanel() *previewPanel {
+	p := &previewPanel{
+		image: canvas.NewImageFromImage(nil),
+		label: widget.NewLabel(""),
+	}
+	p.image.FillMode = canvas.ImageFillOriginal
+	p.image.ScaleMode = canvas.ImageScalePixels
+	p.guides = widget.NewCheck("Bounding box and centering", func(bool) {
+		p.refresh()
+	})
+	return p
+}
+
+func (p *previewPanel) content() fyne.CanvasObject {
+	return container.NewVBox(widget.NewLabel("Model input"), p.image, p.guides, p.label)
+}
+
+// update 記住這次的模型輸入與預處理資訊並重畫
+func (p *previewPanel) update(input *mat.Dense, shape nn.ImageShape, info preprocess.Info) {
+	p.input, p.shape, p.info = mat.Col(nil, 0, input), shape, info
+	p.refresh()
+}
+
+func (p *previewPanel) clear() {
+	p.input = nil
+	p.refresh()
+}
+
+func (p *previewPanel) refresh() {
+	if p.input == nil {
+		p.image.Image = nil
+		p.image.Refresh()
+		p.label.SetText("")
+		return
+	}
+	shape := p.shape
+	img := chart.Tile([]image.Image{chart.TensorImage(p.input, shape.Channels, shape.Height, shape.Width)}, 1, previewScale, 0)
+	p.label.SetText("")
+	if p.guides.Checked && p.info.Found {
+		drawGuides(img, p.info)
+		box, placed := p.info.Box, p.info.Placed
+		text := fmt.Sprintf("Box %dx%d at (%d,%d)\nscaled x%.3f to %dx%d at (%d,%d)",
+			box.Dx(), box.Dy(), box.Min.X, box.Min.Y, p.info.Scale, placed.Dx(), placed.Dy(), placed.Min.X, placed.Min.Y)
+		if p.info.Skew != 0 {
+			text += fmt.Sprintf("\ndeskewed by %.2f", p.info.Skew)
+		}
+		if p.info.Stroke != 0 {
+			text += fmt.Sprintf("\nstroke %.1f px normalized", p.info.Stroke)
+		}
+		p.label.SetText(text)
+	}
+	p.image.Image = img
+	p.image.SetMinSize(fyne.NewSize(float32(img.Bounds().Dx()), float32(img.Bounds().Dy())))
+	p.image.Refresh()
+}
+
+// drawGuides 在放大的預覽上畫出輸入的中心線、數字的邊界框與對齊到中心的點（邊界框中心或質心）
+func drawGuides(img *image.RGBA, info preprocess.Info) {
+	placed := info.Placed
+	bounds := img.Bounds()
+	cx, cy := bounds.Dx()/2, bounds.Dy()/2
+	for x := bounds.Min.X; x < bounds.Max.X; x++ {
+		img.Set(x, cy, centerColor)
+	}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		img.Set(cx, y, centerColor)
+	}
+
+	box := image.Rect(placed.Min.X*previewScale, placed.Min.Y*previewScale, placed.Max.X*previewScale-1, placed.Max.Y*previewScale-1)
+	for x := box.Min.X; x <= box.Max.X; x++ {
+		img.Set(x, box.Min.Y, boxColor)
+		img.Set(x, box.Max.Y, boxColor)
+	}
+	for y := box.Min.Y; y <= box.Max.Y; y++ {
+		img.Set(box.Min.X, y, boxColor)
+		img.Set(box.Max.X, y, boxColor)
+	}
+	// 這個點與輸入中心重合代表置中正確
+	bx, by := int(info.CenterX*previewScale), int(info.CenterY*previewScale)
+	for d := -previewScale; d <= previewScale; d++ {
+		img.Set(bx+d, by, boxColor)
+		img.Set(bx, by+d, boxColor)
+	}
+}
@@ -0,0 +1,85 @@
+package drawing
+
+import (
+	"golang-neural-network/chart"
+	"golang-neural-network/nn"
+	"golang-neural-network/visualize"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"gonum.org/v1/gonum/mat"
+)
+
+// 機率長條圖的大小（像素）與即時預測的延遲
+const (
+	probabilityWidth  = 320
+	probabilityHeight = 200
+	predictDelay      = 150 * time.Millisecond
+)
+
+// probabilityPanel 以長條圖顯示模型輸出的所有類別機率
+type probabilityPanel struct {
+	image  *canvas.Image
+	labels []string
+}
+
+func newProbabilityPanel(model *nn.NeuralNetwork) *probabilityPanel {
+	p := &probabilityPanel{image: canvas.NewImageFromImage(nil)}
+	if model != nil {
+		p.labels = modelLabels(model)
+	}
+	p.image.FillMode = canvas.ImageFillOriginal
+	p.image.SetMinSize(fyne.NewSize(probabilityWidth, probabilityHeight))
+	return p
+}
+
+func (p *probabilityPanel) content() fyne.CanvasObject {
+	return p.image
+}
+
+// update 以 Predict 的輸出（一個 column）重畫長條圖
+func (p *probabilityPanel) update(probs *mat.Dense) {
+	bars := visualize.ProbabilityChart(mat.Col(nil, 0, probs), p.labels)
+	p.image.Image = chart.Render(bars, probabilityWidth, probabilityHeight)
+	p.image.Refresh()
+}
+
+func (p *probabilityPanel) clear() {
+	p.image.Image = nil
+	p.image.Refresh()
+}
+
+// modelLabels CIFAR-10 模型使用類別名稱，其他模型使用數字
+func modelLabels(model *nn.NeuralNetwork) []string {
+	if model.ImageShape().Channels == 3 && model.OutputClass == len(nn.CIFAR10Labels) {
+		return nn.CIFAR10Labels
+	}
+	return nil
+}
+
+// debouncer 連續觸發時只在最後一次觸發 delay 之後執行一次，並在 UI goroutine 上執行
+type debouncer struct {
+	delay time.Duration
+	mu    sync.Mutex
+	timer *time.Timer
+}
+
+func (d *debouncer) trigger(f func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.timer = time.AfterFunc(d.delay, func() { fyne.Do(f) })
+}
+
+func (d *debouncer) cancel() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+}
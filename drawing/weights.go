@@ -0,0 +1,42 @@
+package drawing
+
+import (
+	"fmt"
+	"golang-neural-network/chart"
+	"golang-neural-network/nn"
+	"golang-neural-network/visualize"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// histogramWidth 權重直方圖的寬度（像素）
+const histogramWidth = 560
+
+// weightsTab 顯示第一層特徵網格與每一層權重、bias 直方圖的分頁內容
+func weightsTab(model *nn.NeuralNetwork) fyne.CanvasObject {
+	if model == nil {
+		return widget.NewLabel("No model loaded")
+	}
+	items := container.NewVBox()
+
+	grid, err := visualize.FeatureGrid(model, 2)
+	if err != nil {
+		items.Add(widget.NewLabel(fmt.Sprintf("No feature images: %v", err)))
+	} else {
+		items.Add(widget.NewLabel(fmt.Sprintf("First-layer features (%d units, gray = 0, white = positive, black = negative)", model.Parameters()[0].Rows)))
+		features := canvas.NewImageFromImage(grid)
+		features.FillMode = canvas.ImageFillOriginal
+		features.ScaleMode = canvas.ImageScalePixels
+		items.Add(container.NewCenter(features))
+	}
+
+	height := visualize.HistogramHeight(model)
+	histograms := canvas.NewImageFromImage(chart.Render(visualize.WeightHistograms(model), histogramWidth, height))
+	histograms.FillMode = canvas.ImageFillOriginal
+	items.Add(container.NewCenter(histograms))
+
+	return container.NewVScroll(items)
+}
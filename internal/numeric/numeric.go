@@ -0,0 +1,30 @@
+// Package numeric 放 nn 與 autodiff 共用的數值運算，兩者的 softmax 與 log-softmax 因此得到相同的結果
+package numeric
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// ColumnMax 回傳第 j 個 column 的最大值
+func ColumnMax(m *mat.Dense, j int) float64 {
+	r, _ := m.Dims()
+	max := m.At(0, j)
+	for i := 1; i < r; i++ {
+		max = math.Max(max, m.At(i, j))
+	}
+	return max
+}
+
+// ColumnLogSumExp 回傳第 j 個 column 的最大值與 log Σ exp(x_i - max)
+// 呼叫端以 (x_i - max) - logSum 計算 log-softmax，先減 max 再減 log(sum)，logits 很大時也不會把 log(sum) 捨入掉
+func ColumnLogSumExp(m *mat.Dense, j int) (float64, float64) {
+	r, _ := m.Dims()
+	max := ColumnMax(m, j)
+	sum := 0.0
+	for i := 0; i < r; i++ {
+		sum += math.Exp(m.At(i, j) - max)
+	}
+	return max, math.Log(sum)
+}
@@ -0,0 +1,29 @@
+package numeric
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestColumnLogSumExp(t *testing.T) {
+	m := mat.NewDense(3, 2, []float64{
+		1, 1000,
+		2, -1000,
+		3, 999,
+	})
+	max, logSum := ColumnLogSumExp(m, 0)
+	want := math.Log(math.Exp(1) + math.Exp(2) + math.Exp(3))
+	if max != 3 || math.Abs(max+logSum-want) > 1e-12 {
+		t.Errorf("column 0: max %v, logSum %v, want total %v", max, logSum, want)
+	}
+	// 直接計算 exp(1000) 會溢位
+	max, logSum = ColumnLogSumExp(m, 1)
+	if max != 1000 || math.Abs(logSum-math.Log1p(math.Exp(-1))) > 1e-12 {
+		t.Errorf("column 1: max %v, logSum %v", max, logSum)
+	}
+	if got := ColumnMax(m, 1); got != 1000 {
+		t.Errorf("ColumnMax = %v, want 1000", got)
+	}
+}
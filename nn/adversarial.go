@@ -0,0 +1,74 @@
+package nn
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// AdversarialMode 對抗樣本放進 mini-batch 的方式
+type AdversarialMode string
+
+const (
+	AdversarialReplace AdversarialMode = "replace" // 把 batch 中 Ratio 比例的樣本換成它們的對抗樣本，batch 大小不變
+	AdversarialMix     AdversarialMode = "mix"     // 保留整個乾淨的 batch，另外加入 Ratio 比例樣本的對抗樣本
+)
+
+// AdversarialConfig 對抗訓練：每個 mini-batch 以更新前的權重即時產生對抗樣本
+// 訓練時記錄在模型上（NeuralNetwork.Adversarial），儲存模型時一併寫入
+type AdversarialConfig struct {
+	Attack AttackConfig    `json:"attack"`
+	Ratio  float64         `json:"ratio"` // 0 < Ratio <= 1
+	Mode   AdversarialMode `json:"mode"`  // 空值為 replace
+}
+
+// Validate 檢查設定並補上預設值
+func (c *AdversarialConfig) Validate() error {
+	if c.Ratio <= 0 || c.Ratio > 1 {
+		return fmt.Errorf("Adversarial ratio must be in (0, 1], got %g", c.Ratio)
+	}
+	if c.Mode == "" {
+		c.Mode = AdversarialReplace
+	}
+	if c.Mode != AdversarialReplace && c.Mode != AdversarialMix {
+		return fmt.Errorf("Unknown adversarial mode %q, use replace or mix", c.Mode)
+	}
+	return c.Attack.Validate()
+}
+
+func (c AdversarialConfig) String() string {
+	return fmt.Sprintf("%s, %s %.0f%% of every batch", c.Attack, c.Mode, c.Ratio*100)
+}
+
+// adversarialBatch 依設定把 batch 開頭 Ratio 比例的樣本（batch 已經打亂）換成或加上對抗樣本
+// float32 訓練時先把目前的權重寫回 float64 矩陣，攻擊才會使用最新的權重
+func (nn *NeuralNetwork) adversarialBatch(batch []TrainingData, config AdversarialConfig, rng *rand.Rand) ([]TrainingData, error) {
+	n := min(max(int(math.Round(config.Ratio*float64(len(batch)))), 1), len(batch))
+	nn.syncFromFloat32()
+	adversarial, err := AdversarialExamples(nn, batch[:n], config.Attack, rng)
+	if err != nil {
+		return nil, err
+	}
+	if config.Mode == AdversarialMix {
+		return append(batch, adversarial...), nil
+	}
+	copy(batch, adversarial)
+	return batch, nil
+}
+
+// adversarialValidation 在對抗樣本上驗證，指標名稱加上 adversarial_ 前綴
+func (nn *NeuralNetwork) adversarialValidation(testset []TrainingData, config AdversarialConfig, rng *rand.Rand) (Metrics, error) {
+	adversarial, err := AdversarialExamples(nn, testset, config.Attack, rng)
+	if err != nil {
+		return nil, err
+	}
+	metrics, err := Evaluate(nn, adversarial)
+	if err != nil {
+		return nil, err
+	}
+	prefixed := Metrics{}
+	for name, value := range metrics {
+		prefixed["adversarial_"+name] = value
+	}
+	return prefixed, nil
+}
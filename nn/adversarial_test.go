@@ -0,0 +1,108 @@
+package nn
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestAdversarialBatch(t *testing.T) {
+	network, err := NewNeuralNetwork(12, 3, []int{8}, 0.05)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := syntheticDataset(3, 10, 12, 3)
+	attack := AttackConfig{Method: AttackFGSM, Epsilon: 0.1}
+
+	mixed, err := network.adversarialBatch(append([]TrainingData(nil), data...), AdversarialConfig{Attack: attack, Ratio: 0.3, Mode: AdversarialMix}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mixed) != 13 {
+		t.Fatalf("mix: batch of %d, want 10 clean + 3 adversarial", len(mixed))
+	}
+	for i := 0; i < 10; i++ {
+		if mixed[i].Input != data[i].Input {
+			t.Errorf("mix: clean sample %d replaced", i)
+		}
+	}
+
+	replaced, err := network.adversarialBatch(append([]TrainingData(nil), data...), AdversarialConfig{Attack: attack, Ratio: 0.3, Mode: AdversarialReplace}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replaced) != 10 {
+		t.Fatalf("replace: batch of %d", len(replaced))
+	}
+	for i := range replaced {
+		if changed := !mat.Equal(replaced[i].Input, data[i].Input); changed != (i < 3) {
+			t.Errorf("replace: sample %d changed = %v", i, changed)
+		}
+		if replaced[i].Target != data[i].Target {
+			t.Errorf("replace: sample %d target changed", i)
+		}
+	}
+}
+
+func TestAdversarialTraining(t *testing.T) {
+	data := syntheticDataset(4, 60, 12, 3)
+	for _, precision := range []Precision{Float64, Float32} {
+		network, err := NewNeuralNetwork(12, 3, []int{10}, 0.05)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := network.SetPrecision(precision); err != nil {
+			t.Fatal(err)
+		}
+		adversarial := &AdversarialConfig{Attack: AttackConfig{Method: AttackPGD, Epsilon: 0.05, Steps: 3, RandomStart: true}, Ratio: 0.5}
+		var last Metrics
+		config := TrainingConfig{Epochs: 2, BatchSize: 16, Seed: 9, Adversarial: adversarial, Callbacks: []Callback{metricsSpy{last: &last}}}
+		if err := TrainingLoop(context.Background(), network, config, data, data[:20]); err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range []string{"val_accuracy", "val_adversarial_accuracy", "val_adversarial_loss"} {
+			if _, ok := last[name]; !ok {
+				t.Errorf("%s: missing %s in %v", precision, name, last)
+			}
+		}
+		// 預設值補在模型記錄的設定上，呼叫端的設定不變
+		if network.Adversarial == nil || network.Adversarial.Mode != AdversarialReplace || network.Adversarial.Attack.Norm != NormLinf {
+			t.Errorf("%s: model metadata %+v", precision, network.Adversarial)
+		}
+		if adversarial.Mode != "" {
+			t.Errorf("%s: caller's config was modified", precision)
+		}
+
+		path := filepath.Join(t.TempDir(), "model.json")
+		if err := SaveModel(network, path); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := LoadModel(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if loaded.Adversarial == nil || *loaded.Adversarial != *network.Adversarial {
+			t.Errorf("%s: adversarial config not saved: %+v", precision, loaded.Adversarial)
+		}
+	}
+}
+
+func TestAdversarialTrainingRejectsInvalidConfig(t *testing.T) {
+	network, err := NewNeuralNetwork(12, 3, []int{10}, 0.05)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := syntheticDataset(5, 10, 12, 3)
+	for _, adversarial := range []AdversarialConfig{
+		{Attack: AttackConfig{Method: AttackFGSM, Epsilon: 0.1}, Ratio: 0},
+		{Attack: AttackConfig{Method: AttackFGSM, Epsilon: 0.1}, Ratio: 0.5, Mode: "interleave"},
+		{Attack: AttackConfig{Method: "cw", Epsilon: 0.1}, Ratio: 0.5},
+	} {
+		config := TrainingConfig{Epochs: 1, Adversarial: &adversarial}
+		if err := TrainingLoop(context.Background(), network, config, data, nil); err == nil {
+			t.Errorf("%+v: expected an error", adversarial)
+		}
+	}
+}
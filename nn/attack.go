@@ -0,0 +1,215 @@
+package nn
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// AttackMethod 產生對抗樣本的方法
+type AttackMethod string
+
+const (
+	AttackFGSM AttackMethod = "fgsm" // 沿 loss 梯度走一步，步長為 epsilon
+	AttackPGD  AttackMethod = "pgd"  // 多步梯度上升，每一步後投影回 epsilon 球內
+)
+
+// AttackNorm 限制擾動大小的範數
+type AttackNorm string
+
+const (
+	NormLinf AttackNorm = "linf" // 每個像素最多改變 epsilon
+	NormL2   AttackNorm = "l2"   // 擾動的 L2 長度最多為 epsilon
+)
+
+// attackBatch 每次計算輸入梯度的樣本數
+const attackBatch = 256
+
+// DefaultPGDSteps Steps 未設定時 PGD 的步數，robustness 與對抗訓練的旗標也以此為預設
+const DefaultPGDSteps = 10
+
+// AttackConfig 對抗樣本的設定，所有像素最後都限制在 [0, 1]
+type AttackConfig struct {
+	Method      AttackMethod `json:"method"`
+	Epsilon     float64      `json:"epsilon"`
+	Norm        AttackNorm   `json:"norm"`
+	Steps       int          `json:"steps,omitempty"`        // PGD 的步數
+	StepSize    float64      `json:"step_size,omitempty"`    // PGD 每一步的大小，0 時為 2.5 * Epsilon / Steps
+	RandomStart bool         `json:"random_start,omitempty"` // PGD 從 epsilon 球內的隨機點開始
+}
+
+// Validate 檢查設定並補上預設值：Norm 預設為 L-inf，PGD 預設 DefaultPGDSteps 步
+func (c *AttackConfig) Validate() error {
+	if c.Norm == "" {
+		c.Norm = NormLinf
+	}
+	if c.Norm != NormLinf && c.Norm != NormL2 {
+		return fmt.Errorf("Unknown attack norm %q, use linf or l2", c.Norm)
+	}
+	if c.Epsilon < 0 {
+		return fmt.Errorf("Attack epsilon must not be negative, got %g", c.Epsilon)
+	}
+	switch c.Method {
+	case AttackFGSM:
+		c.Steps, c.StepSize, c.RandomStart = 1, c.Epsilon, false
+	case AttackPGD:
+		if c.Steps <= 0 {
+			c.Steps = DefaultPGDSteps
+		}
+		if c.StepSize <= 0 {
+			c.StepSize = 2.5 * c.Epsilon / float64(c.Steps)
+		}
+	default:
+		return fmt.Errorf("Unknown attack method %q, use fgsm or pgd", c.Method)
+	}
+	return nil
+}
+
+func (c AttackConfig) String() string {
+	s := fmt.Sprintf("%s %s epsilon=%g", c.Method, c.Norm, c.Epsilon)
+	if c.Method == AttackPGD {
+		s += fmt.Sprintf(" steps=%d step=%g", c.Steps, c.StepSize)
+	}
+	return s
+}
+
+// FGSM fast gradient sign method：x + epsilon * sign(∇x loss)（L2 時為單位梯度方向）
+// inputs 與 targets 的每一個 column 為一個樣本
+func FGSM(model *NeuralNetwork, inputs, targets *mat.Dense, epsilon float64, norm AttackNorm) (*mat.Dense, error) {
+	return Attack(model, inputs, targets, AttackConfig{Method: AttackFGSM, Epsilon: epsilon, Norm: norm}, nil)
+}
+
+// PGD projected gradient descent：從 inputs（RandomStart 時加上隨機擾動）開始做 steps 步梯度上升，
+// 每一步後投影回 epsilon 球內並限制在 [0, 1]
+func PGD(model *NeuralNetwork, inputs, targets *mat.Dense, epsilon float64, steps int, norm AttackNorm, rng *rand.Rand) (*mat.Dense, error) {
+	config := AttackConfig{Method: AttackPGD, Epsilon: epsilon, Steps: steps, Norm: norm, RandomStart: rng != nil}
+	return Attack(model, inputs, targets, config, rng)
+}
+
+// Attack 依 config 產生使 loss 變大的對抗樣本，回傳新的矩陣，inputs 不會被修改
+// RandomStart 需要 rng
+func Attack(model *NeuralNetwork, inputs, targets *mat.Dense, config AttackConfig, rng *rand.Rand) (*mat.Dense, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	r, c := inputs.Dims()
+	if tr, tc := targets.Dims(); tr != model.OutputClass || tc != c {
+		return nil, fmt.Errorf("Targets are %dx%d, expected %dx%d", tr, tc, model.OutputClass, c)
+	}
+	adversarial := mat.DenseCopyOf(inputs)
+	if config.Epsilon == 0 {
+		return adversarial, nil
+	}
+	if config.RandomStart {
+		if rng == nil {
+			return nil, fmt.Errorf("Random start needs a random source")
+		}
+		noise := mat.NewDense(r, c, nil)
+		for i := 0; i < r; i++ {
+			for j := 0; j < c; j++ {
+				noise.Set(i, j, rng.NormFloat64())
+			}
+		}
+		if config.Norm == NormLinf {
+			noise.Apply(func(_, _ int, v float64) float64 { return math.Copysign(rng.Float64()*config.Epsilon, v) }, noise)
+		} else {
+			normalizeColumns(noise, config.Epsilon)
+		}
+		adversarial.Add(adversarial, noise)
+		project(adversarial, inputs, config)
+	}
+
+	lossGrad := mat.NewDense(model.OutputClass, c, nil)
+	var lossErr error
+	for step := 0; step < config.Steps; step++ {
+		_, grad, err := model.InputGradient(adversarial, func(logits *mat.Dense) *mat.Dense {
+			_, lossErr = model.lossInto(logits, targets, lossGrad)
+			return lossGrad
+		})
+		if lossErr != nil {
+			return nil, lossErr
+		}
+		if err != nil {
+			return nil, err
+		}
+		if config.Norm == NormLinf {
+			grad.Apply(func(_, _ int, v float64) float64 { return sign(v) * config.StepSize }, grad)
+		} else {
+			normalizeColumns(grad, config.StepSize)
+		}
+		adversarial.Add(adversarial, grad)
+		project(adversarial, inputs, config)
+	}
+	return adversarial, nil
+}
+
+func sign(v float64) float64 {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	}
+	return 0
+}
+
+// normalizeColumns 把每一個 column 縮放成 L2 長度 length，長度為 0 的 column 不變
+func normalizeColumns(m *mat.Dense, length float64) {
+	r, c := m.Dims()
+	for j := 0; j < c; j++ {
+		norm := mat.Norm(m.ColView(j), 2)
+		if norm == 0 {
+			continue
+		}
+		for i := 0; i < r; i++ {
+			m.Set(i, j, m.At(i, j)/norm*length)
+		}
+	}
+}
+
+// project 把 adversarial - original 限制在 epsilon 球內，再把像素限制在 [0, 1]
+func project(adversarial, original *mat.Dense, config AttackConfig) {
+	r, c := adversarial.Dims()
+	delta := mat.NewDense(r, c, nil)
+	delta.Sub(adversarial, original)
+	if config.Norm == NormLinf {
+		delta.Apply(func(_, _ int, v float64) float64 { return math.Max(-config.Epsilon, math.Min(config.Epsilon, v)) }, delta)
+	} else {
+		for j := 0; j < c; j++ {
+			if norm := mat.Norm(delta.ColView(j), 2); norm > config.Epsilon {
+				for i := 0; i < r; i++ {
+					delta.Set(i, j, delta.At(i, j)/norm*config.Epsilon)
+				}
+			}
+		}
+	}
+	adversarial.Add(original, delta)
+	adversarial.Apply(func(_, _ int, v float64) float64 { return math.Max(0, math.Min(1, v)) }, adversarial)
+}
+
+// AdversarialExamples 對 data 的每一個樣本產生對抗樣本，每 256 個樣本一起計算，target 不變
+func AdversarialExamples(model *NeuralNetwork, data []TrainingData, config AttackConfig, rng *rand.Rand) ([]TrainingData, error) {
+	result := make([]TrainingData, 0, len(data))
+	for start := 0; start < len(data); start += attackBatch {
+		batch := data[start:min(start+attackBatch, len(data))]
+		inputs := mat.NewDense(model.Inputs, len(batch), nil)
+		targets := mat.NewDense(model.OutputClass, len(batch), nil)
+		for j, sample := range batch {
+			if r, c := sample.Input.Dims(); r != model.Inputs || c != 1 {
+				return nil, fmt.Errorf("Input is %dx%d, model expects %dx1", r, c, model.Inputs)
+			}
+			inputs.SetCol(j, columnData(sample.Input))
+			targets.SetCol(j, columnData(sample.Target))
+		}
+		adversarial, err := Attack(model, inputs, targets, config, rng)
+		if err != nil {
+			return nil, err
+		}
+		for j, sample := range batch {
+			result = append(result, TrainingData{Input: mat.NewDense(model.Inputs, 1, mat.Col(nil, j, adversarial)), Target: sample.Target})
+		}
+	}
+	return result, nil
+}
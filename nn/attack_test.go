@@ -0,0 +1,151 @@
+package nn
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// attackProblem 隨機網路與像素介於 0.2 到 0.8 的輸入，小的擾動不會被 [0, 1] 截斷
+func attackProblem(t *testing.T, n int) (*NeuralNetwork, *mat.Dense, *mat.Dense) {
+	t.Helper()
+	model, err := NewNeuralNetwork(16, 3, []int{12}, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rng := rand.New(rand.NewSource(7))
+	inputs := mat.NewDense(16, n, nil)
+	targets := mat.NewDense(3, n, nil)
+	for j := 0; j < n; j++ {
+		for i := 0; i < 16; i++ {
+			inputs.Set(i, j, 0.2+0.6*rng.Float64())
+		}
+		targets.Set(rng.Intn(3), j, 1)
+	}
+	return model, inputs, targets
+}
+
+func batchLoss(t *testing.T, model *NeuralNetwork, inputs, targets *mat.Dense) float64 {
+	t.Helper()
+	logits, err := model.Forward(inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loss, _, err := model.lossAndGradient(logits, targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loss
+}
+
+func TestFGSMFollowsGradientSign(t *testing.T) {
+	model, inputs, targets := attackProblem(t, 4)
+	const epsilon = 0.05
+	adversarial, err := FGSM(model, inputs, targets, epsilon, NormLinf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, grad, err := model.InputGradient(inputs, func(logits *mat.Dense) *mat.Dense {
+		_, g, _ := model.lossAndGradient(logits, targets)
+		return g
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 16; i++ {
+		for j := 0; j < 4; j++ {
+			want := inputs.At(i, j) + epsilon*sign(grad.At(i, j))
+			if math.Abs(adversarial.At(i, j)-want) > 1e-12 {
+				t.Fatalf("(%d, %d): %v, want %v", i, j, adversarial.At(i, j), want)
+			}
+		}
+	}
+	if before, after := batchLoss(t, model, inputs, targets), batchLoss(t, model, adversarial, targets); after <= before {
+		t.Errorf("loss did not increase: %v -> %v", before, after)
+	}
+}
+
+func TestAttacksStayInBounds(t *testing.T) {
+	model, inputs, targets := attackProblem(t, 5)
+	// 讓部分像素貼近邊界
+	inputs.Set(0, 0, 0)
+	inputs.Set(1, 0, 1)
+	configs := []AttackConfig{
+		{Method: AttackFGSM, Epsilon: 0.3, Norm: NormLinf},
+		{Method: AttackFGSM, Epsilon: 1, Norm: NormL2},
+		{Method: AttackPGD, Epsilon: 0.1, Norm: NormLinf, Steps: 7, RandomStart: true},
+		{Method: AttackPGD, Epsilon: 0.5, Norm: NormL2, Steps: 7, RandomStart: true},
+	}
+	for _, config := range configs {
+		adversarial, err := Attack(model, inputs, targets, config, rand.New(rand.NewSource(1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		delta := mat.NewDense(16, 5, nil)
+		delta.Sub(adversarial, inputs)
+		for j := 0; j < 5; j++ {
+			column := delta.ColView(j)
+			size := mat.Norm(column, math.Inf(1))
+			if config.Norm == NormL2 {
+				size = mat.Norm(column, 2)
+			}
+			if size > config.Epsilon+1e-9 {
+				t.Errorf("%v: sample %d moved %v", config, j, size)
+			}
+		}
+		if mat.Min(adversarial) < 0 || mat.Max(adversarial) > 1 {
+			t.Errorf("%v: pixels outside [0, 1]", config)
+		}
+		if before, after := batchLoss(t, model, inputs, targets), batchLoss(t, model, adversarial, targets); after <= before {
+			t.Errorf("%v: loss did not increase: %v -> %v", config, before, after)
+		}
+	}
+}
+
+func TestAttackConfigValidate(t *testing.T) {
+	config := AttackConfig{Method: AttackPGD, Epsilon: 0.1}
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if config.Norm != NormLinf || config.Steps != 10 || math.Abs(config.StepSize-0.025) > 1e-12 {
+		t.Errorf("defaults not applied: %+v", config)
+	}
+	for _, bad := range []AttackConfig{
+		{Method: "deepfool", Epsilon: 0.1},
+		{Method: AttackFGSM, Epsilon: 0.1, Norm: "l1"},
+		{Method: AttackFGSM, Epsilon: -1},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("%+v: expected an error", bad)
+		}
+	}
+}
+
+func TestAdversarialExamplesBatches(t *testing.T) {
+	model, inputs, targets := attackProblem(t, 300)
+	var data []TrainingData
+	for j := 0; j < 300; j++ {
+		data = append(data, TrainingData{
+			Input:  mat.NewDense(16, 1, mat.Col(nil, j, inputs)),
+			Target: mat.NewDense(3, 1, mat.Col(nil, j, targets)),
+		})
+	}
+	config := AttackConfig{Method: AttackFGSM, Epsilon: 0.05}
+	examples, err := AdversarialExamples(model, data, config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(examples) != 300 {
+		t.Fatalf("got %d examples", len(examples))
+	}
+	// 第二個 batch 的樣本與單獨攻擊的結果相同
+	single, err := FGSM(model, data[299].Input, data[299].Target, 0.05, NormLinf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mat.EqualApprox(single, examples[299].Input, 1e-12) {
+		t.Error("batched and single-sample attacks differ")
+	}
+}
@@ -0,0 +1,58 @@
+package nn
+
+import (
+	"testing"
+)
+
+// 與 models/basic.json 相同的架構：784-128-64-10
+func benchmarkNetwork(b *testing.B) (*NeuralNetwork, []TrainingData) {
+	b.Helper()
+	network, err := NewNeuralNetwork(784, 10, []int{128, 64}, 0.01)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return network, syntheticDataset(1, 64, 784, 10)
+}
+
+func BenchmarkForward(b *testing.B) {
+	network, data := benchmarkNetwork(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := network.Forward(data[i%len(data)].Input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// autodiff 為 true 時以 Graph 計算梯度（TrainingConfig.Autodiff），否則為預設、不配置記憶體的手寫 backward
+func benchmarkTrainStep(b *testing.B, batchSize, workers int, autodiff bool) {
+	network, data := benchmarkNetwork(b)
+	trainer := newParallelTrainer(network, workers, batchSize)
+	trainer.autodiff = autodiff
+	defer trainer.close()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		start := (i * batchSize) % len(data)
+		if _, err := trainer.step(data[start : start+batchSize]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTrainStepSGD(b *testing.B)             { benchmarkTrainStep(b, 1, 1, false) }
+func BenchmarkTrainStepBatch32(b *testing.B)         { benchmarkTrainStep(b, 32, 1, false) }
+func BenchmarkTrainStepBatch32Workers4(b *testing.B) { benchmarkTrainStep(b, 32, 4, false) }
+func BenchmarkTrainStepBatch32Graph(b *testing.B)    { benchmarkTrainStep(b, 32, 1, true) }
+
+func BenchmarkValidate(b *testing.B) {
+	network, data := benchmarkNetwork(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Evaluate(network, data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package nn
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// TrainingState TrainingLoop 傳給 callback 的訓練狀態，同一次訓練中會重複使用同一個值
+type TrainingState struct {
+	Network       *NeuralNetwork
+	Config        TrainingConfig
+	ValidationSet []TrainingData
+
+	Epoch   int // 目前的 epoch，從 1 開始
+	Batch   int // 這個 epoch 中的第幾個 batch，從 1 開始
+	Batches int // 每個 epoch 的 batch 數
+	Step    int // 從訓練開始累計的 batch 數
+
+	BatchLoss     float64 // 最近一個 batch 的平均 loss
+	GradNorm      float64 // 最近一個 batch 平均梯度的 L2 norm（套用 mask 之前）
+	TrainLoss     float64 // 最近一個 epoch 的平均 training loss
+	TrainAccuracy float64 // 最近一個 epoch 訓練時的 accuracy（以更新前的權重預測，只有 softmax 分類模型）
+	Validation    Metrics // 最近一次驗證的指標，callback 可以在 OnValidationEnd 加入自訂指標
+
+	Interrupted bool // ctx 被取消，訓練提前結束
+
+	stop    bool
+	trainer *parallelTrainer
+}
+
+// StopTraining 要求在目前的 batch（或 epoch）結束後停止訓練，TrainingLoop 會正常回傳
+func (s *TrainingState) StopTraining() {
+	s.stop = true
+}
+
+// Stopped 是否已經有 callback 要求停止
+func (s *TrainingState) Stopped() bool {
+	return s.stop
+}
+
+// Metrics 合併 training loss、accuracy 與驗證指標，驗證指標加上 "val_" 前綴
+// 例如 {"train_loss": 0.21, "train_accuracy": 0.94, "val_loss": 0.25, "val_accuracy": 0.93}
+func (s *TrainingState) Metrics() Metrics {
+	metrics := Metrics{"train_loss": s.TrainLoss}
+	if s.Network.IsClassifier() {
+		metrics["train_accuracy"] = s.TrainAccuracy
+	}
+	for name, value := range s.Validation {
+		metrics["val_"+name] = value
+	}
+	return metrics
+}
+
+// Callback 訓練過程中的事件，回傳 error 會中止訓練並由 TrainingLoop 回傳
+// 只需要部分事件時，可以嵌入 BaseCallback
+type Callback interface {
+	OnTrainBegin(state *TrainingState) error
+	OnTrainEnd(state *TrainingState) error
+	OnEpochBegin(state *TrainingState) error
+	OnEpochEnd(state *TrainingState) error
+	OnBatchEnd(state *TrainingState) error
+	OnValidationEnd(state *TrainingState) error
+}
+
+// BaseCallback 所有事件都不做事，嵌入後只需實作需要的事件
+type BaseCallback struct{}
+
+func (BaseCallback) OnTrainBegin(*TrainingState) error    { return nil }
+func (BaseCallback) OnTrainEnd(*TrainingState) error      { return nil }
+func (BaseCallback) OnEpochBegin(*TrainingState) error    { return nil }
+func (BaseCallback) OnEpochEnd(*TrainingState) error      { return nil }
+func (BaseCallback) OnBatchEnd(*TrainingState) error      { return nil }
+func (BaseCallback) OnValidationEnd(*TrainingState) error { return nil }
+
+// callbackList 依序呼叫每個 callback，遇到錯誤就停止
+type callbackList []Callback
+
+func (l callbackList) each(event func(Callback) error) error {
+	for _, callback := range l {
+		if err := event(callback); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ProgressLogger 每個 epoch 結束時輸出 training loss 與驗證指標
+type ProgressLogger struct {
+	BaseCallback
+	Out io.Writer
+}
+
+func (p ProgressLogger) OnEpochEnd(state *TrainingState) error {
+	fmt.Fprintf(p.Out, "Epoch 【%d/%d】| Average training Loss on this epoch %.4f\n", state.Epoch, state.Config.Epochs, state.TrainLoss)
+	if state.Validation != nil {
+		fmt.Fprintf(p.Out, "Validation 【%d/%d】 | %s\n", state.Epoch, state.Config.Epochs, state.Validation)
+	}
+	return nil
+}
+
+// ProgressBar 在同一行更新目前 epoch 的進度與 batch loss，epoch 結束時換行
+type ProgressBar struct {
+	BaseCallback
+	Out   io.Writer
+	Width int // 進度條長度，<= 0 時為 30
+}
+
+func (p ProgressBar) OnBatchEnd(state *TrainingState) error {
+	width := p.Width
+	if width <= 0 {
+		width = 30
+	}
+	done := width * state.Batch / max(state.Batches, 1)
+	bar := strings.Repeat("=", done) + strings.Repeat(" ", width-done)
+	fmt.Fprintf(p.Out, "\rEpoch %d/%d [%s] %d/%d | loss %.4f", state.Epoch, state.Config.Epochs, bar, state.Batch, state.Batches, state.BatchLoss)
+	if state.Batch == state.Batches {
+		fmt.Fprintln(p.Out)
+	}
+	return nil
+}
+
+// OnEpochEnd epoch 提前結束（StopTraining）時也要換行
+func (p ProgressBar) OnEpochEnd(state *TrainingState) error {
+	if state.Batch != state.Batches {
+		fmt.Fprintln(p.Out)
+	}
+	return nil
+}
+
+// monitorValue 從 state.Metrics() 讀取要監控的指標
+func monitorValue(state *TrainingState, monitor string) (float64, error) {
+	value, ok := state.Metrics()[monitor]
+	if !ok {
+		return 0, fmt.Errorf("Metric %q is not available, got %s", monitor, state.Metrics())
+	}
+	return value, nil
+}
+
+// improved mode 為 "max" 時數值越大越好，其他情況數值越小越好
+func improved(mode string, value, best, minDelta float64) bool {
+	if mode == "max" {
+		return value > best+minDelta
+	}
+	return value < best-minDelta
+}
+
+func worstValue(mode string) float64 {
+	if mode == "max" {
+		return math.Inf(-1)
+	}
+	return math.Inf(1)
+}
+
+// EarlyStopping 監控的指標連續 Patience 個 epoch 沒有進步時停止訓練
+type EarlyStopping struct {
+	BaseCallback
+	Monitor     string  // 例如 "val_loss"、"val_accuracy"
+	Mode        string  // "min" 或 "max"
+	Patience    int     // 連續這麼多個 epoch 沒有進步就停止
+	MinDelta    float64 // 進步幅度小於 MinDelta 視為沒有進步
+	RestoreBest bool    // 停止時把權重還原為最佳 epoch 的權重
+	BestEpoch   int     // 訓練結束後為指標最佳的 epoch
+
+	best      float64
+	wait      int
+	bestModel *NeuralNetwork
+}
+
+func (e *EarlyStopping) OnTrainBegin(state *TrainingState) error {
+	e.best, e.wait, e.bestModel, e.BestEpoch = worstValue(e.Mode), 0, nil, 0
+	return nil
+}
+
+func (e *EarlyStopping) OnEpochEnd(state *TrainingState) error {
+	value, err := monitorValue(state, e.Monitor)
+	if err != nil {
+		return err
+	}
+	if improved(e.Mode, value, e.best, e.MinDelta) {
+		e.best, e.wait, e.BestEpoch = value, 0, state.Epoch
+		if e.RestoreBest {
+			e.bestModel = state.Network.Clone()
+		}
+		return nil
+	}
+	e.wait++
+	if e.wait >= e.Patience {
+		state.StopTraining()
+	}
+	return nil
+}
+
+func (e *EarlyStopping) OnTrainEnd(state *TrainingState) error {
+	if e.RestoreBest && e.bestModel != nil && e.BestEpoch != state.Epoch {
+		*state.Network = *e.bestModel.Clone()
+	}
+	return nil
+}
+
+// ModelCheckpoint 每個 epoch 結束時儲存模型
+// Path 可以包含 %d，會被替換成 epoch；SaveBestOnly 時只在 Monitor 進步時儲存
+type ModelCheckpoint struct {
+	BaseCallback
+	Path         string
+	Monitor      string
+	Mode         string
+	SaveBestOnly bool
+
+	best float64
+}
+
+func (c *ModelCheckpoint) OnTrainBegin(state *TrainingState) error {
+	c.best = worstValue(c.Mode)
+	return nil
+}
+
+func (c *ModelCheckpoint) OnEpochEnd(state *TrainingState) error {
+	if c.SaveBestOnly {
+		value, err := monitorValue(state, c.Monitor)
+		if err != nil {
+			return err
+		}
+		if !improved(c.Mode, value, c.best, 0) {
+			return nil
+		}
+		c.best = value
+	}
+	path := c.Path
+	if strings.Contains(path, "%d") {
+		path = fmt.Sprintf(path, state.Epoch)
+	}
+	if err := SaveModel(state.Network, path); err != nil {
+		return fmt.Errorf("Saving checkpoint: %w", err)
+	}
+	return nil
+}
+
+// MetricFunc 自訂驗證指標，在每次驗證結束後計算並加入 state.Validation
+type MetricFunc struct {
+	BaseCallback
+	Name string
+	Fn   func(nn *NeuralNetwork, validation []TrainingData) (float64, error)
+}
+
+func (m MetricFunc) OnValidationEnd(state *TrainingState) error {
+	value, err := m.Fn(state.Network, state.ValidationSet)
+	if err != nil {
+		return fmt.Errorf("Metric %s: %w", m.Name, err)
+	}
+	state.Validation[m.Name] = value
+	return nil
+}
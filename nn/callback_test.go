@@ -0,0 +1,260 @@
+package nn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// recorder 記錄所有事件，stopAt 不為 0 時在該 step 要求停止
+type recorder struct {
+	events []string
+	stopAt int
+}
+
+func (r *recorder) OnTrainBegin(s *TrainingState) error {
+	r.events = append(r.events, "train_begin")
+	return nil
+}
+func (r *recorder) OnTrainEnd(s *TrainingState) error {
+	r.events = append(r.events, "train_end")
+	return nil
+}
+func (r *recorder) OnEpochBegin(s *TrainingState) error {
+	r.events = append(r.events, fmt.Sprintf("epoch_begin %d", s.Epoch))
+	return nil
+}
+func (r *recorder) OnEpochEnd(s *TrainingState) error {
+	r.events = append(r.events, fmt.Sprintf("epoch_end %d", s.Epoch))
+	return nil
+}
+func (r *recorder) OnBatchEnd(s *TrainingState) error {
+	r.events = append(r.events, fmt.Sprintf("batch %d/%d", s.Batch, s.Batches))
+	if s.Step == r.stopAt {
+		s.StopTraining()
+	}
+	return nil
+}
+func (r *recorder) OnValidationEnd(s *TrainingState) error {
+	r.events = append(r.events, fmt.Sprintf("validation %d", s.Epoch))
+	return nil
+}
+
+func TestCallbackEvents(t *testing.T) {
+	data := syntheticDataset(13, 10, 4, 2)
+	network, err := NewNeuralNetwork(4, 2, []int{3}, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &recorder{}
+	if err := TrainingLoop(context.Background(), network, TrainingConfig{Epochs: 2, BatchSize: 4, Callbacks: []Callback{r}}, data, data[:2]); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"train_begin",
+		"epoch_begin 1", "batch 1/3", "batch 2/3", "batch 3/3", "validation 1", "epoch_end 1",
+		"epoch_begin 2", "batch 1/3", "batch 2/3", "batch 3/3", "validation 2", "epoch_end 2",
+		"train_end",
+	}
+	if strings.Join(r.events, ",") != strings.Join(want, ",") {
+		t.Errorf("events:\n%v\nwant:\n%v", r.events, want)
+	}
+
+	// 沒有驗證集時不呼叫 OnValidationEnd；StopTraining 在目前的 batch 後停止
+	r = &recorder{stopAt: 2}
+	if err := TrainingLoop(context.Background(), network, TrainingConfig{Epochs: 3, BatchSize: 4, Callbacks: []Callback{r}}, data, nil); err != nil {
+		t.Fatal(err)
+	}
+	want = []string{"train_begin", "epoch_begin 1", "batch 1/3", "batch 2/3", "epoch_end 1", "train_end"}
+	if strings.Join(r.events, ",") != strings.Join(want, ",") {
+		t.Errorf("events after StopTraining:\n%v\nwant:\n%v", r.events, want)
+	}
+}
+
+type failingCallback struct{ BaseCallback }
+
+func (failingCallback) OnEpochBegin(*TrainingState) error { return fmt.Errorf("disk full") }
+
+func TestCallbackErrorAbortsTraining(t *testing.T) {
+	data := syntheticDataset(14, 4, 4, 2)
+	network, err := NewNeuralNetwork(4, 2, []int{3}, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = TrainingLoop(context.Background(), network, TrainingConfig{Epochs: 1, Callbacks: []Callback{failingCallback{}}}, data, data)
+	if err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Errorf("TrainingLoop returned %v", err)
+	}
+}
+
+func TestTrainingLoopIsSilentWithoutCallbacks(t *testing.T) {
+	data := syntheticDataset(15, 8, 4, 2)
+	network, err := NewNeuralNetwork(4, 2, []int{3}, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	trainErr := TrainingLoop(context.Background(), network, TrainingConfig{Epochs: 2, BatchSize: 4}, data, data)
+	os.Stdout = stdout
+	writer.Close()
+	output, _ := io.ReadAll(reader)
+	if trainErr != nil {
+		t.Fatal(trainErr)
+	}
+	if len(output) != 0 {
+		t.Errorf("TrainingLoop wrote to stdout: %q", output)
+	}
+}
+
+func TestProgressLogger(t *testing.T) {
+	data := syntheticDataset(16, 8, 4, 2)
+	network, err := NewNeuralNetwork(4, 2, []int{3}, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	config := TrainingConfig{Epochs: 2, BatchSize: 4, Callbacks: []Callback{ProgressBar{Out: &out}, ProgressLogger{Out: &out}}}
+	if err := TrainingLoop(context.Background(), network, config, data, data); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Epoch 【2/2】| Average training Loss", "Validation 【1/2】 | accuracy:", "] 2/2 | loss"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output %q does not contain %q", out.String(), want)
+		}
+	}
+}
+
+// 每個 epoch 回傳固定的 val_loss，用來測試 EarlyStopping 與 ModelCheckpoint
+type scriptedLoss struct {
+	BaseCallback
+	losses []float64
+}
+
+func (s scriptedLoss) OnValidationEnd(state *TrainingState) error {
+	state.Validation["loss"] = s.losses[state.Epoch-1]
+	return nil
+}
+
+type metricsSpy struct {
+	BaseCallback
+	last *Metrics
+}
+
+func (m metricsSpy) OnEpochEnd(state *TrainingState) error {
+	*m.last = state.Metrics()
+	return nil
+}
+
+func TestEarlyStoppingAndCheckpoint(t *testing.T) {
+	data := syntheticDataset(17, 8, 4, 2)
+	network, err := NewNeuralNetwork(4, 2, []int{3}, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	early := &EarlyStopping{Monitor: "val_loss", Mode: "min", Patience: 2, RestoreBest: true}
+	checkpoint := &ModelCheckpoint{Path: filepath.Join(dir, "epoch%d.json"), Monitor: "val_loss", Mode: "min", SaveBestOnly: true}
+	custom := MetricFunc{Name: "constant", Fn: func(*NeuralNetwork, []TrainingData) (float64, error) { return 42, nil }}
+	config := TrainingConfig{
+		Epochs:    10,
+		BatchSize: 4,
+		Callbacks: []Callback{scriptedLoss{losses: []float64{0.9, 0.5, 0.6, 0.7, 0.4, 0.3}}, custom, early, checkpoint},
+	}
+	r := &recorder{}
+	var last Metrics
+	config.Callbacks = append(config.Callbacks, r, metricsSpy{last: &last})
+	if err := TrainingLoop(context.Background(), network, config, data, data); err != nil {
+		t.Fatal(err)
+	}
+	if last["val_constant"] != 42 || last["val_loss"] != 0.7 {
+		t.Errorf("metrics at the last epoch: %v", last)
+	}
+
+	// epoch 3、4 沒有比 epoch 2 好，第 4 個 epoch 結束後停止
+	if early.BestEpoch != 2 || r.events[len(r.events)-2] != "epoch_end 4" {
+		t.Errorf("best epoch %d, events %v", early.BestEpoch, r.events)
+	}
+	for epoch, want := range map[int]bool{1: true, 2: true, 3: false, 4: false} {
+		_, err := os.Stat(filepath.Join(dir, fmt.Sprintf("epoch%d.json", epoch)))
+		if (err == nil) != want {
+			t.Errorf("checkpoint for epoch %d exists: %v, want %v", epoch, err == nil, want)
+		}
+	}
+	saved, err := LoadModel(filepath.Join(dir, "epoch2.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// RestoreBest 之後的權重與 epoch 2 的 checkpoint 相同
+	input := data[0].Input
+	got, _ := network.Forward(input)
+	want, _ := saved.Forward(input)
+	if !mat.EqualApprox(got, want, 1e-12) {
+		t.Error("EarlyStopping did not restore the best weights")
+	}
+}
+
+// cancelAt 在指定的 step 結束後取消 context
+type cancelAt struct {
+	BaseCallback
+	step   int
+	cancel context.CancelFunc
+}
+
+func (c cancelAt) OnBatchEnd(state *TrainingState) error {
+	if state.Step == c.step {
+		c.cancel()
+	}
+	return nil
+}
+
+func TestTrainingLoopCancellation(t *testing.T) {
+	data := syntheticDataset(18, 12, 4, 2)
+	base, err := NewNeuralNetwork(4, 2, []int{3}, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 在第 2 個 batch 後取消，結果要和在同一個 batch 後 StopTraining 相同
+	cancelled := base.Clone()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := &recorder{}
+	config := TrainingConfig{Epochs: 3, BatchSize: 4, Callbacks: []Callback{cancelAt{step: 2, cancel: cancel}, r}}
+	err = TrainingLoop(ctx, cancelled, config, data, data)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("TrainingLoop returned %v, want context.Canceled", err)
+	}
+	want := []string{"train_begin", "epoch_begin 1", "batch 1/3", "batch 2/3", "train_end"}
+	if strings.Join(r.events, ",") != strings.Join(want, ",") {
+		t.Errorf("events:\n%v\nwant:\n%v", r.events, want)
+	}
+
+	stopped := base.Clone()
+	if err := TrainingLoop(context.Background(), stopped, TrainingConfig{Epochs: 3, BatchSize: 4, Callbacks: []Callback{&recorder{stopAt: 2}}}, data, data); err != nil {
+		t.Fatal(err)
+	}
+	if !mat.Equal(cancelled.OutputWeight, stopped.OutputWeight) {
+		t.Error("cancelled training did not finish exactly two steps")
+	}
+
+	// 已經取消的 context 不會執行任何一步
+	untouched := base.Clone()
+	if err := TrainingLoop(ctx, untouched, TrainingConfig{Epochs: 1}, data, data); !errors.Is(err, context.Canceled) {
+		t.Errorf("TrainingLoop with a cancelled context returned %v", err)
+	}
+	if !mat.Equal(untouched.OutputWeight, base.OutputWeight) {
+		t.Error("TrainingLoop with a cancelled context changed the weights")
+	}
+}
@@ -0,0 +1,73 @@
+package nn
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// CIFAR-10 binary 格式：每筆紀錄 = 1 byte label + 3072 bytes 像素
+// 像素依通道排列：前 1024 bytes 為 R，接著 G，最後 B，每個通道都是 32x32 row-major
+const (
+	cifarImageSize  = 32 * 32 * 3
+	cifarRecordSize = 1 + cifarImageSize
+	cifarClasses    = 10
+)
+
+// CIFAR10Labels CIFAR-10 各類別的名稱，依 label 排列
+var CIFAR10Labels = []string{"airplane", "automobile", "bird", "cat", "deer", "dog", "frog", "horse", "ship", "truck"}
+
+// ReadCIFAR10Batch 讀取一個 CIFAR-10 binary batch 檔（例如 data_batch_1.bin）
+// 回傳每張圖片的原始像素（3072 bytes）和對應標籤
+func ReadCIFAR10Batch(filename string) ([][]byte, []byte, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Can't open file %s", filename)
+	}
+	defer file.Close()
+
+	var images [][]byte
+	var labels []byte
+	record := make([]byte, cifarRecordSize)
+	for {
+		_, err := io.ReadFull(file, record)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read CIFAR-10 record %d: %v", len(labels), err)
+		}
+		if int(record[0]) >= cifarClasses {
+			return nil, nil, fmt.Errorf("invalid CIFAR-10 label %d in record %d", record[0], len(labels))
+		}
+		image := make([]byte, cifarImageSize)
+		copy(image, record[1:])
+		images = append(images, image)
+		labels = append(labels, record[0])
+	}
+	return images, labels, nil
+}
+
+// LoadingDataFromCIFAR10 讀取一個或多個 batch 檔並轉成 TrainingData
+// 輸入為 3072x1 矩陣（通道優先，3x32x32），像素標準化到 0-1
+func LoadingDataFromCIFAR10(filenames ...string) ([]TrainingData, error) {
+	var data []TrainingData
+	for _, filename := range filenames {
+		images, labels, err := ReadCIFAR10Batch(filename)
+		if err != nil {
+			return nil, err
+		}
+		for i, image := range images {
+			input := mat.NewDense(cifarImageSize, 1, nil)
+			for j, pixel := range image {
+				input.Set(j, 0, float64(pixel)/255.0)
+			}
+			target := mat.NewDense(cifarClasses, 1, nil)
+			target.Set(int(labels[i]), 0, 1.0)
+			data = append(data, TrainingData{Input: input, Target: target})
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,120 @@
+package nn
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// cifarRecord 一筆 CIFAR-10 紀錄：label 後接 R、G、B 三個通道，每個通道的像素都填 r、g、b
+func cifarRecord(label, r, g, b byte) []byte {
+	record := []byte{label}
+	for _, value := range []byte{r, g, b} {
+		for i := 0; i < cifarImageSize/3; i++ {
+			record = append(record, value)
+		}
+	}
+	return record
+}
+
+func writeCIFAR(t *testing.T, path string, records ...[]byte) {
+	t.Helper()
+	var data []byte
+	for _, record := range records {
+		data = append(data, record...)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCIFAR10Batch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data_batch_1.bin")
+	first := cifarRecord(3, 255, 0, 51)
+	first[1+5] = 102 // R 通道第 5 個像素
+	writeCIFAR(t, path, first, cifarRecord(9, 0, 255, 0))
+
+	images, labels, err := ReadCIFAR10Batch(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(labels) != string([]byte{3, 9}) {
+		t.Errorf("labels = %v, want [3 9]", labels)
+	}
+	if len(images) != 2 || len(images[0]) != cifarImageSize || string(images[0]) != string(first[1:]) {
+		t.Fatalf("read %d images, first image does not match the record", len(images))
+	}
+
+	data, err := LoadingDataFromCIFAR10(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 2 {
+		t.Fatalf("loaded %d samples, want 2", len(data))
+	}
+	if r, c := data[0].Input.Dims(); r != cifarImageSize || c != 1 {
+		t.Fatalf("input is %dx%d, want %dx1", r, c, cifarImageSize)
+	}
+	plane := cifarImageSize / 3
+	// 輸入依 R、G、B 通道排列，像素除以 255
+	for _, check := range []struct {
+		sample, index int
+		want          float64
+	}{
+		{0, 0, 1},
+		{0, 5, 102.0 / 255},
+		{0, plane, 0},
+		{0, 2 * plane, 0.2},
+		{0, 3*plane - 1, 0.2},
+		{1, 0, 0},
+		{1, plane + 7, 1},
+		{1, 2 * plane, 0},
+	} {
+		if got := data[check.sample].Input.At(check.index, 0); got != check.want {
+			t.Errorf("sample %d input[%d] = %v, want %v", check.sample, check.index, got, check.want)
+		}
+	}
+	for i, label := range []int{3, 9} {
+		if r, _ := data[i].Target.Dims(); r != cifarClasses {
+			t.Fatalf("target has %d rows, want %d", r, cifarClasses)
+		}
+		for class := 0; class < cifarClasses; class++ {
+			want := 0.0
+			if class == label {
+				want = 1
+			}
+			if got := data[i].Target.At(class, 0); got != want {
+				t.Errorf("sample %d target[%d] = %v, want %v", i, class, got, want)
+			}
+		}
+	}
+	if inputs, classes, _ := DatasetShape(data); inputs != cifarImageSize || classes != cifarClasses {
+		t.Errorf("dataset shape %d inputs, %d classes", inputs, classes)
+	}
+}
+
+func TestCIFAR10RejectsBadFiles(t *testing.T) {
+	dir := t.TempDir()
+	truncated := filepath.Join(dir, "truncated.bin")
+	writeCIFAR(t, truncated, cifarRecord(1, 0, 0, 0), cifarRecord(2, 0, 0, 0)[:100])
+	if _, _, err := ReadCIFAR10Batch(truncated); err == nil {
+		t.Error("ReadCIFAR10Batch accepted a truncated record")
+	}
+	if _, err := LoadingDataFromCIFAR10(truncated); err == nil {
+		t.Error("LoadingDataFromCIFAR10 accepted a truncated record")
+	}
+
+	badLabel := filepath.Join(dir, "label.bin")
+	writeCIFAR(t, badLabel, cifarRecord(1, 0, 0, 0), cifarRecord(10, 0, 0, 0))
+	if _, _, err := ReadCIFAR10Batch(badLabel); err == nil {
+		t.Error("ReadCIFAR10Batch accepted label 10")
+	}
+	valid := filepath.Join(dir, "valid.bin")
+	writeCIFAR(t, valid, cifarRecord(0, 0, 0, 0))
+	if _, err := LoadingDataFromCIFAR10(valid, badLabel); err == nil {
+		t.Error("LoadingDataFromCIFAR10 accepted label 10 in the second batch")
+	}
+	if _, err := LoadingDataFromCIFAR10(filepath.Join(dir, "missing.bin")); err == nil {
+		t.Error("LoadingDataFromCIFAR10 accepted a missing file")
+	}
+}
@@ -0,0 +1,165 @@
+package nn
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"golang-neural-network/autodiff"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// InputGradient 計算 objective 對輸入的梯度，input 的每一個 column 為一個樣本
+// outputGrad 依 forward 得到的 logits 回傳 objective 對 logits 的梯度（與 logits 同形狀）
+// 回傳 logits 與輸入梯度；使用 float64 的權重，與 Graph 相同
+func (nn *NeuralNetwork) InputGradient(input *mat.Dense, outputGrad func(logits *mat.Dense) *mat.Dense) (*mat.Dense, *mat.Dense, error) {
+	if r, _ := input.Dims(); r != nn.Inputs {
+		return nil, nil, fmt.Errorf("Input has %d rows, model expects %d", r, nn.Inputs)
+	}
+	tape := autodiff.NewTape()
+	x := tape.Variable(input)
+	logits, _ := nn.graph(tape, x, tape.Constant)
+	if err := tape.BackwardWithGrad(logits, outputGrad(logits.Value)); err != nil {
+		return nil, nil, err
+	}
+	if x.Grad == nil {
+		// 所有 ReLU 都沒有啟動時梯度不會傳到輸入
+		r, c := input.Dims()
+		return logits.Value, mat.NewDense(r, c, nil), nil
+	}
+	return logits.Value, x.Grad, nil
+}
+
+// Attribution 每一個輸入值對目標類別 logit 的貢獻
+type Attribution struct {
+	Class  int
+	Values []float64 // 與輸入同長度，通道優先（C x H x W）排列
+	Shape  ImageShape
+}
+
+// Heatmap 把各通道貢獻的絕對值相加並除以最大值，回傳 H x W 個介於 0 到 1 的值（row-major）
+func (a Attribution) Heatmap() []float64 {
+	plane := a.Shape.Height * a.Shape.Width
+	heat := make([]float64, plane)
+	for i, v := range a.Values {
+		heat[i%plane] += math.Abs(v)
+	}
+	peak := 0.0
+	for _, v := range heat {
+		peak = math.Max(peak, v)
+	}
+	if peak > 0 {
+		for i := range heat {
+			heat[i] /= peak
+		}
+	}
+	return heat
+}
+
+// classGradients 對 inputs 的每一個 column 計算 class logit 的輸入梯度，回傳各 column 梯度的平均
+func classGradients(model *NeuralNetwork, inputs *mat.Dense, class int) ([]float64, error) {
+	_, grad, err := model.InputGradient(inputs, func(logits *mat.Dense) *mat.Dense {
+		r, c := logits.Dims()
+		oneHot := mat.NewDense(r, c, nil)
+		for j := 0; j < c; j++ {
+			oneHot.Set(class, j, 1)
+		}
+		return oneHot
+	})
+	if err != nil {
+		return nil, err
+	}
+	r, c := grad.Dims()
+	mean := make([]float64, r)
+	for i := 0; i < r; i++ {
+		mean[i] = mat.Sum(grad.RowView(i)) / float64(c)
+	}
+	return mean, nil
+}
+
+// explainTarget 檢查輸入並決定要解釋的類別，class < 0 時使用模型預測的類別
+func explainTarget(model *NeuralNetwork, input *mat.Dense, class int) ([]float64, int, error) {
+	if r, c := input.Dims(); r != model.Inputs || c != 1 {
+		return nil, 0, fmt.Errorf("Input is %dx%d, model expects %dx1", r, c, model.Inputs)
+	}
+	if class >= model.OutputClass {
+		return nil, 0, fmt.Errorf("Class %d out of range, model has %d outputs", class, model.OutputClass)
+	}
+	if class < 0 {
+		logits, err := model.Forward(input)
+		if err != nil {
+			return nil, 0, err
+		}
+		class = argmax(columnData(logits))
+	}
+	return columnData(input), class, nil
+}
+
+// Saliency 目標類別 logit 對每一個輸入值的梯度（vanilla saliency），class < 0 時使用預測的類別
+func Saliency(model *NeuralNetwork, input *mat.Dense, class int) (Attribution, error) {
+	x, class, err := explainTarget(model, input, class)
+	if err != nil {
+		return Attribution{}, err
+	}
+	grad, err := classGradients(model, mat.NewDense(len(x), 1, x), class)
+	if err != nil {
+		return Attribution{}, err
+	}
+	return Attribution{Class: class, Values: grad, Shape: model.ImageShape()}, nil
+}
+
+// SmoothGrad 在輸入加上 samples 份標準差為 noise x (最大值 - 最小值) 的高斯雜訊，平均各份的梯度
+// 所有加上雜訊的輸入組成一個 batch 一起計算
+func SmoothGrad(model *NeuralNetwork, input *mat.Dense, class, samples int, noise float64, rng *rand.Rand) (Attribution, error) {
+	x, class, err := explainTarget(model, input, class)
+	if err != nil {
+		return Attribution{}, err
+	}
+	if samples < 1 {
+		return Attribution{}, fmt.Errorf("SmoothGrad needs at least 1 sample, got %d", samples)
+	}
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range x {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	sigma := noise * (hi - lo)
+	noisy := mat.NewDense(len(x), samples, nil)
+	for i, v := range x {
+		for j := 0; j < samples; j++ {
+			noisy.Set(i, j, v+rng.NormFloat64()*sigma)
+		}
+	}
+	grad, err := classGradients(model, noisy, class)
+	if err != nil {
+		return Attribution{}, err
+	}
+	return Attribution{Class: class, Values: grad, Shape: model.ImageShape()}, nil
+}
+
+// IntegratedGradients 從全黑（全 0）的 baseline 沿直線積分到輸入，以 steps 個中點近似
+// 貢獻總和約等於 logit(input) - logit(baseline)
+func IntegratedGradients(model *NeuralNetwork, input *mat.Dense, class, steps int) (Attribution, error) {
+	x, class, err := explainTarget(model, input, class)
+	if err != nil {
+		return Attribution{}, err
+	}
+	if steps < 1 {
+		return Attribution{}, fmt.Errorf("Integrated gradients needs at least 1 step, got %d", steps)
+	}
+	path := mat.NewDense(len(x), steps, nil)
+	for j := 0; j < steps; j++ {
+		alpha := (float64(j) + 0.5) / float64(steps)
+		for i, v := range x {
+			path.Set(i, j, alpha*v)
+		}
+	}
+	grad, err := classGradients(model, path, class)
+	if err != nil {
+		return Attribution{}, err
+	}
+	for i, v := range x {
+		grad[i] *= v
+	}
+	return Attribution{Class: class, Values: grad, Shape: model.ImageShape()}, nil
+}
@@ -0,0 +1,119 @@
+package nn
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func explainNetwork(t *testing.T) (*NeuralNetwork, *mat.Dense) {
+	t.Helper()
+	network, err := NewNeuralNetwork(16, 3, []int{8, 6}, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rng := rand.New(rand.NewSource(5))
+	for _, layer := range network.Hidden {
+		for i := range layer.bias.RawMatrix().Data {
+			layer.bias.RawMatrix().Data[i] = rng.Float64()*0.2 - 0.05
+		}
+	}
+	input := mat.NewDense(16, 1, nil)
+	for i := 0; i < 16; i++ {
+		input.Set(i, 0, rng.Float64())
+	}
+	return network, input
+}
+
+func logit(t *testing.T, network *NeuralNetwork, input *mat.Dense, class int) float64 {
+	t.Helper()
+	logits, err := network.Forward(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logits.At(class, 0)
+}
+
+func TestSaliencyMatchesFiniteDifferences(t *testing.T) {
+	network, input := explainNetwork(t)
+	attribution, err := Saliency(network, input, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attribution.Class != 2 || len(attribution.Values) != 16 {
+		t.Fatalf("unexpected attribution %+v", attribution)
+	}
+	const h = 1e-6
+	for i := 0; i < 16; i++ {
+		plus, minus := mat.DenseCopyOf(input), mat.DenseCopyOf(input)
+		plus.Set(i, 0, input.At(i, 0)+h)
+		minus.Set(i, 0, input.At(i, 0)-h)
+		numeric := (logit(t, network, plus, 2) - logit(t, network, minus, 2)) / (2 * h)
+		if math.Abs(numeric-attribution.Values[i]) > 1e-5 {
+			t.Errorf("input %d: gradient %v, numeric %v", i, attribution.Values[i], numeric)
+		}
+	}
+}
+
+func TestSaliencyDefaultsToPredictedClass(t *testing.T) {
+	network, input := explainNetwork(t)
+	attribution, err := Saliency(network, input, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logits, _ := network.Forward(input)
+	if want := argmax(columnData(logits)); attribution.Class != want {
+		t.Errorf("explained class %d, predicted %d", attribution.Class, want)
+	}
+	if _, err := Saliency(network, input, 3); err == nil {
+		t.Error("expected an error for a class out of range")
+	}
+}
+
+// 積分梯度的總和應該接近 logit(input) - logit(0)
+func TestIntegratedGradientsCompleteness(t *testing.T) {
+	network, input := explainNetwork(t)
+	attribution, err := IntegratedGradients(network, input, 1, 400)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := 0.0
+	for _, v := range attribution.Values {
+		sum += v
+	}
+	want := logit(t, network, input, 1) - logit(t, network, mat.NewDense(16, 1, nil), 1)
+	if math.Abs(sum-want) > 1e-2*math.Max(1, math.Abs(want)) {
+		t.Errorf("attributions sum to %v, want %v", sum, want)
+	}
+}
+
+func TestSmoothGradWithoutNoiseIsSaliency(t *testing.T) {
+	network, input := explainNetwork(t)
+	smooth, err := SmoothGrad(network, input, 0, 5, 0, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	saliency, err := Saliency(network, input, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range smooth.Values {
+		if math.Abs(smooth.Values[i]-saliency.Values[i]) > 1e-12 {
+			t.Fatalf("input %d: %v != %v", i, smooth.Values[i], saliency.Values[i])
+		}
+	}
+}
+
+func TestAttributionHeatmap(t *testing.T) {
+	a := Attribution{Values: []float64{1, -4, 0, 2, 1, 0, 0, 2}, Shape: ImageShape{Channels: 2, Height: 2, Width: 2}}
+	heat := a.Heatmap()
+	// 兩個通道的絕對值相加後為 2, 4, 0, 4
+	want := []float64{0.5, 1, 0, 1}
+	for i := range want {
+		if math.Abs(heat[i]-want[i]) > 1e-12 {
+			t.Fatalf("Heatmap() = %v, want %v", heat, want)
+		}
+	}
+}
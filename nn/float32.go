@@ -0,0 +1,319 @@
+package nn
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/blas"
+	"gonum.org/v1/gonum/blas/blas32"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Precision 網路計算使用的浮點數精度
+type Precision string
+
+const (
+	Float64 Precision = "float64" // 預設，全部透過 gonum/mat
+	Float32 Precision = "float32" // 全連接層以 blas32 計算，權重以 float32 儲存
+)
+
+// SetPrecision 切換網路的計算精度
+// 切到 float32 時，權重會轉成 float32 並由 float32 版本負責訓練與推論；
+// float64 的矩陣（Hidden / OutputWeight）仍保留作為交換格式，訓練時每個 epoch 結束後同步一次
+func (nn *NeuralNetwork) SetPrecision(precision Precision) error {
+	switch precision {
+	case Float64, "":
+		if nn.f32 != nil {
+			nn.f32.store(nn)
+		}
+		nn.f32 = nil
+		nn.Precision = Float64
+	case Float32:
+		nn.f32 = newParams32(nn)
+		nn.Precision = Float32
+	default:
+		return fmt.Errorf("Unknown precision %q", precision)
+	}
+	return nil
+}
+
+func (nn NeuralNetwork) isFloat32() bool {
+	return nn.Precision == Float32 && nn.f32 != nil
+}
+
+// refreshFloat32 float64 的權重被直接修改後（例如剪枝），重新建立 float32 權重
+func (nn *NeuralNetwork) refreshFloat32() {
+	if nn.Precision == Float32 {
+		nn.f32 = newParams32(nn)
+	}
+}
+
+// syncFromFloat32 把 float32 權重寫回 float64 矩陣
+func (nn *NeuralNetwork) syncFromFloat32() {
+	if nn.isFloat32() {
+		nn.f32.store(nn)
+	}
+}
+
+// dense32 float32 版本的全連接層，weight 以 row-major 連續儲存
+type dense32 struct {
+	rows, cols int
+	weight     []float32
+	bias       []float32
+}
+
+func (d dense32) general() blas32.General {
+	return blas32.General{Rows: d.rows, Cols: d.cols, Stride: d.cols, Data: d.weight}
+}
+
+// params32 float32 的所有參數，同樣的形狀也用來存放 float32 的梯度
+type params32 struct {
+	hidden []dense32
+	output dense32
+}
+
+func toDense32(weight, bias *mat.Dense) dense32 {
+	rows, cols := weight.Dims()
+	d := dense32{rows: rows, cols: cols, weight: make([]float32, rows*cols), bias: make([]float32, rows)}
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			d.weight[i*cols+j] = float32(weight.At(i, j))
+		}
+		d.bias[i] = float32(bias.At(i, 0))
+	}
+	return d
+}
+
+func newParams32(nn *NeuralNetwork) *params32 {
+	p := &params32{hidden: make([]dense32, len(nn.Hidden))}
+	for i, layer := range nn.Hidden {
+		p.hidden[i] = toDense32(layer.weight, layer.bias)
+	}
+	p.output = toDense32(nn.OutputWeight, nn.OutputBias)
+	return p
+}
+
+// zeroLike 形狀相同、全為 0 的參數（用來累加梯度）
+func (p *params32) zeroLike() *params32 {
+	z := &params32{hidden: make([]dense32, len(p.hidden))}
+	like := func(d dense32) dense32 {
+		return dense32{rows: d.rows, cols: d.cols, weight: make([]float32, len(d.weight)), bias: make([]float32, len(d.bias))}
+	}
+	for i, d := range p.hidden {
+		z.hidden[i] = like(d)
+	}
+	z.output = like(p.output)
+	return z
+}
+
+func (p *params32) clone() *params32 {
+	c := p.zeroLike()
+	c.add(p)
+	return c
+}
+
+// layers 依序回傳所有層（隱藏層 + 輸出層）
+func (p *params32) layers() []*dense32 {
+	layers := make([]*dense32, 0, len(p.hidden)+1)
+	for i := range p.hidden {
+		layers = append(layers, &p.hidden[i])
+	}
+	return append(layers, &p.output)
+}
+
+func (p *params32) zero() {
+	for _, d := range p.layers() {
+		clear(d.weight)
+		clear(d.bias)
+	}
+}
+
+// add p += other
+func (p *params32) add(other *params32) {
+	p.axpy(1, other)
+}
+
+// norm 所有參數的 L2 norm（以 float64 累加）
+func (p *params32) norm() float64 {
+	sum := 0.0
+	for _, d := range p.layers() {
+		for _, data := range [][]float32{d.weight, d.bias} {
+			n := float64(blas32.Nrm2(blas32.Vector{N: len(data), Inc: 1, Data: data}))
+			sum += n * n
+		}
+	}
+	return math.Sqrt(sum)
+}
+
+// axpy p += alpha * other
+func (p *params32) axpy(alpha float32, other *params32) {
+	mine, theirs := p.layers(), other.layers()
+	for i, d := range mine {
+		blas32.Axpy(alpha, blas32.Vector{N: len(d.weight), Inc: 1, Data: theirs[i].weight}, blas32.Vector{N: len(d.weight), Inc: 1, Data: d.weight})
+		blas32.Axpy(alpha, blas32.Vector{N: len(d.bias), Inc: 1, Data: theirs[i].bias}, blas32.Vector{N: len(d.bias), Inc: 1, Data: d.bias})
+	}
+}
+
+// store 將 float32 權重寫回網路的 float64 矩陣
+func (p *params32) store(nn *NeuralNetwork) {
+	write := func(d dense32, weight, bias *mat.Dense) {
+		w := weight.RawMatrix().Data
+		for i, v := range d.weight {
+			w[i] = float64(v)
+		}
+		b := bias.RawMatrix().Data
+		for i, v := range d.bias {
+			b[i] = float64(v)
+		}
+	}
+	for i, layer := range nn.Hidden {
+		write(p.hidden[i], layer.weight, layer.bias)
+	}
+	write(p.output, nn.OutputWeight, nn.OutputBias)
+}
+
+// workspace32 float32 版本的 workspace，forward/backward 不配置記憶體
+// loss 仍以 float64 計算（輸出層只有 OutputClass 個值），共用 lossInto
+type workspace32 struct {
+	input       []float32
+	activations [][]float32
+	deltas      [][]float32
+	logits      []float32
+	outputError []float32
+
+	logitsMat      *mat.Dense
+	outputErrorMat *mat.Dense
+}
+
+func newWorkspace32(p *params32, inputs int) *workspace32 {
+	ws := &workspace32{
+		input:       make([]float32, inputs),
+		activations: make([][]float32, len(p.hidden)),
+		deltas:      make([][]float32, len(p.hidden)),
+		logits:      make([]float32, p.output.rows),
+		outputError: make([]float32, p.output.rows),
+	}
+	for i, d := range p.hidden {
+		ws.activations[i] = make([]float32, d.rows)
+		ws.deltas[i] = make([]float32, d.rows)
+	}
+	ws.logitsMat = mat.NewDense(p.output.rows, 1, nil)
+	ws.outputErrorMat = mat.NewDense(p.output.rows, 1, nil)
+	return ws
+}
+
+// forward 計算單一樣本的 logits（同時寫入 float64 的 logitsMat）
+func (ws *workspace32) forward(p *params32, input []float64) []float32 {
+	for i, v := range input {
+		ws.input[i] = float32(v)
+	}
+	current := ws.input
+	for i, d := range p.hidden {
+		out := ws.activations[i]
+		copy(out, d.bias)
+		gemv32(d, current, out)
+		for k, v := range out {
+			if v < 0 {
+				out[k] = 0
+			}
+		}
+		current = out
+	}
+	copy(ws.logits, p.output.bias)
+	gemv32(p.output, current, ws.logits)
+
+	logits64 := ws.logitsMat.RawMatrix().Data
+	for i, v := range ws.logits {
+		logits64[i] = float64(v)
+	}
+	return ws.logits
+}
+
+// backward 由 outputErrorMat 反向傳播，把梯度累加進 grads
+func (ws *workspace32) backward(p *params32, grads *params32) {
+	for i, v := range ws.outputErrorMat.RawMatrix().Data {
+		ws.outputError[i] = float32(v)
+	}
+	layercount := len(p.hidden)
+	last := ws.input
+	if layercount > 0 {
+		last = ws.activations[layercount-1]
+	}
+	ger32(ws.outputError, last, grads.output)
+	addVec32(grads.output.bias, ws.outputError)
+
+	for i := layercount - 1; i >= 0; i-- {
+		delta := ws.deltas[i]
+		if i == layercount-1 {
+			gemvT32(p.output, ws.outputError, delta)
+		} else {
+			gemvT32(p.hidden[i+1], ws.deltas[i+1], delta)
+		}
+		for k, a := range ws.activations[i] {
+			if a <= 0 {
+				delta[k] = 0
+			}
+		}
+		prev := ws.input
+		if i > 0 {
+			prev = ws.activations[i-1]
+		}
+		ger32(delta, prev, grads.hidden[i])
+		addVec32(grads.hidden[i].bias, delta)
+	}
+}
+
+// sampleGradients32 sampleGradients 的 float32 版本
+func (nn *NeuralNetwork) sampleGradients32(ws *workspace32, input *mat.Dense, target *mat.Dense, grads *params32) (float64, error) {
+	if r, c := input.Dims(); r != nn.Inputs || c != 1 {
+		return 0, fmt.Errorf("Input is %dx%d, model expects %dx1", r, c, nn.Inputs)
+	}
+	ws.forward(nn.f32, columnData(input))
+	loss, err := nn.lossInto(ws.logitsMat, target, ws.outputErrorMat)
+	if err != nil {
+		return 0, err
+	}
+	ws.backward(nn.f32, grads)
+	return loss, nil
+}
+
+// forward32 Forward 的 float32 版本，input 的每一個 column 為一個樣本
+func (nn NeuralNetwork) forward32(input *mat.Dense) *mat.Dense {
+	_, c := input.Dims()
+	ws := newWorkspace32(nn.f32, nn.Inputs)
+	output := mat.NewDense(nn.OutputClass, c, nil)
+	column := make([]float64, nn.Inputs)
+	for j := 0; j < c; j++ {
+		mat.Col(column, j, input)
+		ws.forward(nn.f32, column)
+		output.SetCol(j, ws.logitsMat.RawMatrix().Data)
+	}
+	return output
+}
+
+// gemv32 y += W x
+func gemv32(d dense32, x, y []float32) {
+	blas32.Gemv(blas.NoTrans, 1, d.general(),
+		blas32.Vector{N: d.cols, Inc: 1, Data: x}, 1,
+		blas32.Vector{N: d.rows, Inc: 1, Data: y})
+}
+
+// gemvT32 y = W^T x
+func gemvT32(d dense32, x, y []float32) {
+	blas32.Gemv(blas.Trans, 1, d.general(),
+		blas32.Vector{N: d.rows, Inc: 1, Data: x}, 0,
+		blas32.Vector{N: d.cols, Inc: 1, Data: y})
+}
+
+// ger32 W += x y^T
+func ger32(x, y []float32, d dense32) {
+	blas32.Ger(1,
+		blas32.Vector{N: d.rows, Inc: 1, Data: x},
+		blas32.Vector{N: d.cols, Inc: 1, Data: y}, d.general())
+}
+
+func addVec32(dst, src []float32) {
+	for i, v := range src {
+		dst[i] += v
+	}
+}
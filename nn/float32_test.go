@@ -0,0 +1,144 @@
+package nn
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// learnableDataset 標籤由固定的線性函數決定，小網路訓練幾個 epoch 就能學會
+func learnableDataset(seed int64, samples, inputs, classes int) []TrainingData {
+	rng := rand.New(rand.NewSource(seed))
+	projection := mat.NewDense(classes, inputs, nil)
+	for i := 0; i < classes; i++ {
+		for j := 0; j < inputs; j++ {
+			projection.Set(i, j, rng.NormFloat64())
+		}
+	}
+	data := make([]TrainingData, samples)
+	for i := range data {
+		input := mat.NewDense(inputs, 1, nil)
+		for j := 0; j < inputs; j++ {
+			input.Set(j, 0, rng.Float64())
+		}
+		var scores mat.Dense
+		scores.Mul(projection, input)
+		label, _ := Argmax(&scores)
+		target := mat.NewDense(classes, 1, nil)
+		target.Set(label, 0, 1)
+		data[i] = TrainingData{Input: input, Target: target}
+	}
+	return data
+}
+
+func TestFloat32TrainingMatchesFloat64(t *testing.T) {
+	data := learnableDataset(3, 600, 16, 4)
+	trainSet, testSet := data[:400], data[400:]
+	base, err := NewNeuralNetwork(16, 4, []int{32, 16}, 0.05)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := TrainingConfig{Epochs: 5, BatchSize: 8, Seed: 1}
+
+	network64 := base.Clone()
+	if err := TrainingLoop(context.Background(), network64, config, trainSet, testSet); err != nil {
+		t.Fatal(err)
+	}
+	network32 := base.Clone()
+	if err := network32.SetPrecision(Float32); err != nil {
+		t.Fatal(err)
+	}
+	if err := TrainingLoop(context.Background(), network32, config, trainSet, testSet); err != nil {
+		t.Fatal(err)
+	}
+
+	metrics64, err := Evaluate(network64, testSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics32, err := Evaluate(network32, testSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metrics64["accuracy"] < 0.7 {
+		t.Fatalf("float64 accuracy %.3f, the dataset should be learnable", metrics64["accuracy"])
+	}
+	if diff := math.Abs(metrics64["accuracy"] - metrics32["accuracy"]); diff > 0.01 {
+		t.Errorf("accuracy float64 %.4f vs float32 %.4f", metrics64["accuracy"], metrics32["accuracy"])
+	}
+
+	// 兩條路徑的 logits 應該只差 float32 的捨入誤差
+	for _, sample := range testSet[:20] {
+		logits64, _ := network64.Forward(sample.Input)
+		logits32, _ := network32.Forward(sample.Input)
+		if !mat.EqualApprox(logits64, logits32, 1e-3) {
+			t.Fatalf("logits differ:\nfloat64 %v\nfloat32 %v", mat.Formatted(logits64.T()), mat.Formatted(logits32.T()))
+		}
+	}
+}
+
+func TestFloat32ParallelTrainingIsDeterministic(t *testing.T) {
+	data := syntheticDataset(1, 45, 12, 3)
+	base, err := NewNeuralNetwork(12, 3, []int{10}, 0.05)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := base.SetPrecision(Float32); err != nil {
+		t.Fatal(err)
+	}
+	train := func(workers int) *NeuralNetwork {
+		network := base.Clone()
+		if err := TrainingLoop(context.Background(), network, TrainingConfig{Epochs: 2, BatchSize: 20, Workers: workers, Seed: 5}, data, data[:5]); err != nil {
+			t.Fatal(err)
+		}
+		return network
+	}
+	want, got := train(1), train(4)
+	if !mat.Equal(want.OutputWeight, got.OutputWeight) || !mat.Equal(want.Hidden[0].weight, got.Hidden[0].weight) {
+		t.Error("float32 training with 4 workers differs from single-threaded training")
+	}
+}
+
+func TestFloat32SaveLoadRoundTrip(t *testing.T) {
+	network, err := NewNeuralNetwork(20, 5, []int{12, 8}, 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	path64 := filepath.Join(dir, "model64.json")
+	if err := SaveModel(network, path64); err != nil {
+		t.Fatal(err)
+	}
+	if err := network.SetPrecision(Float32); err != nil {
+		t.Fatal(err)
+	}
+	path32 := filepath.Join(dir, "model32.json")
+	if err := SaveModel(network, path32); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadModel(path32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Precision != Float32 {
+		t.Fatalf("loaded precision %q, want float32", loaded.Precision)
+	}
+	input := syntheticDataset(2, 1, 20, 5)[0].Input
+	want, _ := network.Forward(input)
+	got, _ := loaded.Forward(input)
+	if !mat.Equal(want, got) {
+		t.Errorf("loaded float32 model gives %v, want %v", mat.Formatted(got.T()), mat.Formatted(want.T()))
+	}
+
+	info64, _ := os.Stat(path64)
+	info32, _ := os.Stat(path32)
+	if info32.Size() >= info64.Size() {
+		t.Errorf("float32 model is %d bytes, float64 model is %d bytes", info32.Size(), info64.Size())
+	}
+}
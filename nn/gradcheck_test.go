@@ -0,0 +1,91 @@
+package nn
+
+import (
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// 每種輸出頭與損失函數、不同深度的網路，backPropagation 都要和數值梯度一致
+func TestBackPropagationGradientCheck(t *testing.T) {
+	heads := []struct {
+		name       string
+		activation OutputActivation
+		loss       LossConfig
+		target     []float64
+	}{
+		{"softmax cross entropy", OutputSoftmax, LossConfig{Type: LossCrossEntropy}, []float64{0, 0, 1}},
+		{"weighted smoothed cross entropy", OutputSoftmax, LossConfig{Type: LossCrossEntropy, ClassWeights: []float64{2, 0.5, 1}, LabelSmoothing: 0.1}, []float64{0, 1, 0}},
+		{"focal", OutputSoftmax, LossConfig{Type: LossFocal, FocalGamma: 2}, []float64{1, 0, 0}},
+		{"sigmoid binary cross entropy", OutputSigmoid, LossConfig{Type: LossBinaryCrossEntropy}, []float64{1, 0, 1}},
+		{"linear mse", OutputLinear, LossConfig{Type: LossMSE}, []float64{0.5, -1, 2}},
+		{"linear mae", OutputLinear, LossConfig{Type: LossMAE}, []float64{0.5, -1, 2}},
+		{"linear huber", OutputLinear, LossConfig{Type: LossHuber, HuberDelta: 0.3}, []float64{0.5, -1, 2}},
+	}
+	architectures := [][]int{{7}, {6, 5}, {5, 4, 6}}
+
+	rng := rand.New(rand.NewSource(11))
+	for _, head := range heads {
+		for _, hidden := range architectures {
+			network, err := NewNeuralNetwork(4, 3, hidden, 0.1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := network.SetOutputHead(head.activation, head.loss); err != nil {
+				t.Fatal(err)
+			}
+			// 非 0 的 bias 讓 ReLU 的各種情況都出現
+			for _, layer := range network.Hidden {
+				for i := range layer.bias.RawMatrix().Data {
+					layer.bias.RawMatrix().Data[i] = rng.NormFloat64() * 0.1
+				}
+			}
+			input := mat.NewDense(4, 1, []float64{rng.Float64(), rng.Float64(), rng.Float64(), rng.Float64()})
+			target := mat.NewDense(3, 1, head.target)
+
+			result, err := GradientCheck(network, input, target, 1e-6)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.MaxError > 1e-5 {
+				t.Errorf("%s %v: %s", head.name, hidden, result)
+			}
+		}
+	}
+}
+
+func TestGradientCheckFloat32Model(t *testing.T) {
+	network, err := NewNeuralNetwork(3, 2, []int{4}, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := network.SetPrecision(Float32); err != nil {
+		t.Fatal(err)
+	}
+	before := mat.DenseCopyOf(network.OutputWeight)
+	input := mat.NewDense(3, 1, []float64{0.2, 0.7, 0.4})
+	target := mat.NewDense(2, 1, []float64{1, 0})
+	result, err := GradientCheck(network, input, target, 1e-6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 4*3 + 4 + 2*4 + 2; result.Checked != want {
+		t.Errorf("checked %d parameters, want %d", result.Checked, want)
+	}
+	if result.MaxError > 1e-5 {
+		t.Error(result)
+	}
+	if network.Precision != Float32 || !mat.Equal(before, network.OutputWeight) {
+		t.Error("GradientCheck modified the network")
+	}
+}
+
+func TestGradientError(t *testing.T) {
+	if e := gradientError(10, 11); e < 0.09 || e > 0.1 {
+		t.Errorf("gradientError(10, 11) = %v, want relative error 1/11", e)
+	}
+	if e := gradientError(1e-9, 2e-9); e > 1e-8 {
+		t.Errorf("gradientError(1e-9, 2e-9) = %v, want absolute error", e)
+	}
+}
@@ -0,0 +1,93 @@
+package nn
+
+import (
+	"fmt"
+
+	"golang-neural-network/autodiff"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Graph 以 autodiff 的運算在 tape 上建立網路：隱藏層為 ReLU(W x + b)，輸出層為 W x + b（logits）
+// input 的每一個 column 為一個樣本。參數節點直接包住網路的權重矩陣（不複製），順序為
+// hidden[0].weight, hidden[0].bias, ..., output.weight, output.bias
+// 使用 float64 的權重，float32 模型的權重在每個 epoch 結束時才會同步
+func (nn *NeuralNetwork) Graph(tape *autodiff.Tape, input *mat.Dense) (*autodiff.Node, []*autodiff.Node) {
+	return nn.graph(tape, tape.Constant(input), tape.Variable)
+}
+
+// graph 以 x 為輸入建立網路，參數節點由 param 建立（訓練時為 Variable，只需要輸入梯度時為 Constant）
+func (nn *NeuralNetwork) graph(tape *autodiff.Tape, x *autodiff.Node, param func(*mat.Dense) *autodiff.Node) (*autodiff.Node, []*autodiff.Node) {
+	var params []*autodiff.Node
+	dense := func(weight, bias *mat.Dense, x *autodiff.Node) *autodiff.Node {
+		w, b := param(weight), param(bias)
+		params = append(params, w, b)
+		return tape.Add(tape.MatMul(w, x), b)
+	}
+
+	current := x
+	for _, layer := range nn.Hidden {
+		current = tape.ReLU(dense(layer.weight, layer.bias, current))
+	}
+	return dense(nn.OutputWeight, nn.OutputBias, current), params
+}
+
+// autodiffGradients 以 Graph 計算一批樣本的梯度總和並累加進 grads，回傳 loss 總和與預測正確的樣本數
+// 整批樣本組成一個矩陣一起 forward；損失函數仍由 lossInto 逐一樣本計算，其梯度作為 logits 的起始梯度
+func (nn *NeuralNetwork) autodiffGradients(samples []TrainingData, grads *gradients) (float64, int, error) {
+	input := mat.NewDense(nn.Inputs, len(samples), nil)
+	target := mat.NewDense(nn.OutputClass, len(samples), nil)
+	for j, sample := range samples {
+		if r, c := sample.Input.Dims(); r != nn.Inputs || c != 1 {
+			return 0, 0, fmt.Errorf("Input is %dx%d, model expects %dx1", r, c, nn.Inputs)
+		}
+		if r, c := sample.Target.Dims(); r != nn.OutputClass || c != 1 {
+			return 0, 0, fmt.Errorf("Target is %dx%d, model expects %dx1", r, c, nn.OutputClass)
+		}
+		input.SetCol(j, columnData(sample.Input))
+		target.SetCol(j, columnData(sample.Target))
+	}
+
+	tape := autodiff.NewTape()
+	logits, params := nn.Graph(tape, input)
+	// 與手寫的 backward 相同，逐一樣本計算 loss 再加總，回歸損失才不會被 batch 大小平均掉
+	outputError := mat.NewDense(nn.OutputClass, len(samples), nil)
+	loss := 0.0
+	for j := range samples {
+		sampleLoss, err := nn.lossInto(column(logits.Value, j), column(target, j), column(outputError, j))
+		if err != nil {
+			return 0, 0, err
+		}
+		loss += sampleLoss
+	}
+	if err := tape.BackwardWithGrad(logits, outputError); err != nil {
+		return 0, 0, err
+	}
+	correct := 0
+	if nn.IsClassifier() {
+		labels := ArgmaxColumns(target)
+		for j, predicted := range ArgmaxColumns(logits.Value) {
+			if predicted == labels[j] {
+				correct++
+			}
+		}
+	}
+
+	destinations := make([]*mat.Dense, 0, len(params))
+	for i := range nn.Hidden {
+		destinations = append(destinations, grads.hiddenWeights[i], grads.hiddenBiases[i])
+	}
+	destinations = append(destinations, grads.outputWeight, grads.outputBias)
+	for i, param := range params {
+		if param.Grad != nil {
+			destinations[i].Add(destinations[i], param.Grad)
+		}
+	}
+	return loss, correct, nil
+}
+
+// column 第 j 個 column 的 view，寫入會改到 m
+func column(m *mat.Dense, j int) *mat.Dense {
+	r, _ := m.Dims()
+	return m.Slice(0, r, j, j+1).(*mat.Dense)
+}
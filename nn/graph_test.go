@@ -0,0 +1,130 @@
+package nn
+
+import (
+	"context"
+	"testing"
+
+	"golang-neural-network/autodiff"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// autodiff 建立的網路必須和手寫的 Forward / backPropagation 得到相同的結果
+func TestGraphMatchesBackPropagation(t *testing.T) {
+	heads := []struct {
+		activation OutputActivation
+		loss       LossConfig
+	}{
+		{OutputSoftmax, LossConfig{Type: LossCrossEntropy}},
+		{OutputSoftmax, LossConfig{Type: LossFocal, FocalGamma: 2, LabelSmoothing: 0.1}},
+		{OutputSigmoid, LossConfig{Type: LossBinaryCrossEntropy}},
+		{OutputLinear, LossConfig{Type: LossMSE}},
+		{OutputLinear, LossConfig{Type: LossHuber, HuberDelta: 0.5}},
+	}
+	data := syntheticDataset(9, 10, 6, 3)
+	for _, head := range heads {
+		network, err := NewNeuralNetwork(6, 3, []int{8, 5}, 0.1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := network.SetOutputHead(head.activation, head.loss); err != nil {
+			t.Fatal(err)
+		}
+
+		want := newGradients(network)
+		ws := newWorkspace(network)
+		wantLoss := 0.0
+		for _, sample := range data {
+			loss, err := network.sampleGradients(ws, sample.Input, sample.Target, want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantLoss += loss
+		}
+		got := newGradients(network)
+		gotLoss, _, err := network.autodiffGradients(data, got)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if diff := gotLoss - wantLoss; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("%s: loss %v, want %v", head.loss.Type, gotLoss, wantLoss)
+		}
+		for i := range network.Hidden {
+			if !mat.EqualApprox(got.hiddenWeights[i], want.hiddenWeights[i], 1e-9) || !mat.EqualApprox(got.hiddenBiases[i], want.hiddenBiases[i], 1e-9) {
+				t.Errorf("%s: hidden layer %d gradients differ", head.loss.Type, i)
+			}
+		}
+		if !mat.EqualApprox(got.outputWeight, want.outputWeight, 1e-9) || !mat.EqualApprox(got.outputBias, want.outputBias, 1e-9) {
+			t.Errorf("%s: output layer gradients differ", head.loss.Type)
+		}
+	}
+}
+
+func TestGraphForward(t *testing.T) {
+	network, err := NewNeuralNetwork(6, 3, []int{8}, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := syntheticDataset(10, 1, 6, 3)[0].Input
+	tape := autodiff.NewTape()
+	logits, params := network.Graph(tape, input)
+	want, _ := network.Forward(input)
+	if !mat.EqualApprox(logits.Value, want, 1e-12) {
+		t.Errorf("Graph logits %v, Forward %v", mat.Formatted(logits.Value.T()), mat.Formatted(want.T()))
+	}
+	if len(params) != 4 {
+		t.Errorf("%d parameter nodes, want 4", len(params))
+	}
+}
+
+func TestAutodiffTraining(t *testing.T) {
+	data := syntheticDataset(12, 40, 6, 3)
+	base, err := NewNeuralNetwork(6, 3, []int{8}, 0.05)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handwritten, viaAutodiff := base.Clone(), base.Clone()
+	if err := TrainingLoop(context.Background(), handwritten, TrainingConfig{Epochs: 2, BatchSize: 10, Workers: 2}, data, data[:5]); err != nil {
+		t.Fatal(err)
+	}
+	if err := TrainingLoop(context.Background(), viaAutodiff, TrainingConfig{Epochs: 2, BatchSize: 10, Workers: 2, Autodiff: true}, data, data[:5]); err != nil {
+		t.Fatal(err)
+	}
+	if !mat.EqualApprox(handwritten.OutputWeight, viaAutodiff.OutputWeight, 1e-9) {
+		t.Error("autodiff training diverged from the handwritten backward pass")
+	}
+
+	float32Network := base.Clone()
+	if err := float32Network.SetPrecision(Float32); err != nil {
+		t.Fatal(err)
+	}
+	if err := TrainingLoop(context.Background(), float32Network, TrainingConfig{Epochs: 1, Autodiff: true}, data, data); err == nil {
+		t.Error("autodiff training accepted a float32 model")
+	}
+}
+
+func TestCheckBackward(t *testing.T) {
+	data := syntheticDataset(13, 20, 6, 3)
+	network, err := NewNeuralNetwork(6, 3, []int{8}, 0.05)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := TrainingLoop(context.Background(), network, TrainingConfig{Epochs: 1, BatchSize: 10, CheckBackward: true}, data, data[:5]); err != nil {
+		t.Fatalf("matching gradients rejected: %v", err)
+	}
+
+	// 以目前權重的 Graph 梯度作為 total 時一致，再模擬手寫 backward 的錯誤
+	trainer := newParallelTrainer(network, 1, len(data))
+	defer trainer.close()
+	if _, _, err := network.autodiffGradients(data, trainer.total); err != nil {
+		t.Fatal(err)
+	}
+	if err := trainer.verifyHandwritten(data); err != nil {
+		t.Fatal(err)
+	}
+	trainer.total.outputBias.Set(0, 0, trainer.total.outputBias.At(0, 0)+0.1)
+	if err := trainer.verifyHandwritten(data); err == nil {
+		t.Error("expected an error for gradients that differ from the graph")
+	}
+}
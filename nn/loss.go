@@ -0,0 +1,327 @@
+package nn
+
+import (
+	"fmt"
+	"math"
+
+	"golang-neural-network/internal/numeric"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// OutputActivation 決定輸出層的激活函數（輸出頭）
+type OutputActivation string
+
+const (
+	OutputSoftmax OutputActivation = "softmax" // 單標籤分類
+	OutputSigmoid OutputActivation = "sigmoid" // 多標籤分類，每個輸出獨立
+	OutputLinear  OutputActivation = "linear"  // 迴歸
+)
+
+// LossFunc 訓練使用的損失函數
+type LossFunc string
+
+const (
+	LossCrossEntropy       LossFunc = "cross_entropy"
+	LossBinaryCrossEntropy LossFunc = "binary_cross_entropy"
+	LossMSE                LossFunc = "mse"
+	LossMAE                LossFunc = "mae"
+	LossHuber              LossFunc = "huber"
+	LossFocal              LossFunc = "focal"
+)
+
+// LossConfig 損失函數與其參數，會跟著模型一起儲存
+type LossConfig struct {
+	Type       LossFunc `json:"type"`
+	HuberDelta float64  `json:"huber_delta,omitempty"`
+
+	// 分類用參數（cross-entropy / focal / binary cross-entropy）
+	ClassWeights   []float64 `json:"class_weights,omitempty"`   // 每個類別的權重，空值代表全部為 1
+	LabelSmoothing float64   `json:"label_smoothing,omitempty"` // 0 代表不做 label smoothing
+	FocalGamma     float64   `json:"focal_gamma,omitempty"`     // focal loss 的 gamma，預設 2
+}
+
+// 每種輸出頭可以搭配的損失函數
+var compatibleLosses = map[OutputActivation][]LossFunc{
+	OutputSoftmax: {LossCrossEntropy, LossFocal},
+	OutputSigmoid: {LossBinaryCrossEntropy},
+	OutputLinear:  {LossMSE, LossMAE, LossHuber},
+}
+
+// SetOutputHead 設定輸出層激活函數與對應的損失函數
+// 不相容的組合（例如 softmax + MSE）會回傳錯誤
+func (nn *NeuralNetwork) SetOutputHead(activation OutputActivation, loss LossConfig) error {
+	losses, ok := compatibleLosses[activation]
+	if !ok {
+		return fmt.Errorf("Unknown output activation %q", activation)
+	}
+	compatible := false
+	for _, l := range losses {
+		if l == loss.Type {
+			compatible = true
+		}
+	}
+	if !compatible {
+		return fmt.Errorf("Loss %q can't be used with %q output, expected one of %v", loss.Type, activation, losses)
+	}
+	if loss.Type == LossHuber && loss.HuberDelta <= 0 {
+		loss.HuberDelta = 1.0
+	}
+	if loss.Type == LossFocal && loss.FocalGamma <= 0 {
+		loss.FocalGamma = 2.0
+	}
+	if loss.LabelSmoothing < 0 || loss.LabelSmoothing >= 1 {
+		return fmt.Errorf("Label smoothing must be in [0, 1), got %v", loss.LabelSmoothing)
+	}
+	if loss.ClassWeights != nil && nn.OutputClass > 0 && len(loss.ClassWeights) != nn.OutputClass {
+		return fmt.Errorf("Got %d class weights for %d classes", len(loss.ClassWeights), nn.OutputClass)
+	}
+	for _, w := range loss.ClassWeights {
+		if w < 0 {
+			return fmt.Errorf("Class weights must be non-negative, got %v", loss.ClassWeights)
+		}
+	}
+	nn.Output = activation
+	nn.Loss = loss
+	return nil
+}
+
+// outputActivation 舊模型沒有記錄輸出頭，預設為 softmax + cross-entropy
+func (nn NeuralNetwork) outputActivation() OutputActivation {
+	if nn.Output == "" {
+		return OutputSoftmax
+	}
+	return nn.Output
+}
+
+func (nn NeuralNetwork) lossConfig() LossConfig {
+	if nn.Loss.Type == "" {
+		return LossConfig{Type: LossCrossEntropy}
+	}
+	return nn.Loss
+}
+
+// IsClassifier 單標籤分類模型（softmax 輸出）
+func (nn NeuralNetwork) IsClassifier() bool {
+	return nn.outputActivation() == OutputSoftmax
+}
+
+// activateOutput 將 logits 轉成模型輸出（機率或迴歸值）
+func (nn NeuralNetwork) activateOutput(logits *mat.Dense) *mat.Dense {
+	switch nn.outputActivation() {
+	case OutputSigmoid:
+		return sigmoid(logits)
+	case OutputLinear:
+		return mat.DenseCopyOf(logits)
+	default:
+		return Softmax(logits)
+	}
+}
+
+// Predict 執行 Forward 並套用輸出層激活函數
+func (nn NeuralNetwork) Predict(input *mat.Dense) (*mat.Dense, error) {
+	logits, err := nn.Forward(input)
+	if err != nil {
+		return nil, err
+	}
+	return nn.activateOutput(logits), nil
+}
+
+func sigmoid(m *mat.Dense) *mat.Dense {
+	r, c := m.Dims()
+	result := mat.NewDense(r, c, nil)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			result.Set(i, j, 1.0/(1.0+math.Exp(-m.At(i, j))))
+		}
+	}
+	return result
+}
+
+// softplus log(1 + e^x)，大數值時避免溢位
+func softplus(x float64) float64 {
+	if x > 0 {
+		return x + math.Log1p(math.Exp(-x))
+	}
+	return math.Log1p(math.Exp(x))
+}
+
+// lossAndGradient 計算 loss 以及 loss 對輸出層 logits 的梯度 dL/dz
+// 梯度直接對 logits 求，所以輸出層激活函數的導數已經包含在內
+func (nn NeuralNetwork) lossAndGradient(logits *mat.Dense, target *mat.Dense) (float64, *mat.Dense, error) {
+	r, c := logits.Dims()
+	grad := mat.NewDense(r, c, nil)
+	loss, err := nn.lossInto(logits, target, grad)
+	if err != nil {
+		return 0, nil, err
+	}
+	return loss, grad, nil
+}
+
+// lossInto 與 lossAndGradient 相同，但梯度寫入呼叫端提供的 grad（訓練時為 workspace 的 buffer）
+func (nn NeuralNetwork) lossInto(logits *mat.Dense, target *mat.Dense, grad *mat.Dense) (float64, error) {
+	r, c := logits.Dims()
+	tr, tc := target.Dims()
+	if r != tr || c != tc {
+		return 0, fmt.Errorf("Output is %dx%d but target is %dx%d", r, c, tr, tc)
+	}
+	config := nn.lossConfig()
+	loss := 0.0
+
+	if config.ClassWeights != nil && len(config.ClassWeights) != r {
+		return 0, fmt.Errorf("Got %d class weights for %d outputs", len(config.ClassWeights), r)
+	}
+
+	switch config.Type {
+	case LossCrossEntropy, LossFocal:
+		if config.Type == LossCrossEntropy && config.ClassWeights == nil && config.LabelSmoothing == 0 {
+			// softmax + cross-entropy: dL/dz = pred - target
+			loss = softmaxCrossEntropyInto(logits, target, grad)
+			break
+		}
+		loss = classificationLoss(logits, target, config, grad)
+
+	case LossBinaryCrossEntropy:
+		// 每個輸出獨立的 sigmoid: L = Σ w * (softplus(z) - t*z), dL/dz = w * (σ(z) - t)
+		// label smoothing 把 target 往 0.5 拉：t' = t(1-ε) + ε/2
+		for i := 0; i < r; i++ {
+			w := classWeight(config.ClassWeights, i)
+			for j := 0; j < c; j++ {
+				z := logits.At(i, j)
+				t := target.At(i, j)*(1-config.LabelSmoothing) + config.LabelSmoothing/2
+				loss += w * (softplus(z) - t*z)
+				grad.Set(i, j, w*(1.0/(1.0+math.Exp(-z))-t))
+			}
+		}
+
+	case LossMSE, LossMAE, LossHuber:
+		// 線性輸出，loss 對所有輸出取平均
+		n := float64(r * c)
+		delta := config.HuberDelta
+		if delta <= 0 {
+			delta = 1.0
+		}
+		for i := 0; i < r; i++ {
+			for j := 0; j < c; j++ {
+				diff := logits.At(i, j) - target.At(i, j)
+				l, g := regressionLoss(config.Type, diff, delta)
+				loss += l / n
+				grad.Set(i, j, g/n)
+			}
+		}
+
+	default:
+		return 0, fmt.Errorf("Unknown loss %q", config.Type)
+	}
+	return loss, nil
+}
+
+func classWeight(weights []float64, class int) float64 {
+	if weights == nil {
+		return 1.0
+	}
+	return weights[class]
+}
+
+// classificationLoss 加權、label smoothing 與 focal loss 的通用形式，梯度寫入 grad
+//
+//	y' = (1-ε) y + ε/K                      （label smoothing）
+//	L  = -Σ_i a_i y'_i (1-p_i)^γ log p_i   （a_i 為類別權重，γ = 0 時即為加權 cross-entropy）
+//
+// 令 f_i = γ (1-p_i)^(γ-1) p_i log p_i - (1-p_i)^γ，由 dp_i/dz_j = p_i (δ_ij - p_j) 得到
+//
+//	dL/dz_j = a_j y'_j f_j - p_j Σ_i a_i y'_i f_i
+//
+// γ = 0 時 f_i = -1，化簡為 (Σ_i a_i y'_i) p_j - a_j y'_j
+//
+// 先把 a_i y'_i f_i 寫進 grad，再減去 p_j Σ，不需要額外的 buffer
+func classificationLoss(logits, target *mat.Dense, config LossConfig, grad *mat.Dense) float64 {
+	r, c := logits.Dims()
+	gamma := 0.0
+	if config.Type == LossFocal {
+		gamma = config.FocalGamma
+		if gamma <= 0 {
+			gamma = 2.0
+		}
+	}
+	smoothing := config.LabelSmoothing
+
+	loss := 0.0
+	for j := 0; j < c; j++ {
+		max, logSum := numeric.ColumnLogSumExp(logits, j)
+		sum := 0.0
+		for i := 0; i < r; i++ {
+			logP := (logits.At(i, j) - max) - logSum
+			p := math.Exp(logP)
+			y := target.At(i, j)*(1-smoothing) + smoothing/float64(r)
+			a := classWeight(config.ClassWeights, i) * y
+
+			modulator := 1.0 // (1-p)^γ
+			f := -1.0
+			if gamma != 0 {
+				modulator = math.Pow(1-p, gamma)
+				f = -modulator
+				// p -> 1 時 (1-p)^(γ-1) 可能發散，但整項趨近於 0
+				if 1-p > 0 {
+					f += gamma * math.Pow(1-p, gamma-1) * p * logP
+				}
+			}
+			if a != 0 {
+				loss += -a * modulator * logP
+			}
+			grad.Set(i, j, a*f) // a_i y'_i f_i
+			sum += a * f
+		}
+		for i := 0; i < r; i++ {
+			p := math.Exp((logits.At(i, j) - max) - logSum)
+			grad.Set(i, j, grad.At(i, j)-p*sum)
+		}
+	}
+	return loss
+}
+
+// BalancedClassWeights 依訓練集的類別頻率計算權重 w_c = N / (K * n_c)
+// 出現次數越少的類別權重越大，沒有出現的類別權重為 0
+func BalancedClassWeights(data []TrainingData) ([]float64, error) {
+	_, classes, err := DatasetShape(data)
+	if err != nil {
+		return nil, err
+	}
+	counts := make([]float64, classes)
+	for _, sample := range data {
+		label, err := Argmax(sample.Target)
+		if err != nil {
+			return nil, err
+		}
+		counts[label]++
+	}
+	weights := make([]float64, classes)
+	for c, count := range counts {
+		if count > 0 {
+			weights[c] = float64(len(data)) / (float64(classes) * count)
+		}
+	}
+	return weights, nil
+}
+
+// regressionLoss 回傳單一元素的 loss 與對 diff (= pred - target) 的導數
+func regressionLoss(lossType LossFunc, diff, delta float64) (float64, float64) {
+	switch lossType {
+	case LossMAE:
+		sign := 0.0
+		if diff > 0 {
+			sign = 1
+		} else if diff < 0 {
+			sign = -1
+		}
+		return math.Abs(diff), sign
+	case LossHuber:
+		// |diff| <= delta 時為二次，之外為線性
+		if math.Abs(diff) <= delta {
+			return 0.5 * diff * diff, diff
+		}
+		return delta * (math.Abs(diff) - 0.5*delta), delta * math.Copysign(1, diff)
+	default:
+		return diff * diff, 2 * diff
+	}
+}
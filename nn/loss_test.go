@@ -0,0 +1,214 @@
+package nn
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func assertFinite(t *testing.T, name string, m *mat.Dense) {
+	t.Helper()
+	r, c := m.Dims()
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			if v := m.At(i, j); math.IsNaN(v) || math.IsInf(v, 0) {
+				t.Fatalf("%s[%d,%d] = %v", name, i, j, v)
+			}
+		}
+	}
+}
+
+func TestSoftmaxPerColumn(t *testing.T) {
+	// 第二個 column 的數值遠大於第一個，全域 max 會讓第一個 column 全部 underflow
+	batch := mat.NewDense(3, 2, []float64{
+		1, 1000,
+		2, 1001,
+		3, 1002,
+	})
+	got := Softmax(batch)
+	for j := 0; j < 2; j++ {
+		want := Softmax(mat.NewDense(3, 1, mat.Col(nil, j, batch)))
+		for i := 0; i < 3; i++ {
+			if math.Abs(got.At(i, j)-want.At(i, 0)) > 1e-12 {
+				t.Errorf("column %d row %d: got %v, want %v", j, i, got.At(i, j), want.At(i, 0))
+			}
+		}
+	}
+}
+
+func TestLogSoftmaxExtremeLogits(t *testing.T) {
+	logits := mat.NewDense(3, 2, []float64{
+		1000, 1e4,
+		0, 1e4,
+		-1000, -1e4,
+	})
+	got := LogSoftmax(logits)
+	assertFinite(t, "LogSoftmax", got)
+
+	want := [][]float64{
+		{0, -math.Ln2},
+		{-1000, -math.Ln2},
+		{-2000, -2e4 - math.Ln2},
+	}
+	for i := range want {
+		for j := range want[i] {
+			if math.Abs(got.At(i, j)-want[i][j]) > 1e-9 {
+				t.Errorf("LogSoftmax[%d,%d] = %v, want %v", i, j, got.At(i, j), want[i][j])
+			}
+		}
+	}
+}
+
+func TestSoftmaxCrossEntropyExtremeLogits(t *testing.T) {
+	tests := []struct {
+		name     string
+		logits   []float64
+		target   []float64
+		wantLoss float64
+		wantGrad []float64
+	}{
+		{"confidently wrong", []float64{-1000, 1000}, []float64{1, 0}, 2000, []float64{-1, 1}},
+		{"confidently right", []float64{-1000, 1000}, []float64{0, 1}, 0, []float64{0, 0}},
+		{"huge equal logits", []float64{1e300, 1e300}, []float64{1, 0}, math.Ln2, []float64{-0.5, 0.5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logits := mat.NewDense(len(tt.logits), 1, tt.logits)
+			target := mat.NewDense(len(tt.target), 1, tt.target)
+			loss, grad := SoftmaxCrossEntropy(logits, target)
+			if math.IsNaN(loss) || math.IsInf(loss, 0) {
+				t.Fatalf("loss = %v", loss)
+			}
+			assertFinite(t, "grad", grad)
+			if math.Abs(loss-tt.wantLoss) > 1e-9 {
+				t.Errorf("loss = %v, want %v", loss, tt.wantLoss)
+			}
+			for i, want := range tt.wantGrad {
+				if math.Abs(grad.At(i, 0)-want) > 1e-9 {
+					t.Errorf("grad[%d] = %v, want %v", i, grad.At(i, 0), want)
+				}
+			}
+		})
+	}
+}
+
+func TestSoftmaxCrossEntropyMatchesUnfused(t *testing.T) {
+	logits := mat.NewDense(4, 2, []float64{
+		0.5, -1,
+		-0.3, 2,
+		1.2, 0.1,
+		0, -0.4,
+	})
+	target := mat.NewDense(4, 2, []float64{
+		0, 0,
+		0, 1,
+		1, 0,
+		0, 0,
+	})
+	fused, grad := SoftmaxCrossEntropy(logits, target)
+	pred := Softmax(logits)
+	unfused, _ := crossEntropyLoss(pred, target)
+	if math.Abs(fused-unfused) > 1e-12 {
+		t.Errorf("fused loss %v, unfused loss %v", fused, unfused)
+	}
+	var want mat.Dense
+	want.Sub(pred, target)
+	if !mat.EqualApprox(grad, &want, 1e-12) {
+		t.Errorf("grad = %v, want pred - target = %v", mat.Formatted(grad), mat.Formatted(&want))
+	}
+}
+
+// 每種損失函數對 logits 的梯度都和中央差分比較，包含 batch 輸入
+func TestLossGradientsMatchFiniteDifferences(t *testing.T) {
+	tests := []struct {
+		name       string
+		activation OutputActivation
+		loss       LossConfig
+		target     []float64
+	}{
+		{"cross entropy", OutputSoftmax, LossConfig{Type: LossCrossEntropy}, []float64{0, 1, 0, 1, 0, 0}},
+		{"weighted smoothed cross entropy", OutputSoftmax, LossConfig{Type: LossCrossEntropy, ClassWeights: []float64{0.5, 2, 1}, LabelSmoothing: 0.1}, []float64{0, 1, 0, 1, 0, 0}},
+		{"focal", OutputSoftmax, LossConfig{Type: LossFocal, FocalGamma: 2, ClassWeights: []float64{0.5, 2, 1}}, []float64{0, 1, 0, 1, 0, 0}},
+		{"binary cross entropy", OutputSigmoid, LossConfig{Type: LossBinaryCrossEntropy}, []float64{0, 1, 1, 1, 0, 0}},
+		{"mse", OutputLinear, LossConfig{Type: LossMSE}, []float64{0.3, -1, 2, 0, 0.5, 1}},
+		{"mae", OutputLinear, LossConfig{Type: LossMAE}, []float64{0.3, -1, 2, 0, 0.5, 1}},
+		{"huber", OutputLinear, LossConfig{Type: LossHuber, HuberDelta: 0.5}, []float64{0.3, -1, 2, 0, 0.5, 1}},
+	}
+	logits := mat.NewDense(3, 2, []float64{
+		0.2, -1.5,
+		-0.7, 0.4,
+		1.1, 0.9,
+	})
+	const eps = 1e-6
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var network NeuralNetwork
+			if err := network.SetOutputHead(tt.activation, tt.loss); err != nil {
+				t.Fatal(err)
+			}
+			target := mat.NewDense(3, 2, tt.target)
+			_, grad, err := network.lossAndGradient(logits, target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 3; i++ {
+				for j := 0; j < 2; j++ {
+					plus := mat.DenseCopyOf(logits)
+					plus.Set(i, j, logits.At(i, j)+eps)
+					minus := mat.DenseCopyOf(logits)
+					minus.Set(i, j, logits.At(i, j)-eps)
+					lossPlus, _, _ := network.lossAndGradient(plus, target)
+					lossMinus, _, _ := network.lossAndGradient(minus, target)
+					numeric := (lossPlus - lossMinus) / (2 * eps)
+					if math.Abs(numeric-grad.At(i, j)) > 1e-6 {
+						t.Errorf("dL/dz[%d,%d] = %v, finite difference %v", i, j, grad.At(i, j), numeric)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestClassificationLossesExtremeLogits(t *testing.T) {
+	logits := mat.NewDense(3, 1, []float64{-1000, 1000, 0})
+	target := mat.NewDense(3, 1, []float64{1, 0, 0})
+	for _, config := range []LossConfig{
+		{Type: LossCrossEntropy, LabelSmoothing: 0.1},
+		{Type: LossFocal, FocalGamma: 2},
+		{Type: LossFocal, FocalGamma: 0.5, ClassWeights: []float64{1, 2, 3}},
+	} {
+		var network NeuralNetwork
+		if err := network.SetOutputHead(OutputSoftmax, config); err != nil {
+			t.Fatal(err)
+		}
+		loss, grad, err := network.lossAndGradient(logits, target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.IsNaN(loss) || math.IsInf(loss, 0) {
+			t.Errorf("%+v: loss = %v", config, loss)
+		}
+		assertFinite(t, string(config.Type), grad)
+	}
+}
+
+func TestRegressionLossAveragesOverAllOutputs(t *testing.T) {
+	var network NeuralNetwork
+	if err := network.SetOutputHead(OutputLinear, LossConfig{Type: LossMSE}); err != nil {
+		t.Fatal(err)
+	}
+	// 2 個輸出 x 2 個樣本，每個元素的 (z - t)^2 都是 1，loss 是 4 個元素的平均而不是樣本的總和
+	logits := mat.NewDense(2, 2, []float64{1, 2, 3, 4})
+	target := mat.NewDense(2, 2, []float64{0, 1, 2, 3})
+	loss, grad, err := network.lossAndGradient(logits, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(loss-1) > 1e-12 {
+		t.Errorf("batch loss = %v, want 1", loss)
+	}
+	if g := grad.At(0, 0); math.Abs(g-0.5) > 1e-12 {
+		t.Errorf("dL/dz[0,0] = %v, want 2 * 1 / 4", g)
+	}
+}
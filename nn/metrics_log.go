@@ -0,0 +1,124 @@
+package nn
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// MetricsRecord metrics log 中的一行
+// Type 為 "step" 時 Loss 是 batch 的平均 loss；為 "epoch" 時 Loss 是整個 epoch 的平均 training loss
+type MetricsRecord struct {
+	Type         string  `json:"type"`
+	Epoch        int     `json:"epoch"`
+	Step         int     `json:"step"`
+	Batch        int     `json:"batch,omitempty"`
+	Loss         float64 `json:"loss"`
+	Accuracy     float64 `json:"accuracy,omitempty"` // 只有 epoch 紀錄：訓練時的 accuracy
+	LearningRate float64 `json:"learning_rate"`
+	GradNorm     float64 `json:"grad_norm,omitempty"`
+	Duration     float64 `json:"duration_ms"` // step（或 epoch，含驗證）花費的時間
+	Elapsed      float64 `json:"elapsed_s"`   // 從訓練開始經過的時間
+	Validation   Metrics `json:"validation,omitempty"`
+}
+
+const (
+	RecordStep  = "step"
+	RecordEpoch = "epoch"
+)
+
+// MetricsLog 以 JSON Lines 格式把每個 step 與每個 epoch 的指標寫入 Out，一行一筆 MetricsRecord
+type MetricsLog struct {
+	BaseCallback
+	Out   io.Writer
+	Every int // 每幾個 step 寫一筆 step 紀錄，<= 0 時為每個 step；epoch 紀錄一定會寫
+
+	start, epochStart, stepStart time.Time
+}
+
+func (l *MetricsLog) OnTrainBegin(state *TrainingState) error {
+	l.start = time.Now()
+	return nil
+}
+
+func (l *MetricsLog) OnEpochBegin(state *TrainingState) error {
+	l.epochStart = time.Now()
+	l.stepStart = l.epochStart
+	return nil
+}
+
+func (l *MetricsLog) OnBatchEnd(state *TrainingState) error {
+	now := time.Now()
+	duration := now.Sub(l.stepStart)
+	l.stepStart = now
+	if l.Every > 1 && state.Step%l.Every != 0 {
+		return nil
+	}
+	return l.write(MetricsRecord{
+		Type:         RecordStep,
+		Epoch:        state.Epoch,
+		Step:         state.Step,
+		Batch:        state.Batch,
+		Loss:         state.BatchLoss,
+		LearningRate: state.Network.LearningRate,
+		GradNorm:     state.GradNorm,
+		Duration:     milliseconds(duration),
+		Elapsed:      now.Sub(l.start).Seconds(),
+	})
+}
+
+func (l *MetricsLog) OnEpochEnd(state *TrainingState) error {
+	now := time.Now()
+	return l.write(MetricsRecord{
+		Type:         RecordEpoch,
+		Epoch:        state.Epoch,
+		Step:         state.Step,
+		Loss:         state.TrainLoss,
+		Accuracy:     state.TrainAccuracy,
+		LearningRate: state.Network.LearningRate,
+		Duration:     milliseconds(now.Sub(l.epochStart)),
+		Elapsed:      now.Sub(l.start).Seconds(),
+		Validation:   state.Validation,
+	})
+}
+
+func (l *MetricsLog) write(record MetricsRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("Writing metrics: %w", err)
+	}
+	if _, err := l.Out.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("Writing metrics: %w", err)
+	}
+	return nil
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// ReadMetrics 讀取 MetricsLog 寫出的 JSON Lines
+func ReadMetrics(r io.Reader) ([]MetricsRecord, error) {
+	var records []MetricsRecord
+	decoder := json.NewDecoder(r)
+	for decoder.More() {
+		var record MetricsRecord
+		if err := decoder.Decode(&record); err != nil {
+			return records, fmt.Errorf("Reading metrics line %d: %w", len(records)+1, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// EpochRecords 只保留 epoch 紀錄
+func EpochRecords(records []MetricsRecord) []MetricsRecord {
+	var epochs []MetricsRecord
+	for _, record := range records {
+		if record.Type == RecordEpoch {
+			epochs = append(epochs, record)
+		}
+	}
+	return epochs
+}
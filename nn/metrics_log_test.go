@@ -0,0 +1,92 @@
+package nn
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"testing"
+)
+
+func TestMetricsLog(t *testing.T) {
+	data := syntheticDataset(17, 20, 4, 3)
+	network, err := NewNeuralNetwork(4, 3, []int{5}, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 第一個 step 的 grad norm 應等於整批平均梯度的 norm
+	grads := newGradients(network)
+	if _, _, err := network.Clone().autodiffGradients(data[:8], grads); err != nil {
+		t.Fatal(err)
+	}
+	wantNorm := grads.norm() / 8
+
+	var out bytes.Buffer
+	log := &MetricsLog{Out: &out}
+	config := TrainingConfig{Epochs: 2, BatchSize: 8, Workers: 2, Callbacks: []Callback{log}}
+	if err := TrainingLoop(context.Background(), network, config, data, data[:5]); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := ReadMetrics(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 每個 epoch 3 個 step 加 1 筆 epoch 紀錄
+	if len(records) != 8 {
+		t.Fatalf("got %d records, want 8:\n%s", len(records), out.String())
+	}
+	first := records[0]
+	if first.Type != "step" || first.Epoch != 1 || first.Step != 1 || first.Batch != 1 {
+		t.Errorf("first record = %+v", first)
+	}
+	if math.Abs(first.GradNorm-wantNorm) > 1e-9 {
+		t.Errorf("grad norm = %v, want %v", first.GradNorm, wantNorm)
+	}
+	if first.LearningRate != 0.1 || first.Duration < 0 {
+		t.Errorf("first record = %+v", first)
+	}
+
+	epochs := EpochRecords(records)
+	if len(epochs) != 2 || epochs[1].Epoch != 2 || epochs[1].Step != 6 {
+		t.Fatalf("epoch records = %+v", epochs)
+	}
+	if epochs[1].Accuracy <= 0 || epochs[1].Accuracy > 1 {
+		t.Errorf("training accuracy = %v", epochs[1].Accuracy)
+	}
+	if _, ok := epochs[1].Validation["accuracy"]; !ok {
+		t.Errorf("epoch record has no validation accuracy: %+v", epochs[1])
+	}
+	if epochs[1].Elapsed < epochs[0].Elapsed {
+		t.Errorf("elapsed went backwards: %v then %v", epochs[0].Elapsed, epochs[1].Elapsed)
+	}
+}
+
+func TestMetricsLogEvery(t *testing.T) {
+	data := syntheticDataset(19, 20, 4, 3)
+	network, err := NewNeuralNetwork(4, 3, []int{5}, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	config := TrainingConfig{Epochs: 2, BatchSize: 4, Callbacks: []Callback{&MetricsLog{Out: &out, Every: 3}}}
+	if err := TrainingLoop(context.Background(), network, config, data, nil); err != nil {
+		t.Fatal(err)
+	}
+	records, err := ReadMetrics(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var steps []int
+	for _, record := range records {
+		if record.Type == "step" {
+			steps = append(steps, record.Step)
+		}
+	}
+	// 10 個 step，每 3 個記錄一次
+	if len(steps) != 3 || steps[0] != 3 || steps[2] != 9 {
+		t.Errorf("logged steps %v, want [3 6 9]", steps)
+	}
+	if epochs := EpochRecords(records); len(epochs) != 2 || epochs[0].Validation != nil {
+		t.Errorf("epoch records = %+v", epochs)
+	}
+}
@@ -3,9 +3,16 @@ package nn
 import (
 	"encoding/binary"
 	"fmt"
+	"io"
 	"os"
 )
 
+// IDX 檔案開頭的 magic number
+const (
+	mnistImagesMagic = 2051
+	mnistLabelsMagic = 2049
+)
+
 func ReadMNISTImages(filename string) ([][]byte, error){
 	file, err := os.Open(filename)
 	if err != nil{
@@ -16,7 +23,12 @@ func ReadMNISTImages(filename string) ([][]byte, error){
 	header := make([]byte, 16)
 
 	// 2. 讀取16字節到buffer
-	_, err = file.Read(header)
+	if _, err = io.ReadFull(file, header); err != nil {
+		return nil, fmt.Errorf("failed to read header: %v", err)
+	}
+	if magic := binary.BigEndian.Uint32(header[0:4]); magic != mnistImagesMagic {
+		return nil, fmt.Errorf("%s is not an MNIST image file (magic %d)", filename, magic)
+	}
 
 	// 3. 從header中解析數字 (Big Endian格式)
 	numImages := binary.BigEndian.Uint32(header[4:8])  // 圖片數量
@@ -33,9 +45,9 @@ func ReadMNISTImages(filename string) ([][]byte, error){
 		image := make([]byte, imageSize)
 
 		// 讀取這張圖片的所有像素
-		_, err = file.Read(image)
+		_, err = io.ReadFull(file, image)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read header: %v", err)
+			return nil, fmt.Errorf("failed to read image %d: %v", i, err)
 		}
 		images[i] = image
 	}
@@ -50,12 +62,17 @@ func ReadMNISTLabels(filename string) ([]byte, error){
 	defer file.Close()
 	header := make([]byte, 8)
 
-	_, err = file.Read(header)
+	if _, err = io.ReadFull(file, header); err != nil {
+		return nil, fmt.Errorf("failed to read header: %v", err)
+	}
+	if magic := binary.BigEndian.Uint32(header[0:4]); magic != mnistLabelsMagic {
+		return nil, fmt.Errorf("%s is not an MNIST label file (magic %d)", filename, magic)
+	}
 	numLabels := binary.BigEndian.Uint32(header[4:8])
 
 	labels := make([]byte, numLabels)
 
-	_, err = file.Read(labels)
+	_, err = io.ReadFull(file, labels)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read labels: %v", err)
 	}
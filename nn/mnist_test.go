@@ -0,0 +1,98 @@
+package nn
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeIDX 寫出 MNIST 的 IDX 格式：magic、各維度大小（big endian），接著是資料
+func writeIDX(t *testing.T, path string, magic uint32, dims []uint32, data []byte) {
+	t.Helper()
+	header := make([]byte, 4*(len(dims)+1))
+	binary.BigEndian.PutUint32(header, magic)
+	for i, d := range dims {
+		binary.BigEndian.PutUint32(header[4*(i+1):], d)
+	}
+	if err := os.WriteFile(path, append(header, data...), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMNISTReadersAndCSV(t *testing.T) {
+	dir := t.TempDir()
+	imagesPath := filepath.Join(dir, "images")
+	labelsPath := filepath.Join(dir, "labels")
+	// 3 張 2x2 的圖片
+	pixels := []byte{
+		0, 255, 10, 20,
+		30, 40, 50, 60,
+		255, 0, 0, 128,
+	}
+	writeIDX(t, imagesPath, mnistImagesMagic, []uint32{3, 2, 2}, pixels)
+	writeIDX(t, labelsPath, mnistLabelsMagic, []uint32{3}, []byte{7, 0, 2})
+
+	images, err := ReadMNISTImages(imagesPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(images) != 3 || string(images[1]) != string(pixels[4:8]) {
+		t.Errorf("images = %v", images)
+	}
+	labels, err := ReadMNISTLabels(labelsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(labels) != string([]byte{7, 0, 2}) {
+		t.Errorf("labels = %v", labels)
+	}
+
+	csvPath := filepath.Join(dir, "data.csv")
+	if err := ConvertToCSV(imagesPath, labelsPath, csvPath); err != nil {
+		t.Fatal(err)
+	}
+	data, err := LoadingDataFromCSV(csvPath, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 3 {
+		t.Fatalf("loaded %d samples, want 3", len(data))
+	}
+	if got := data[0].Input.At(1, 0); got != 1 {
+		t.Errorf("pixel 255 loaded as %v, want 1", got)
+	}
+	if label, _ := Argmax(data[0].Target); label != 7 {
+		t.Errorf("first label %d, want 7", label)
+	}
+	if inputs, classes, _ := DatasetShape(data); inputs != 4 || classes != 10 {
+		t.Errorf("dataset shape %d inputs, %d classes", inputs, classes)
+	}
+}
+
+func TestMNISTReadersRejectBadFiles(t *testing.T) {
+	dir := t.TempDir()
+	truncated := filepath.Join(dir, "truncated")
+	writeIDX(t, truncated, mnistImagesMagic, []uint32{2, 2, 2}, []byte{1, 2, 3, 4, 5})
+	if _, err := ReadMNISTImages(truncated); err == nil {
+		t.Error("ReadMNISTImages accepted a truncated file")
+	}
+
+	labels := filepath.Join(dir, "labels")
+	writeIDX(t, labels, mnistLabelsMagic, []uint32{2}, []byte{1, 2})
+	if _, err := ReadMNISTImages(labels); err == nil {
+		t.Error("ReadMNISTImages accepted a label file")
+	}
+	if _, err := ReadMNISTLabels(truncated); err == nil {
+		t.Error("ReadMNISTLabels accepted an image file")
+	}
+
+	short := filepath.Join(dir, "short")
+	writeIDX(t, short, mnistLabelsMagic, []uint32{5}, []byte{1, 2})
+	if _, err := ReadMNISTLabels(short); err == nil {
+		t.Error("ReadMNISTLabels accepted a truncated file")
+	}
+	if _, err := ReadMNISTLabels(filepath.Join(dir, "missing")); err == nil {
+		t.Error("ReadMNISTLabels accepted a missing file")
+	}
+}
@@ -1,9 +1,13 @@
 package nn
 
 import (
+	"fmt"
 	"math"
 	"math/rand"
 
+	"golang-neural-network/internal/numeric"
+
+	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/mat"
 )
 
@@ -14,6 +18,48 @@ type NeuralNetwork struct {
 	OutputWeight *mat.Dense  
     OutputBias   *mat.Dense 
 	LearningRate float64
+	InputShape   ImageShape
+	Output       OutputActivation // 空值代表 softmax
+	Loss         LossConfig       // 空值代表 cross-entropy
+	Precision    Precision        // 空值代表 float64
+	Adversarial  *AdversarialConfig // 對抗訓練的設定，nil 代表沒有經過對抗訓練
+
+	f32   *params32   // Precision 為 float32 時的權重，見 SetPrecision
+	masks *pruneMasks // 剪枝遮罩，見 PruneMagnitude
+}
+
+// ImageShape 描述影像輸入的形狀，輸入向量依通道優先（C x H x W）排列
+type ImageShape struct {
+	Channels int `json:"channels"`
+	Height   int `json:"height"`
+	Width    int `json:"width"`
+}
+
+var (
+	MNISTShape   = ImageShape{Channels: 1, Height: 28, Width: 28}
+	CIFAR10Shape = ImageShape{Channels: 3, Height: 32, Width: 32}
+)
+
+func (s ImageShape) Size() int {
+	return s.Channels * s.Height * s.Width
+}
+
+// ImageShape 回傳模型的影像輸入形狀
+// 舊模型沒有記錄形狀時，從輸入大小推斷（正方形灰階或正方形 RGB）
+func (nn NeuralNetwork) ImageShape() ImageShape {
+	if nn.InputShape.Size() > 0 && nn.InputShape.Size() == nn.Inputs {
+		return nn.InputShape
+	}
+	for _, channels := range []int{1, 3} {
+		if nn.Inputs%channels != 0 {
+			continue
+		}
+		side := int(math.Round(math.Sqrt(float64(nn.Inputs / channels))))
+		if side*side*channels == nn.Inputs {
+			return ImageShape{Channels: channels, Height: side, Width: side}
+		}
+	}
+	return ImageShape{Channels: 1, Height: 1, Width: nn.Inputs}
 }
 
 type HiddenLayer struct{
@@ -47,6 +93,12 @@ func zeroBiasArray(size int) []float64 {
 
 func NewNeuralNetwork(inputs, outputClass int, hiddenNodes []int, learningRate float64) (*NeuralNetwork, error){
 	// Let user define the nn structure 
+	if inputs <= 0 || outputClass <= 0 {
+		return nil, fmt.Errorf("Input size and class count must be positive, got %d and %d", inputs, outputClass)
+	}
+	if len(hiddenNodes) == 0 {
+		return nil, fmt.Errorf("At least one hidden layer is required")
+	}
 	nn := &NeuralNetwork{
 		Inputs: inputs,
 		OutputClass: outputClass,
@@ -79,66 +131,115 @@ func NewNeuralNetwork(inputs, outputClass int, hiddenNodes []int, learningRate f
 }
 
 
-func relu(m *mat.Dense) *mat.Dense{
-	r, c := m.Dims()
-	new_matrix := mat.NewDense(r, c, nil)
-	for i := 0; i < r; i++{
-		for j := 0; j < c; j++ {
-			value := m.At(i, j)
-			new_value := max(value, 0)
-			new_matrix.Set(i, j, new_value)
+// Clone 深度複製網路的權重與設定，修改複本不會影響原本的網路
+func (nn *NeuralNetwork) Clone() *NeuralNetwork {
+	clone := *nn
+	clone.Hidden = make([]HiddenLayer, len(nn.Hidden))
+	for i, layer := range nn.Hidden {
+		clone.Hidden[i] = HiddenLayer{
+			nodenum: layer.nodenum,
+			weight:  mat.DenseCopyOf(layer.weight),
+			bias:    mat.DenseCopyOf(layer.bias),
 		}
 	}
-	return new_matrix
+	clone.OutputWeight = mat.DenseCopyOf(nn.OutputWeight)
+	clone.OutputBias = mat.DenseCopyOf(nn.OutputBias)
+	if nn.Loss.ClassWeights != nil {
+		clone.Loss.ClassWeights = append([]float64(nil), nn.Loss.ClassWeights...)
+	}
+	if nn.Adversarial != nil {
+		adversarial := *nn.Adversarial
+		clone.Adversarial = &adversarial
+	}
+	if nn.f32 != nil {
+		clone.f32 = nn.f32.clone()
+	}
+	if nn.masks != nil {
+		clone.masks = nn.masks.clone()
+	}
+	return &clone
 }
 
-
 func (nn NeuralNetwork) Forward(input *mat.Dense) (*mat.Dense, error) {
+	if r, _ := input.Dims(); r != nn.Inputs {
+		return nil, fmt.Errorf("Input has %d rows, model expects %d", r, nn.Inputs)
+	}
+	if nn.isFloat32() {
+		return nn.forward32(input), nil
+	}
 	current := input
 	// process through hidden layer
 	for _, layer := range nn.Hidden {
+		weighted := affine(layer.weight, layer.bias, current)
 
-		weighted := mat.Dense{}
-		weighted.Mul(layer.weight, current)
-		weighted.Add(&weighted, layer.bias)
+		// activation function（直接在 weighted 的 backing slice 上做 ReLU）
+		reluInPlace(weighted.RawMatrix().Data)
 
-		// activation function
-		activated := relu(&weighted)
-
-		current = activated
+		current = weighted
 	}
 	// process through output layer
-	output := mat.Dense{}
-	output.Mul(nn.OutputWeight, current)
-	output.Add(&output, nn.OutputBias)
+	return affine(nn.OutputWeight, nn.OutputBias, current), nil
+}
 
-	return &output, nil
+// affine 計算 W x + b，x 的每一個 column 為一個樣本
+// 單一樣本時用 gemv，gonum 的 Gemm 在只有一個 column 時會退化成逐元素的 axpy
+func affine(weight *mat.Dense, bias *mat.Dense, x *mat.Dense) *mat.Dense {
+	rows, _ := weight.Dims()
+	_, c := x.Dims()
+	out := mat.NewDense(rows, c, nil)
+	if c == 1 {
+		data := out.RawMatrix().Data
+		copy(data, columnData(bias))
+		gemv(weight, columnData(x), data)
+		return out
+	}
+	out.Mul(weight, x)
+	addBias(out, bias)
+	return out
 }
 
+// addBias 將 bias (n x 1) 加到 m 的每一個 column，輸入可以是單一樣本或一個 batch
+func addBias(m *mat.Dense, bias *mat.Dense) {
+	raw := m.RawMatrix()
+	b := columnData(bias)
+	for i := 0; i < raw.Rows; i++ {
+		row := raw.Data[i*raw.Stride : i*raw.Stride+raw.Cols]
+		floats.AddConst(b[i], row)
+	}
+}
 
+
+// Softmax 對每一個 column（一個樣本）分別做 softmax
+// 每個 column 先減去自己的最大值，避免 exp 溢位
 func Softmax(input *mat.Dense) *mat.Dense {
-	max := input.At(0, 0)
 	r, c := input.Dims()
-	for i := 0; i < r; i++{
-		for j:=0; j < c; j++{
-			max = math.Max(max, input.At(i, j))
-		}
-	}
 	result := mat.NewDense(r, c, nil)
-	sum := 0.0
-	for i := 0; i < r; i++{
-		for j:=0; j < c; j++{
+	for j := 0; j < c; j++ {
+		max := numeric.ColumnMax(input, j)
+		sum := 0.0
+		for i := 0; i < r; i++ {
 			new := math.Exp(input.At(i, j) - max)
 			result.Set(i, j, new)
 			sum += new
 		}
-	}
-	for i := 0; i < r; i++{
-		for j:=0; j < c; j++{
-			new := result.At(i, j) / sum
-			result.Set(i, j, new)
+		for i := 0; i < r; i++ {
+			result.Set(i, j, result.At(i, j)/sum)
 		}
 	}
 	return result
 }
 
+// LogSoftmax 對每一個 column 計算 log(softmax(x))
+// 使用 log-sum-exp：log p_i = (x_i - max) - log Σ exp(x_k - max)，極端 logits 也不會得到 -Inf 或 NaN
+// 先減 max 再減 log(sum)，logits 很大時 max + log(sum) 才不會把 log(sum) 捨入掉
+func LogSoftmax(input *mat.Dense) *mat.Dense {
+	r, c := input.Dims()
+	result := mat.NewDense(r, c, nil)
+	for j := 0; j < c; j++ {
+		max, logSum := numeric.ColumnLogSumExp(input, j)
+		for i := 0; i < r; i++ {
+			result.Set(i, j, (input.At(i, j)-max)-logSum)
+		}
+	}
+	return result
+}
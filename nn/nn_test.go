@@ -0,0 +1,124 @@
+package nn
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// smallNetwork 2-2-2 的網路，權重固定，方便手算
+func smallNetwork() *NeuralNetwork {
+	return &NeuralNetwork{
+		Inputs:      2,
+		OutputClass: 2,
+		Hidden: []HiddenLayer{{
+			nodenum: 2,
+			weight:  mat.NewDense(2, 2, []float64{1, -1, 0.5, 2}),
+			bias:    mat.NewDense(2, 1, []float64{0, -1}),
+		}},
+		OutputWeight: mat.NewDense(2, 2, []float64{1, 0, -1, 3}),
+		OutputBias:   mat.NewDense(2, 1, []float64{0.5, 0}),
+		LearningRate: 0.1,
+	}
+}
+
+func TestForward(t *testing.T) {
+	network := smallNetwork()
+	// hidden = relu([1*1 - 1*2 + 0, 0.5*1 + 2*2 - 1]) = relu([-1, 3.5]) = [0, 3.5]
+	// output = [0 + 0 + 0.5, 0 + 10.5 + 0] = [0.5, 10.5]
+	got, err := network.Forward(mat.NewDense(2, 1, []float64{1, 2}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := mat.NewDense(2, 1, []float64{0.5, 10.5})
+	if !mat.EqualApprox(got, want, 1e-12) {
+		t.Errorf("Forward = %v, want %v", mat.Formatted(got.T()), mat.Formatted(want.T()))
+	}
+}
+
+func TestForwardBatchMatchesSingleSamples(t *testing.T) {
+	network, err := NewNeuralNetwork(5, 3, []int{6, 4}, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := syntheticDataset(8, 4, 5, 3)
+	batch := mat.NewDense(5, len(data), nil)
+	for j, sample := range data {
+		batch.SetCol(j, columnData(sample.Input))
+	}
+	output, err := network.Forward(batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for j, sample := range data {
+		single, err := network.Forward(sample.Input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 3; i++ {
+			if math.Abs(output.At(i, j)-single.At(i, 0)) > 1e-12 {
+				t.Errorf("batch column %d row %d = %v, single sample %v", j, i, output.At(i, j), single.At(i, 0))
+			}
+		}
+	}
+}
+
+func TestForwardRejectsWrongInputSize(t *testing.T) {
+	if _, err := smallNetwork().Forward(mat.NewDense(3, 1, nil)); err == nil {
+		t.Error("Forward accepted a 3x1 input for a 2-input network")
+	}
+}
+
+func TestSoftmax(t *testing.T) {
+	got := Softmax(mat.NewDense(3, 1, []float64{1, 2, 3}))
+	sum := math.Exp(1) + math.Exp(2) + math.Exp(3)
+	for i, z := range []float64{1, 2, 3} {
+		if want := math.Exp(z) / sum; math.Abs(got.At(i, 0)-want) > 1e-12 {
+			t.Errorf("Softmax[%d] = %v, want %v", i, got.At(i, 0), want)
+		}
+	}
+	// 加上常數不改變結果，大數值也不會溢位
+	shifted := Softmax(mat.NewDense(3, 1, []float64{1001, 1002, 1003}))
+	assertFinite(t, "Softmax", shifted)
+	if !mat.EqualApprox(got, shifted, 1e-12) {
+		t.Errorf("Softmax is not shift invariant: %v vs %v", mat.Formatted(got.T()), mat.Formatted(shifted.T()))
+	}
+}
+
+func TestCrossEntropyLoss(t *testing.T) {
+	pred := mat.NewDense(3, 2, []float64{
+		0.7, 0.1,
+		0.2, 0.0,
+		0.1, 0.9,
+	})
+	truth := mat.NewDense(3, 2, []float64{
+		1, 0,
+		0, 1,
+		0, 0,
+	})
+	loss, err := crossEntropyLoss(pred, truth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 第二個樣本的機率為 0，會被截斷在 1e-15
+	want := -math.Log(0.7) - math.Log(1e-15)
+	if math.Abs(loss-want) > 1e-9 {
+		t.Errorf("crossEntropyLoss = %v, want %v", loss, want)
+	}
+}
+
+func TestNewNeuralNetworkValidation(t *testing.T) {
+	for _, tt := range []struct {
+		inputs, classes int
+		hidden          []int
+	}{
+		{0, 10, []int{5}},
+		{784, 0, []int{5}},
+		{784, 10, nil},
+	} {
+		if _, err := NewNeuralNetwork(tt.inputs, tt.classes, tt.hidden, 0.1); err == nil {
+			t.Errorf("NewNeuralNetwork(%d, %d, %v) should fail", tt.inputs, tt.classes, tt.hidden)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package nn
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// occlusionBatch 每次 Forward 的遮蔽影像數
+const occlusionBatch = 256
+
+// OccludedPatch 一個遮蔽位置（左上角 X, Y）與遮蔽後目標類別的機率
+type OccludedPatch struct {
+	X, Y        int
+	Probability float64
+}
+
+// OcclusionMap 遮蔽分析的結果
+type OcclusionMap struct {
+	Class       int
+	Probability float64   // 沒有遮蔽時目標類別的機率
+	Patch       int       // 方塊邊長
+	Drops       []float64 // H x W（row-major），覆蓋該像素的所有方塊造成的平均機率下降，可能為負
+	Patches     []OccludedPatch
+	Shape       ImageShape
+}
+
+// Heatmap 只保留機率下降（負值視為 0）並除以最大值，回傳 H x W 個介於 0 到 1 的值
+func (m OcclusionMap) Heatmap() []float64 {
+	heat := make([]float64, len(m.Drops))
+	peak := 0.0
+	for i, v := range m.Drops {
+		heat[i] = math.Max(v, 0)
+		peak = math.Max(peak, heat[i])
+	}
+	if peak > 0 {
+		for i := range heat {
+			heat[i] /= peak
+		}
+	}
+	return heat
+}
+
+// MostSensitive 機率下降最多的遮蔽位置
+func (m OcclusionMap) MostSensitive() OccludedPatch {
+	best := m.Patches[0]
+	for _, p := range m.Patches[1:] {
+		if p.Probability < best.Probability {
+			best = p
+		}
+	}
+	return best
+}
+
+// occlusionOffsets 方塊左上角的位置，每隔 stride 一個，最後一個一定貼齊邊緣
+func occlusionOffsets(size, patch, stride int) []int {
+	var offsets []int
+	for o := 0; o+patch <= size; o += stride {
+		offsets = append(offsets, o)
+	}
+	if last := size - patch; offsets[len(offsets)-1] != last {
+		offsets = append(offsets, last)
+	}
+	return offsets
+}
+
+// Occlusion 以 patch x patch 的方塊（所有通道填入 fill）每隔 stride 個像素遮住輸入，記錄目標類別機率的下降
+// 不需要梯度，任何輸出頭都適用；遮蔽後的影像每 256 張組成一個 batch 執行 Forward
+// class < 0 時使用模型預測的類別
+func Occlusion(model *NeuralNetwork, input *mat.Dense, class, patch, stride int, fill float64) (OcclusionMap, error) {
+	x, class, err := explainTarget(model, input, class)
+	if err != nil {
+		return OcclusionMap{}, err
+	}
+	shape := model.ImageShape()
+	if patch < 1 || patch > shape.Height || patch > shape.Width {
+		return OcclusionMap{}, fmt.Errorf("Patch size %d does not fit a %dx%d input", patch, shape.Height, shape.Width)
+	}
+	if stride < 1 {
+		return OcclusionMap{}, fmt.Errorf("Stride must be at least 1, got %d", stride)
+	}
+	probs, err := model.Predict(input)
+	if err != nil {
+		return OcclusionMap{}, err
+	}
+	result := OcclusionMap{Class: class, Probability: probs.At(class, 0), Patch: patch, Shape: shape}
+
+	for _, y := range occlusionOffsets(shape.Height, patch, stride) {
+		for _, x := range occlusionOffsets(shape.Width, patch, stride) {
+			result.Patches = append(result.Patches, OccludedPatch{X: x, Y: y})
+		}
+	}
+	plane := shape.Height * shape.Width
+	for start := 0; start < len(result.Patches); start += occlusionBatch {
+		batch := result.Patches[start:min(start+occlusionBatch, len(result.Patches))]
+		occluded := mat.NewDense(len(x), len(batch), nil)
+		for j, p := range batch {
+			occluded.SetCol(j, x)
+			for c := 0; c < shape.Channels; c++ {
+				for dy := 0; dy < patch; dy++ {
+					for dx := 0; dx < patch; dx++ {
+						occluded.Set(c*plane+(p.Y+dy)*shape.Width+p.X+dx, j, fill)
+					}
+				}
+			}
+		}
+		probs, err := model.Predict(occluded)
+		if err != nil {
+			return OcclusionMap{}, err
+		}
+		for j := range batch {
+			batch[j].Probability = probs.At(class, j)
+		}
+	}
+
+	// 每個像素取覆蓋它的所有方塊的平均下降
+	drops := make([]float64, plane)
+	counts := make([]int, plane)
+	for _, p := range result.Patches {
+		drop := result.Probability - p.Probability
+		for dy := 0; dy < patch; dy++ {
+			for dx := 0; dx < patch; dx++ {
+				i := (p.Y+dy)*shape.Width + p.X + dx
+				drops[i] += drop
+				counts[i]++
+			}
+		}
+	}
+	for i := range drops {
+		if counts[i] > 0 {
+			drops[i] /= float64(counts[i])
+		}
+	}
+	result.Drops = drops
+	return result, nil
+}
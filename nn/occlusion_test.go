@@ -0,0 +1,95 @@
+package nn
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestOcclusionOffsets(t *testing.T) {
+	if got := occlusionOffsets(28, 4, 2); len(got) != 13 || got[12] != 24 {
+		t.Errorf("occlusionOffsets(28, 4, 2) = %v", got)
+	}
+	// 步長不整除時最後一個位置貼齊邊緣
+	if got, want := occlusionOffsets(10, 4, 4), []int{0, 4, 6}; !reflect.DeepEqual(got, want) {
+		t.Errorf("occlusionOffsets(10, 4, 4) = %v, want %v", got, want)
+	}
+}
+
+// 分批的結果要和一張一張遮蔽後 Predict 的結果相同，784 個位置會跨過多個 batch
+func TestOcclusionMatchesSingleSamples(t *testing.T) {
+	model, err := NewNeuralNetwork(784, 10, []int{16}, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rng := rand.New(rand.NewSource(2))
+	input := mat.NewDense(784, 1, nil)
+	for i := 0; i < 784; i++ {
+		input.Set(i, 0, rng.Float64())
+	}
+	result, err := Occlusion(model, input, 4, 1, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Patches) != 784 || len(result.Drops) != 784 {
+		t.Fatalf("%d patches, %d drops", len(result.Patches), len(result.Drops))
+	}
+	for _, i := range []int{0, 255, 256, 511, 783} {
+		p := result.Patches[i]
+		occluded := mat.DenseCopyOf(input)
+		occluded.Set(p.Y*28+p.X, 0, 0)
+		probs, err := model.Predict(occluded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(probs.At(4, 0)-p.Probability) > 1e-12 {
+			t.Errorf("patch %d: %v, single sample %v", i, p.Probability, probs.At(4, 0))
+		}
+		// 1x1 方塊時每個像素只被一個方塊覆蓋
+		if drop := result.Probability - p.Probability; math.Abs(result.Drops[p.Y*28+p.X]-drop) > 1e-12 {
+			t.Errorf("pixel %d: drop %v, want %v", i, result.Drops[p.Y*28+p.X], drop)
+		}
+	}
+}
+
+// 只有左上角 2x2 像素影響類別 0 時，遮住其他地方不會改變機率
+func TestOcclusionFindsRelevantPixels(t *testing.T) {
+	model, err := NewNeuralNetwork(16, 2, []int{1}, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	model.Hidden[0].weight.Zero()
+	for _, i := range []int{0, 1, 4, 5} {
+		model.Hidden[0].weight.Set(0, i, 1)
+	}
+	model.Hidden[0].bias.Zero()
+	model.OutputWeight.Copy(mat.NewDense(2, 1, []float64{2, -2}))
+	model.OutputBias.Zero()
+	input := mat.NewDense(16, 1, nil)
+	for i := 0; i < 16; i++ {
+		input.Set(i, 0, 1)
+	}
+	result, err := Occlusion(model, input, -1, 2, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Class != 0 {
+		t.Fatalf("explained class %d", result.Class)
+	}
+	if best := result.MostSensitive(); best.X != 0 || best.Y != 0 {
+		t.Errorf("most sensitive patch at (%d, %d)", best.X, best.Y)
+	}
+	heat := result.Heatmap()
+	for i, v := range heat {
+		relevant := i == 0 || i == 1 || i == 4 || i == 5
+		if relevant && v != 1 || !relevant && v != 0 {
+			t.Errorf("pixel %d: heat %v", i, v)
+		}
+	}
+	if _, err := Occlusion(model, input, 0, 5, 1, 0); err == nil {
+		t.Error("expected an error for a patch larger than the input")
+	}
+}
@@ -0,0 +1,217 @@
+package nn
+
+import (
+	"fmt"
+	"sync"
+)
+
+// gradientShardSize 每個梯度分片包含的樣本數
+// 分片的切法只跟 batch 大小有關、跟 worker 數量無關，分片的梯度再依分片順序相加，
+// 所以不論用幾個 worker，浮點數的累加順序都相同，結果和單執行緒訓練完全一致
+const gradientShardSize = 8
+
+// parallelTrainer 資料平行訓練：把一個 mini-batch 切成分片，交給多個 worker goroutine 計算梯度
+// 每個 worker 有自己的 workspace（forward/backward buffer），所有分片完成後才合併梯度並更新一次權重，
+// backward 期間權重不會被修改
+//
+// float32 網路使用同樣的分片與合併順序，只是 buffer 換成 float32 版本
+type parallelTrainer struct {
+	nn       *NeuralNetwork
+	workers  int
+	float32  bool
+	autodiff bool        // 分片的梯度改由 autodiffGradients 計算
+	verify   bool        // 下一次 step 先確認手寫的梯度與 Graph 的梯度一致
+	classify bool        // softmax 分類模型，順便計算訓練 accuracy
+	state    workerState // 單執行緒時使用
+
+	shards    []*gradients // 每個分片自己的梯度 buffer，依分片順序合併
+	shards32  []*params32
+	losses    []float64
+	corrects  []int // 每個分片中預測正確的樣本數（只在 classify 時計算）
+	errs      []error
+	total     *gradients
+	total32   *params32
+	gradNorm  float64 // 最近一次 step 平均梯度的 L2 norm
+	correct   int     // 最近一次 step 預測正確的樣本數
+	batchSize int     // 最近一次 step 的樣本數，total / total32 除以它即為平均梯度
+
+	jobs chan shardJob
+	wg   sync.WaitGroup
+}
+
+// workerState 每個 worker 自己的 forward/backward buffer
+type workerState struct {
+	ws   *workspace
+	ws32 *workspace32
+}
+
+func (t *parallelTrainer) newWorkerState() workerState {
+	if t.float32 {
+		return workerState{ws32: newWorkspace32(t.nn.f32, t.nn.Inputs)}
+	}
+	return workerState{ws: newWorkspace(t.nn)}
+}
+
+type shardJob struct {
+	index   int
+	samples []TrainingData
+}
+
+func newParallelTrainer(nn *NeuralNetwork, workers int, batchSize int) *parallelTrainer {
+	workers = max(workers, 1)
+	shardCount := (batchSize + gradientShardSize - 1) / gradientShardSize
+	t := &parallelTrainer{
+		nn:       nn,
+		workers:  workers,
+		float32:  nn.isFloat32(),
+		classify: nn.IsClassifier(),
+	}
+	if t.float32 {
+		t.total32 = nn.f32.zeroLike()
+	} else {
+		t.total = newGradients(nn)
+	}
+	t.state = t.newWorkerState()
+	t.growShards(shardCount)
+	if workers > 1 {
+		t.jobs = make(chan shardJob)
+		for w := 0; w < workers; w++ {
+			go t.worker(t.newWorkerState())
+		}
+	}
+	return t
+}
+
+// growShards 確保至少有 n 個分片的梯度 buffer
+func (t *parallelTrainer) growShards(n int) {
+	for len(t.losses) < n {
+		if t.float32 {
+			t.shards32 = append(t.shards32, t.nn.f32.zeroLike())
+		} else {
+			t.shards = append(t.shards, newGradients(t.nn))
+		}
+		t.losses = append(t.losses, 0)
+		t.corrects = append(t.corrects, 0)
+		t.errs = append(t.errs, nil)
+	}
+}
+
+func (t *parallelTrainer) worker(state workerState) {
+	for job := range t.jobs {
+		t.runShard(job, state)
+		t.wg.Done()
+	}
+}
+
+// runShard 計算一個分片內所有樣本的梯度總和
+func (t *parallelTrainer) runShard(job shardJob, state workerState) {
+	if t.float32 {
+		t.shards32[job.index].zero()
+	} else {
+		t.shards[job.index].zero()
+	}
+	if t.autodiff {
+		t.losses[job.index], t.corrects[job.index], t.errs[job.index] = t.nn.autodiffGradients(job.samples, t.shards[job.index])
+		return
+	}
+	lossSum, correct := 0.0, 0
+	for _, sample := range job.samples {
+		var loss float64
+		var err error
+		var logits []float64
+		if t.float32 {
+			loss, err = t.nn.sampleGradients32(state.ws32, sample.Input, sample.Target, t.shards32[job.index])
+			logits = state.ws32.logitsMat.RawMatrix().Data
+		} else {
+			loss, err = t.nn.sampleGradients(state.ws, sample.Input, sample.Target, t.shards[job.index])
+			logits = state.ws.logitsMat.RawMatrix().Data
+		}
+		if err != nil {
+			t.errs[job.index] = err
+			return
+		}
+		lossSum += loss
+		if t.classify && argmax(logits) == argmax(columnData(sample.Target)) {
+			correct++
+		}
+	}
+	t.losses[job.index] = lossSum
+	t.corrects[job.index] = correct
+	t.errs[job.index] = nil
+}
+
+// step 用一個 mini-batch 更新一次權重（梯度取 batch 平均），回傳 batch 的 loss 總和
+func (t *parallelTrainer) step(batch []TrainingData) (float64, error) {
+	shardCount := (len(batch) + gradientShardSize - 1) / gradientShardSize
+	t.growShards(shardCount)
+	t.batchSize = 0
+
+	for k := 0; k < shardCount; k++ {
+		job := shardJob{index: k, samples: batch[k*gradientShardSize : min((k+1)*gradientShardSize, len(batch))]}
+		if t.workers > 1 {
+			t.wg.Add(1)
+			t.jobs <- job
+		} else {
+			t.runShard(job, t.state)
+		}
+	}
+	t.wg.Wait()
+
+	// 依分片順序合併，確保結果與 worker 數量無關
+	lossSum := 0.0
+	t.correct = 0
+	for k := 0; k < shardCount; k++ {
+		if t.errs[k] != nil {
+			return 0, t.errs[k]
+		}
+		lossSum += t.losses[k]
+		t.correct += t.corrects[k]
+	}
+	if t.float32 {
+		t.total32.zero()
+		for k := 0; k < shardCount; k++ {
+			t.total32.add(t.shards32[k])
+		}
+		t.gradNorm, t.batchSize = t.total32.norm()/float64(len(batch)), len(batch)
+		t.nn.f32.axpy(float32(-t.nn.LearningRate/float64(len(batch))), t.total32)
+		t.nn.applyMasks()
+		return lossSum, nil
+	}
+	t.total.zero()
+	for k := 0; k < shardCount; k++ {
+		t.total.add(t.shards[k])
+	}
+	if t.verify {
+		t.verify = false
+		if err := t.verifyHandwritten(batch); err != nil {
+			return 0, err
+		}
+	}
+	t.gradNorm, t.batchSize = t.total.norm()/float64(len(batch)), len(batch)
+	t.nn.applyGradients(t.total, 1/float64(len(batch)))
+	t.nn.applyMasks()
+	return lossSum, nil
+}
+
+// handwrittenTolerance 手寫與 Graph 的梯度之間允許的最大差距（相對於梯度的大小，至少以 1 計）
+const handwrittenTolerance = 1e-6
+
+// verifyHandwritten 以 Graph 重新計算 batch 的梯度總和，與 t.total（手寫 backward 的結果）比較
+// 在更新權重之前呼叫，兩者用的是同一組權重
+func (t *parallelTrainer) verifyHandwritten(batch []TrainingData) error {
+	want := newGradients(t.nn)
+	if _, _, err := t.nn.autodiffGradients(batch, want); err != nil {
+		return err
+	}
+	if diff := t.total.maxDiff(want); diff > handwrittenTolerance*max(1, want.maxAbs()) {
+		return fmt.Errorf("Hand-written backward pass differs from the autodiff graph by %g", diff)
+	}
+	return nil
+}
+
+// close 結束所有 worker goroutine
+func (t *parallelTrainer) close() {
+	if t.jobs != nil {
+		close(t.jobs)
+	}
+}
@@ -0,0 +1,89 @@
+package nn
+
+import (
+	"fmt"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Parameter 一個參數（或梯度）矩陣的複本，Data 以 row-major 排列
+// 名稱依層命名，例如 "hidden0/weight"、"hidden0/bias"、"output/weight"
+type Parameter struct {
+	Name       string
+	Rows, Cols int
+	Data       []float64
+}
+
+func layerName(i, hidden int) string {
+	if i == hidden {
+		return "output"
+	}
+	return fmt.Sprintf("hidden%d", i)
+}
+
+func denseParameter(name string, m *mat.Dense, scale float64) Parameter {
+	r, c := m.Dims()
+	data := make([]float64, r*c)
+	for i, v := range m.RawMatrix().Data {
+		data[i] = v * scale
+	}
+	return Parameter{Name: name, Rows: r, Cols: c, Data: data}
+}
+
+// parameters 依層的順序複製所有矩陣並乘上 scale
+func (g *gradients) parameters(scale float64) []Parameter {
+	hidden := len(g.hiddenWeights)
+	params := make([]Parameter, 0, 2*(hidden+1))
+	for i := range g.hiddenWeights {
+		name := layerName(i, hidden)
+		params = append(params, denseParameter(name+"/weight", g.hiddenWeights[i], scale), denseParameter(name+"/bias", g.hiddenBiases[i], scale))
+	}
+	name := layerName(hidden, hidden)
+	return append(params, denseParameter(name+"/weight", g.outputWeight, scale), denseParameter(name+"/bias", g.outputBias, scale))
+}
+
+func (p *params32) parameters(scale float64) []Parameter {
+	convert := func(name string, rows, cols int, values []float32) Parameter {
+		data := make([]float64, len(values))
+		for i, v := range values {
+			data[i] = float64(v) * scale
+		}
+		return Parameter{Name: name, Rows: rows, Cols: cols, Data: data}
+	}
+	layers := p.layers()
+	params := make([]Parameter, 0, 2*len(layers))
+	for i, d := range layers {
+		name := layerName(i, len(layers)-1)
+		params = append(params, convert(name+"/weight", d.rows, d.cols, d.weight), convert(name+"/bias", d.rows, 1, d.bias))
+	}
+	return params
+}
+
+// Parameters 回傳所有參數的複本，順序為 hidden0/weight, hidden0/bias, ..., output/weight, output/bias
+// float32 模型回傳目前的 float32 權重（訓練中的 float64 矩陣要到 epoch 結束才會同步）
+func (nn *NeuralNetwork) Parameters() []Parameter {
+	if nn.isFloat32() {
+		return nn.f32.parameters(1)
+	}
+	// 參數與梯度的形狀相同，直接借用 gradients 的排列
+	view := &gradients{outputWeight: nn.OutputWeight, outputBias: nn.OutputBias}
+	for _, layer := range nn.Hidden {
+		view.hiddenWeights = append(view.hiddenWeights, layer.weight)
+		view.hiddenBiases = append(view.hiddenBiases, layer.bias)
+	}
+	return view.parameters(1)
+}
+
+// Gradients 最近一個 batch 的平均梯度（複本），順序與名稱同 Parameters；還沒有任何 step 時為 nil
+// 每次呼叫都會複製所有梯度，需要時（例如每 N 個 step）才呼叫
+func (s *TrainingState) Gradients() []Parameter {
+	t := s.trainer
+	if t == nil || t.batchSize == 0 {
+		return nil
+	}
+	scale := 1 / float64(t.batchSize)
+	if t.float32 {
+		return t.total32.parameters(scale)
+	}
+	return t.total.parameters(scale)
+}
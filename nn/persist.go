@@ -12,14 +12,26 @@ type SerializableModel struct {
     Inputs       int           `json:"inputs"`
     OutputClass  int           `json:"output_class"`
     HiddenLayers []SerializableLayer `json:"hidden_layers"`
-    OutputWeight [][]float64   `json:"output_weight"`
-    OutputBias   [][]float64   `json:"output_bias"`
+    OutputWeight [][]float64   `json:"output_weight,omitempty"`
+    OutputBias   [][]float64   `json:"output_bias,omitempty"`
     LearningRate float64       `json:"learning_rate"`
+    InputShape   *ImageShape   `json:"input_shape,omitempty"`
+    Output       OutputActivation `json:"output,omitempty"`
+    Loss         *LossConfig   `json:"loss,omitempty"`
+    Precision    Precision     `json:"precision,omitempty"`
+    Pruned       bool          `json:"pruned,omitempty"` // 值為 0 的權重在 fine-tuning 時維持為 0
+    Adversarial  *AdversarialConfig `json:"adversarial,omitempty"` // 對抗訓練使用的攻擊設定
+
+    // float32 模型的權重（Precision 為 float32 時取代上面的 float64 欄位）
+    OutputWeight32 [][]float32 `json:"output_weight_f32,omitempty"`
+    OutputBias32   [][]float32 `json:"output_bias_f32,omitempty"`
 }
 
 type SerializableLayer struct {
-    Weight [][]float64 `json:"weight"`
-    Bias   [][]float64 `json:"bias"`
+    Weight [][]float64 `json:"weight,omitempty"`
+    Bias   [][]float64 `json:"bias,omitempty"`
+    Weight32 [][]float32 `json:"weight_f32,omitempty"`
+    Bias32   [][]float32 `json:"bias_f32,omitempty"`
 }
 
 
@@ -61,10 +73,42 @@ func sliceToDense(data [][]float64) (*mat.Dense, error) {
 
 
 
+// dense32ToSlices 將 float32 的層轉成 [][]float32（weight 與 n x 1 的 bias）
+func dense32ToSlices(d dense32) ([][]float32, [][]float32) {
+	weight := make([][]float32, d.rows)
+	bias := make([][]float32, d.rows)
+	for i := 0; i < d.rows; i++ {
+		weight[i] = append([]float32(nil), d.weight[i*d.cols:(i+1)*d.cols]...)
+		bias[i] = []float32{d.bias[i]}
+	}
+	return weight, bias
+}
+
+// sliceToDenseEither float32 的資料存在時優先使用，否則讀 float64 的資料
+func sliceToDenseEither(data [][]float64, data32 [][]float32) (*mat.Dense, error) {
+	if len(data32) == 0 {
+		return sliceToDense(data)
+	}
+	converted := make([][]float64, len(data32))
+	for i, row := range data32 {
+		converted[i] = make([]float64, len(row))
+		for j, v := range row {
+			converted[i][j] = float64(v)
+		}
+	}
+	return sliceToDense(converted)
+}
+
+// SaveModel 儲存模型，float32 模型的權重以 float32 寫入（檔案約小一半）
 func SaveModel(nn *NeuralNetwork, filepath string) error {
 
 	hiddenLayers := make([]SerializableLayer, len(nn.Hidden))
 	for i :=0; i<len(nn.Hidden); i++{
+		if nn.isFloat32() {
+			weight, bias := dense32ToSlices(nn.f32.hidden[i])
+			hiddenLayers[i] = SerializableLayer{Weight32: weight, Bias32: bias}
+			continue
+		}
 		hiddenLayers[i] = SerializableLayer{
 			Weight: denseToSlice(nn.Hidden[i].weight),
 			Bias: denseToSlice(nn.Hidden[i].bias),
@@ -75,10 +119,24 @@ func SaveModel(nn *NeuralNetwork, filepath string) error {
 		Inputs:       nn.Inputs,
         OutputClass:  nn.OutputClass,
         LearningRate: nn.LearningRate,
-		OutputWeight: denseToSlice(nn.OutputWeight),
-		OutputBias: denseToSlice(nn.OutputBias),
 		HiddenLayers: hiddenLayers,
 	}
+	if nn.isFloat32() {
+		serializableModel.Precision = Float32
+		serializableModel.OutputWeight32, serializableModel.OutputBias32 = dense32ToSlices(nn.f32.output)
+	} else {
+		serializableModel.OutputWeight = denseToSlice(nn.OutputWeight)
+		serializableModel.OutputBias = denseToSlice(nn.OutputBias)
+	}
+	if nn.InputShape.Size() > 0 {
+		shape := nn.InputShape
+		serializableModel.InputShape = &shape
+	}
+	serializableModel.Output = nn.outputActivation()
+	serializableModel.Pruned = nn.masks != nil
+	serializableModel.Adversarial = nn.Adversarial
+	loss := nn.lossConfig()
+	serializableModel.Loss = &loss
 
 	jsonData, err := json.MarshalIndent(serializableModel, "", "	")
 	if err != nil {
@@ -109,11 +167,11 @@ func LoadModel(filename string) (*NeuralNetwork, error){
 
 	// 轉換 SerializableModel → NeuralNetwork
 	// 轉換 OutputWeight 和 OutputBias
-	outputWeight, err := sliceToDense(serializableModel.OutputWeight)
+	outputWeight, err := sliceToDenseEither(serializableModel.OutputWeight, serializableModel.OutputWeight32)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert OutputWeight: %w", err)
 	}
-	outputBias, err := sliceToDense(serializableModel.OutputBias)
+	outputBias, err := sliceToDenseEither(serializableModel.OutputBias, serializableModel.OutputBias32)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert OutputBias: %w", err)
 	}
@@ -121,18 +179,18 @@ func LoadModel(filename string) (*NeuralNetwork, error){
 	// 轉換 Hidden layers
 	hiddenLayers := make([]HiddenLayer, len(serializableModel.HiddenLayers))
 	for i, serLayer := range serializableModel.HiddenLayers {
-		weight, err := sliceToDense(serLayer.Weight)
+		weight, err := sliceToDenseEither(serLayer.Weight, serLayer.Weight32)
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert hidden layer %d weight: %w", i, err)
 		}
 
-		bias, err := sliceToDense(serLayer.Bias)
+		bias, err := sliceToDenseEither(serLayer.Bias, serLayer.Bias32)
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert hidden layer %d bias: %w", i, err)
 		}
 
 		hiddenLayers[i] = HiddenLayer{
-			nodenum: len(serLayer.Weight),
+			nodenum: weight.RawMatrix().Rows,
 			weight: weight,
 			bias:   bias,
 		}
@@ -147,6 +205,20 @@ func LoadModel(filename string) (*NeuralNetwork, error){
 		OutputBias:   outputBias,
 		LearningRate: serializableModel.LearningRate,
 	}
+	if serializableModel.InputShape != nil {
+		nn.InputShape = *serializableModel.InputShape
+	}
+	nn.Output = serializableModel.Output
+	if serializableModel.Loss != nil {
+		nn.Loss = *serializableModel.Loss
+	}
+	nn.Adversarial = serializableModel.Adversarial
+	if serializableModel.Pruned {
+		nn.masksFromZeros()
+	}
+	if err := nn.SetPrecision(serializableModel.Precision); err != nil {
+		return nil, err
+	}
 
 	return nn, nil
 }
\ No newline at end of file
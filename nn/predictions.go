@@ -0,0 +1,84 @@
+package nn
+
+import (
+	"fmt"
+	"sort"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// predictionBatch Predictions 每次一起 forward 的樣本數
+const predictionBatch = 256
+
+// Prediction 單一樣本的分類結果
+type Prediction struct {
+	Index      int     // 樣本在資料集中的位置
+	Label      int     // 正確類別
+	Predicted  int     // 預測類別
+	Confidence float64 // 預測類別的機率
+	LabelProb  float64 // 正確類別的機率
+}
+
+// Correct 是否分類正確
+func (p Prediction) Correct() bool {
+	return p.Label == p.Predicted
+}
+
+// Predictions 對資料集的每個樣本做分類（只支援 softmax 分類模型），樣本以 predictionBatch 筆為一批 forward
+func Predictions(nn *NeuralNetwork, data []TrainingData) ([]Prediction, error) {
+	if !nn.IsClassifier() {
+		return nil, fmt.Errorf("Predictions requires a softmax classifier, model output is %s", nn.outputActivation())
+	}
+	predictions := make([]Prediction, 0, len(data))
+	for start := 0; start < len(data); start += predictionBatch {
+		batch := data[start:min(start+predictionBatch, len(data))]
+		input := mat.NewDense(nn.Inputs, len(batch), nil)
+		for j, sample := range batch {
+			if r, c := sample.Input.Dims(); r != nn.Inputs || c != 1 {
+				return nil, fmt.Errorf("Input %d is %dx%d, model expects %dx1", start+j, r, c, nn.Inputs)
+			}
+			input.SetCol(j, columnData(sample.Input))
+		}
+		probs, err := nn.Predict(input)
+		if err != nil {
+			return nil, err
+		}
+		for j, predicted := range ArgmaxColumns(probs) {
+			label := argmax(columnData(batch[j].Target))
+			predictions = append(predictions, Prediction{
+				Index:      start + j,
+				Label:      label,
+				Predicted:  predicted,
+				Confidence: probs.At(predicted, j),
+				LabelProb:  probs.At(label, j),
+			})
+		}
+	}
+	return predictions, nil
+}
+
+// ConfusionMatrix counts[label][predicted]
+func ConfusionMatrix(predictions []Prediction, classes int) [][]int {
+	counts := make([][]int, classes)
+	for i := range counts {
+		counts[i] = make([]int, classes)
+	}
+	for _, p := range predictions {
+		if p.Label < classes && p.Predicted < classes {
+			counts[p.Label][p.Predicted]++
+		}
+	}
+	return counts
+}
+
+// WorstMisclassified 依預測類別的機率由高到低，回傳最多 k 個分類錯誤的樣本（最有信心的錯誤）
+func WorstMisclassified(predictions []Prediction, k int) []Prediction {
+	var wrong []Prediction
+	for _, p := range predictions {
+		if !p.Correct() {
+			wrong = append(wrong, p)
+		}
+	}
+	sort.SliceStable(wrong, func(i, j int) bool { return wrong[i].Confidence > wrong[j].Confidence })
+	return wrong[:min(k, len(wrong))]
+}
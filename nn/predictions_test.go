@@ -0,0 +1,69 @@
+package nn
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestPredictions(t *testing.T) {
+	network := smallNetwork()
+	data := syntheticDataset(23, 300, network.Inputs, network.OutputClass)
+	predictions, err := Predictions(network, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(predictions) != len(data) {
+		t.Fatalf("got %d predictions, want %d", len(predictions), len(data))
+	}
+	// 與逐筆 Predict 的結果一致（跨過 predictionBatch 的邊界）
+	for _, i := range []int{0, predictionBatch - 1, predictionBatch, len(data) - 1} {
+		probs, err := network.Predict(data[i].Input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		predicted, _ := Argmax(probs)
+		label, _ := Argmax(data[i].Target)
+		p := predictions[i]
+		if p.Index != i || p.Predicted != predicted || p.Label != label || math.Abs(p.Confidence-probs.At(predicted, 0)) > 1e-12 {
+			t.Errorf("prediction %d = %+v, want predicted %d label %d", i, p, predicted, label)
+		}
+	}
+
+	confusion := ConfusionMatrix(predictions, network.OutputClass)
+	total, correct := 0, 0
+	for i, row := range confusion {
+		for j, n := range row {
+			total += n
+			if i == j {
+				correct += n
+			}
+		}
+	}
+	metrics, err := Evaluate(network, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != len(data) || math.Abs(float64(correct)/float64(total)-metrics["accuracy"]) > 1e-12 {
+		t.Errorf("confusion matrix accuracy %d/%d, Evaluate accuracy %v", correct, total, metrics["accuracy"])
+	}
+
+	worst := WorstMisclassified(predictions, 5)
+	for i, p := range worst {
+		if p.Correct() || (i > 0 && p.Confidence > worst[i-1].Confidence) {
+			t.Errorf("worst[%d] = %+v", i, p)
+		}
+	}
+}
+
+func TestPredictionsRequiresClassifier(t *testing.T) {
+	network := smallNetwork()
+	if err := network.SetOutputHead(OutputLinear, LossConfig{Type: LossMSE}); err != nil {
+		t.Fatal(err)
+	}
+	data := []TrainingData{{Input: mat.NewDense(network.Inputs, 1, nil), Target: mat.NewDense(network.OutputClass, 1, nil)}}
+	if _, err := Predictions(network, data); err == nil {
+		t.Error("expected an error for a regression model")
+	}
+}
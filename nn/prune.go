@@ -0,0 +1,252 @@
+package nn
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// PruneScope 非結構化剪枝時，門檻是對所有層一起計算還是每一層各自計算
+type PruneScope string
+
+const (
+	PruneGlobal   PruneScope = "global" // 所有權重一起排序，較不重要的層會被剪得比較多
+	PrunePerLayer PruneScope = "layer"  // 每一層都剪掉相同比例
+)
+
+// pruneMasks 剪枝後的權重遮罩，1 代表保留、0 代表剪掉
+// 每個 slice 與對應權重矩陣的 raw data 對齊；網路沒有剪枝時 masks 為 nil
+// 訓練時每次更新權重後都會重新套用，讓被剪掉的權重在 fine-tuning 時維持為 0
+type pruneMasks struct {
+	hidden [][]float64
+	output []float64
+}
+
+func (m *pruneMasks) clone() *pruneMasks {
+	c := &pruneMasks{hidden: make([][]float64, len(m.hidden)), output: append([]float64(nil), m.output...)}
+	for i, mask := range m.hidden {
+		c.hidden[i] = append([]float64(nil), mask...)
+	}
+	return c
+}
+
+// layers 依序回傳所有遮罩（隱藏層 + 輸出層），與 weightData 的順序相同
+func (m *pruneMasks) layers() [][]float64 {
+	return append(append([][]float64(nil), m.hidden...), m.output)
+}
+
+// weightData 依序回傳所有權重矩陣的 raw data（不含 bias）
+func (nn *NeuralNetwork) weightData() [][]float64 {
+	data := make([][]float64, 0, len(nn.Hidden)+1)
+	for _, layer := range nn.Hidden {
+		data = append(data, layer.weight.RawMatrix().Data)
+	}
+	return append(data, nn.OutputWeight.RawMatrix().Data)
+}
+
+// ensureMasks 第一次剪枝時建立全為 1 的遮罩
+func (nn *NeuralNetwork) ensureMasks() {
+	if nn.masks != nil {
+		return
+	}
+	ones := func(n int) []float64 {
+		mask := make([]float64, n)
+		for i := range mask {
+			mask[i] = 1
+		}
+		return mask
+	}
+	nn.masks = &pruneMasks{hidden: make([][]float64, len(nn.Hidden))}
+	for i, layer := range nn.Hidden {
+		nn.masks.hidden[i] = ones(len(layer.weight.RawMatrix().Data))
+	}
+	nn.masks.output = ones(len(nn.OutputWeight.RawMatrix().Data))
+}
+
+// masksFromZeros 讀取剪枝過的模型時，由值為 0 的權重重建遮罩
+func (nn *NeuralNetwork) masksFromZeros() {
+	nn.masks = nil
+	nn.ensureMasks()
+	masks := nn.masks.layers()
+	for l, weight := range nn.weightData() {
+		for i, w := range weight {
+			if w == 0 {
+				masks[l][i] = 0
+			}
+		}
+	}
+}
+
+// applyMasks 把被剪掉的權重設回 0（float32 模型同時處理 float32 權重）
+func (nn *NeuralNetwork) applyMasks() {
+	if nn.masks == nil {
+		return
+	}
+	masks := nn.masks.layers()
+	for l, weight := range nn.weightData() {
+		floats.Mul(weight, masks[l])
+	}
+	if nn.isFloat32() {
+		for l, d := range nn.f32.layers() {
+			for i, keep := range masks[l] {
+				if keep == 0 {
+					d.weight[i] = 0
+				}
+			}
+		}
+	}
+}
+
+// PruneMagnitude 非結構化剪枝：把絕對值最小的權重設為 0，直到權重的稀疏度達到 sparsity
+// 已經被剪掉的權重也計入稀疏度，所以可以逐步提高 sparsity 重複呼叫；bias 不會被剪枝
+func (nn *NeuralNetwork) PruneMagnitude(sparsity float64, scope PruneScope) error {
+	if sparsity < 0 || sparsity >= 1 {
+		return fmt.Errorf("Sparsity must be in [0, 1), got %v", sparsity)
+	}
+	nn.syncFromFloat32()
+	nn.ensureMasks()
+	weights, masks := nn.weightData(), nn.masks.layers()
+
+	switch scope {
+	case PruneGlobal:
+		pruneSmallest(weights, masks, sparsity)
+	case PrunePerLayer:
+		for l := range weights {
+			pruneSmallest(weights[l:l+1], masks[l:l+1], sparsity)
+		}
+	default:
+		return fmt.Errorf("Unknown prune scope %q", scope)
+	}
+	nn.applyMasks()
+	nn.refreshFloat32()
+	return nil
+}
+
+// pruneSmallest 在 weights 中把絕對值最小的 sparsity 比例的權重的遮罩設為 0
+func pruneSmallest(weights, masks [][]float64, sparsity float64) {
+	type position struct{ layer, index int }
+	var positions []position
+	for l, weight := range weights {
+		for i := range weight {
+			positions = append(positions, position{l, i})
+		}
+	}
+	magnitude := func(p position) float64 {
+		if masks[p.layer][p.index] == 0 {
+			return -1 // 已剪掉的權重排在最前面
+		}
+		return math.Abs(weights[p.layer][p.index])
+	}
+	sort.SliceStable(positions, func(a, b int) bool {
+		return magnitude(positions[a]) < magnitude(positions[b])
+	})
+	count := int(math.Round(sparsity * float64(len(positions))))
+	for _, p := range positions[:count] {
+		masks[p.layer][p.index] = 0
+	}
+}
+
+// PruneNeurons 結構化剪枝：每個隱藏層移除 fraction 比例的神經元，直接縮小層的形狀
+// 神經元的重要性為輸入權重（weight 的 row）與輸出權重（下一層 weight 的 column）L2 norm 的乘積，
+// 移除神經元時一併刪除下一層對應的輸入 column；每層至少保留一個神經元
+func (nn *NeuralNetwork) PruneNeurons(fraction float64) error {
+	if fraction < 0 || fraction >= 1 {
+		return fmt.Errorf("Fraction must be in [0, 1), got %v", fraction)
+	}
+	nn.syncFromFloat32()
+	for i := range nn.Hidden {
+		nn.pruneLayerNeurons(i, fraction)
+	}
+	nn.refreshFloat32()
+	return nil
+}
+
+func (nn *NeuralNetwork) pruneLayerNeurons(i int, fraction float64) {
+	layer := &nn.Hidden[i]
+	next := &nn.OutputWeight
+	var nextMask *[]float64
+	if nn.masks != nil {
+		nextMask = &nn.masks.output
+	}
+	if i+1 < len(nn.Hidden) {
+		next = &nn.Hidden[i+1].weight
+		if nn.masks != nil {
+			nextMask = &nn.masks.hidden[i+1]
+		}
+	}
+
+	rows, cols := layer.weight.Dims()
+	remove := min(int(math.Round(fraction*float64(rows))), rows-1)
+	if remove <= 0 {
+		return
+	}
+	scores := make([]float64, rows)
+	for j := 0; j < rows; j++ {
+		incoming := floats.Norm(layer.weight.RawRowView(j), 2)
+		outgoing := mat.Norm((*next).ColView(j), 2)
+		scores[j] = incoming * outgoing
+	}
+	order := make([]int, rows)
+	for j := range order {
+		order[j] = j
+	}
+	sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] < scores[order[b]] })
+	keep := order[remove:]
+	sort.Ints(keep)
+
+	nextRows, _ := (*next).Dims()
+	weight := mat.NewDense(len(keep), cols, nil)
+	bias := mat.NewDense(len(keep), 1, nil)
+	nextWeight := mat.NewDense(nextRows, len(keep), nil)
+	for k, j := range keep {
+		weight.SetRow(k, layer.weight.RawRowView(j))
+		bias.Set(k, 0, layer.bias.At(j, 0))
+		nextWeight.SetCol(k, mat.Col(nil, j, *next))
+	}
+
+	if nn.masks != nil {
+		mask := make([]float64, 0, len(keep)*cols)
+		for _, j := range keep {
+			mask = append(mask, nn.masks.hidden[i][j*cols:(j+1)*cols]...)
+		}
+		nn.masks.hidden[i] = mask
+		shrunk := make([]float64, 0, nextRows*len(keep))
+		for r := 0; r < nextRows; r++ {
+			for _, j := range keep {
+				shrunk = append(shrunk, (*nextMask)[r*rows+j])
+			}
+		}
+		*nextMask = shrunk
+	}
+	*layer = HiddenLayer{nodenum: len(keep), weight: weight, bias: bias}
+	*next = nextWeight
+}
+
+// Sparsity 權重（不含 bias）中值為 0 的比例
+func (nn NeuralNetwork) Sparsity() float64 {
+	zeros, total := 0, 0
+	for _, weight := range nn.weightData() {
+		for _, w := range weight {
+			if w == 0 {
+				zeros++
+			}
+		}
+		total += len(weight)
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(zeros) / float64(total)
+}
+
+// ParameterCount 權重與 bias 的總數
+func (nn NeuralNetwork) ParameterCount() int {
+	count := 0
+	for _, layer := range nn.Hidden {
+		count += len(layer.weight.RawMatrix().Data) + len(layer.bias.RawMatrix().Data)
+	}
+	return count + len(nn.OutputWeight.RawMatrix().Data) + len(nn.OutputBias.RawMatrix().Data)
+}
@@ -0,0 +1,136 @@
+package nn
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestPruneMagnitudeKeepsZerosDuringFineTuning(t *testing.T) {
+	data := learnableDataset(5, 300, 16, 4)
+	for _, precision := range []Precision{Float64, Float32} {
+		for _, scope := range []PruneScope{PruneGlobal, PrunePerLayer} {
+			network, err := NewNeuralNetwork(16, 4, []int{24, 12}, 0.05)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := network.SetPrecision(precision); err != nil {
+				t.Fatal(err)
+			}
+			if err := network.PruneMagnitude(0.6, scope); err != nil {
+				t.Fatal(err)
+			}
+			if s := network.Sparsity(); math.Abs(s-0.6) > 0.01 {
+				t.Errorf("%s/%s: sparsity %.3f after pruning, want 0.6", precision, scope, s)
+			}
+			if scope == PrunePerLayer {
+				for l, weight := range network.weightData() {
+					zeros := 0
+					for _, w := range weight {
+						if w == 0 {
+							zeros++
+						}
+					}
+					if s := float64(zeros) / float64(len(weight)); math.Abs(s-0.6) > 0.02 {
+						t.Errorf("layer %d sparsity %.3f, want 0.6", l, s)
+					}
+				}
+			}
+			pruned := network.Clone()
+
+			if err := TrainingLoop(context.Background(), network, TrainingConfig{Epochs: 2, BatchSize: 8}, data, data[:10]); err != nil {
+				t.Fatal(err)
+			}
+			before, after := pruned.weightData(), network.weightData()
+			for l := range before {
+				for i, w := range before[l] {
+					if w == 0 && after[l][i] != 0 {
+						t.Fatalf("%s/%s: pruned weight [%d][%d] became %v during fine-tuning", precision, scope, l, i, after[l][i])
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestPruneNeuronsShrinksLayers(t *testing.T) {
+	network, err := NewNeuralNetwork(10, 3, []int{8, 6}, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 讓部分神經元的輸出權重為 0：移除它們不會改變網路的輸出
+	dead := map[int][]int{0: {1, 4}, 1: {0, 5}}
+	for r := 0; r < 6; r++ {
+		for _, d := range dead[0] {
+			network.Hidden[1].weight.Set(r, d, 0)
+		}
+	}
+	for r := 0; r < 3; r++ {
+		for _, d := range dead[1] {
+			network.OutputWeight.Set(r, d, 0)
+		}
+	}
+	input := learnableDataset(6, 1, 10, 3)[0].Input
+	want, _ := network.Forward(input)
+	params := network.ParameterCount()
+
+	if err := network.PruneNeurons(0.25); err != nil {
+		t.Fatal(err)
+	}
+	if rows, cols := network.Hidden[0].weight.Dims(); rows != 6 || cols != 10 {
+		t.Errorf("hidden layer 0 is %dx%d, want 6x10", rows, cols)
+	}
+	if rows, cols := network.Hidden[1].weight.Dims(); rows != 4 || cols != 6 {
+		t.Errorf("hidden layer 1 is %dx%d, want 4x6", rows, cols)
+	}
+	if rows, cols := network.OutputWeight.Dims(); rows != 3 || cols != 4 {
+		t.Errorf("output layer is %dx%d, want 3x4", rows, cols)
+	}
+	if network.ParameterCount() >= params {
+		t.Errorf("parameter count %d, was %d", network.ParameterCount(), params)
+	}
+	got, err := network.Forward(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mat.EqualApprox(want, got, 1e-12) {
+		t.Errorf("removing dead neurons changed the output: %v -> %v", mat.Formatted(want.T()), mat.Formatted(got.T()))
+	}
+}
+
+func TestPrunedModelSaveLoad(t *testing.T) {
+	network, err := NewNeuralNetwork(12, 3, []int{10}, 0.05)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := network.PruneMagnitude(0.5, PruneGlobal); err != nil {
+		t.Fatal(err)
+	}
+	if err := network.PruneNeurons(0.2); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "pruned.json")
+	if err := SaveModel(network, path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadModel(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.masks == nil {
+		t.Fatal("loaded pruned model has no masks")
+	}
+	if loaded.Sparsity() != network.Sparsity() {
+		t.Errorf("sparsity %.3f after loading, want %.3f", loaded.Sparsity(), network.Sparsity())
+	}
+	data := syntheticDataset(7, 20, 12, 3)
+	if err := TrainingLoop(context.Background(), loaded, TrainingConfig{Epochs: 1, BatchSize: 4}, data, data[:4]); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Sparsity() < network.Sparsity() {
+		t.Errorf("sparsity dropped to %.3f during fine-tuning, want %.3f", loaded.Sparsity(), network.Sparsity())
+	}
+}
@@ -0,0 +1,388 @@
+package nn
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// QuantizedModel 訓練後量化的 int8 模型，只能做推論
+//
+// 權重以每個輸出 channel（每個 row）各自的 scale 做對稱量化：w ≈ WeightScale[i] * q
+// 每一層的輸入同樣以對稱 int8 表示，scale 由校正資料的最大絕對值決定：x ≈ InputScale * q
+// 推論時矩陣乘法以 int32 累加，層與層之間以定點數乘法 requantize，不經過浮點數與 gonum
+type QuantizedModel struct {
+	Inputs      int
+	OutputClass int
+	InputShape  ImageShape
+	Layers      []QuantizedLayer // 隱藏層依序排列，最後一層為輸出層
+}
+
+// QuantizedLayer 一個量化後的全連接層
+type QuantizedLayer struct {
+	Rows, Cols  int
+	Weight      []int8    // row-major，Rows x Cols
+	WeightScale []float32 // 每個輸出 channel 的權重 scale
+	Bias        []int32   // 以 InputScale * WeightScale[i] 為 scale 的 bias
+	InputScale  float32   // 這一層輸入的 scale
+
+	// 由 scale 推導出來，不寫入檔案
+	multiplier []int32   // 隱藏層：requantize 到下一層輸入 scale 的 Q31 倍率
+	shift      []int32   // 與 multiplier 搭配的右移位數
+	outScale   []float32 // 輸出層：int32 累加值轉回 logits 的 scale
+}
+
+const (
+	quantizedMagic   = "NNQ8"
+	quantizedVersion = 1
+	// 讀檔時單層權重數量與層數的上限，避免損毀的檔案造成巨大配置
+	maxQuantizedWeights = 1 << 28
+	maxQuantizedLayers  = 1024
+	// 檔頭與每一層的 rows、cols、input scale 佔的位元組數
+	quantizedHeaderBytes = 7 * 4
+	quantizedLayerBytes  = 3 * 4
+)
+
+// QuantizeInt8 將訓練好的分類模型轉成 int8 模型
+// calibration 為校正用的樣本（通常取訓練集的一部分），用來決定每一層輸入的範圍
+func QuantizeInt8(nn *NeuralNetwork, calibration []TrainingData) (*QuantizedModel, error) {
+	if !nn.IsClassifier() {
+		return nil, fmt.Errorf("int8 quantization only supports softmax classifiers, model output is %q", nn.outputActivation())
+	}
+	if len(calibration) == 0 {
+		return nil, fmt.Errorf("Empty calibration set")
+	}
+	nn.syncFromFloat32()
+
+	// 每一層輸入的最大絕對值：ranges[0] 為網路輸入，ranges[i+1] 為第 i 個隱藏層（ReLU 後）的輸出
+	ranges := make([]float64, len(nn.Hidden)+1)
+	ws := newWorkspace(nn)
+	for _, sample := range calibration {
+		if r, c := sample.Input.Dims(); r != nn.Inputs || c != 1 {
+			return nil, fmt.Errorf("Calibration input is %dx%d, model expects %dx1", r, c, nn.Inputs)
+		}
+		input := columnData(sample.Input)
+		ws.forward(nn, input)
+		ranges[0] = math.Max(ranges[0], maxAbs(input))
+		for i, activation := range ws.activations {
+			ranges[i+1] = math.Max(ranges[i+1], maxAbs(activation))
+		}
+	}
+
+	q := &QuantizedModel{Inputs: nn.Inputs, OutputClass: nn.OutputClass, InputShape: nn.InputShape}
+	for i, layer := range nn.Hidden {
+		q.Layers = append(q.Layers, quantizeLayer(layer.weight, layer.bias, ranges[i]))
+	}
+	q.Layers = append(q.Layers, quantizeLayer(nn.OutputWeight, nn.OutputBias, ranges[len(ranges)-1]))
+	q.prepare()
+	return q, nil
+}
+
+// quantizeLayer 以每個 row 的最大絕對值決定權重 scale，bias 量化成 int32
+func quantizeLayer(weightMat, biasMat *mat.Dense, inputRange float64) QuantizedLayer {
+	raw := weightMat.RawMatrix()
+	rows, cols := raw.Rows, raw.Cols
+	weight, bias := raw.Data, biasMat.RawMatrix().Data
+	layer := QuantizedLayer{
+		Rows:        rows,
+		Cols:        cols,
+		Weight:      make([]int8, rows*cols),
+		WeightScale: make([]float32, rows),
+		Bias:        make([]int32, rows),
+		InputScale:  float32(activationScale(inputRange)),
+	}
+	for i := 0; i < rows; i++ {
+		row := weight[i*cols : (i+1)*cols]
+		scale := activationScale(maxAbs(row))
+		layer.WeightScale[i] = float32(scale)
+		for j, w := range row {
+			layer.Weight[i*cols+j] = clampInt8(math.Round(w / scale))
+		}
+		biasScale := float64(layer.InputScale) * float64(layer.WeightScale[i])
+		layer.Bias[i] = int32(math.Max(math.MinInt32, math.Min(math.MaxInt32, math.Round(bias[i]/biasScale))))
+	}
+	return layer
+}
+
+// activationScale 對稱 int8 的 scale，範圍為 0（例如整個 row 都是 0）時使用 1
+func activationScale(maxAbs float64) float64 {
+	if maxAbs == 0 {
+		return 1
+	}
+	return maxAbs / 127
+}
+
+func maxAbs(values []float64) float64 {
+	result := 0.0
+	for _, v := range values {
+		result = math.Max(result, math.Abs(v))
+	}
+	return result
+}
+
+func clampInt8(v float64) int8 {
+	return int8(math.Max(-127, math.Min(127, v)))
+}
+
+// prepare 由各層的 scale 計算 requantize 需要的定點數倍率
+func (q *QuantizedModel) prepare() {
+	for l := range q.Layers {
+		layer := &q.Layers[l]
+		if l == len(q.Layers)-1 {
+			layer.outScale = make([]float32, layer.Rows)
+			for i, s := range layer.WeightScale {
+				layer.outScale[i] = s * layer.InputScale
+			}
+			continue
+		}
+		next := float64(q.Layers[l+1].InputScale)
+		layer.multiplier = make([]int32, layer.Rows)
+		layer.shift = make([]int32, layer.Rows)
+		for i, s := range layer.WeightScale {
+			layer.multiplier[i], layer.shift[i] = quantizeMultiplier(float64(s) * float64(layer.InputScale) / next)
+		}
+	}
+}
+
+// quantizeMultiplier 把實數倍率 m 表示成 multiplier * 2^-(31+shift)，multiplier 為 [2^30, 2^31) 的整數
+func quantizeMultiplier(m float64) (int32, int32) {
+	if m <= 0 {
+		return 0, 0
+	}
+	frac, exp := math.Frexp(m) // m = frac * 2^exp，frac 在 [0.5, 1)
+	multiplier := int64(math.Round(frac * (1 << 31)))
+	if multiplier == 1<<31 {
+		multiplier /= 2
+		exp++
+	}
+	return int32(multiplier), int32(-exp)
+}
+
+// requantizeReLU 將 int32 累加值乘上定點數倍率，套用 ReLU 後截斷到 [0, 127]
+func requantizeReLU(acc, multiplier, shift int32) int8 {
+	if acc <= 0 || multiplier == 0 {
+		return 0
+	}
+	total := 31 + int64(shift)
+	product := int64(acc) * int64(multiplier)
+	var result int64
+	switch {
+	case total <= 0:
+		result = product << -total
+	case total >= 63:
+		result = 0
+	default:
+		result = (product + 1<<(total-1)) >> total
+	}
+	return int8(min(result, 127))
+}
+
+// matVecInt8 acc = bias + W x，以 int32 累加
+func matVecInt8(layer *QuantizedLayer, x []int8, acc []int32) {
+	for i := 0; i < layer.Rows; i++ {
+		sum := layer.Bias[i]
+		row := layer.Weight[i*layer.Cols : (i+1)*layer.Cols]
+		for j, w := range row {
+			sum += int32(w) * int32(x[j])
+		}
+		acc[i] = sum
+	}
+}
+
+// Forward 整數推論，回傳輸出層的 logits
+// 只有輸入量化與最後的 logits 使用浮點數，中間各層都是 int8 / int32 運算
+func (q *QuantizedModel) Forward(input []float64) ([]float64, error) {
+	if len(input) != q.Inputs {
+		return nil, fmt.Errorf("Input has %d values, model expects %d", len(input), q.Inputs)
+	}
+	x := make([]int8, q.Inputs)
+	inputScale := float64(q.Layers[0].InputScale)
+	for i, v := range input {
+		x[i] = clampInt8(math.Round(v / inputScale))
+	}
+
+	for l := range q.Layers {
+		layer := &q.Layers[l]
+		acc := make([]int32, layer.Rows)
+		matVecInt8(layer, x, acc)
+		if l == len(q.Layers)-1 {
+			logits := make([]float64, layer.Rows)
+			for i, a := range acc {
+				logits[i] = float64(a) * float64(layer.outScale[i])
+			}
+			return logits, nil
+		}
+		x = make([]int8, layer.Rows)
+		for i, a := range acc {
+			x[i] = requantizeReLU(a, layer.multiplier[i], layer.shift[i])
+		}
+	}
+	return nil, fmt.Errorf("Quantized model has no layers")
+}
+
+// Classify 回傳預測的類別
+func (q *QuantizedModel) Classify(input []float64) (int, error) {
+	logits, err := q.Forward(input)
+	if err != nil {
+		return 0, err
+	}
+	best := 0
+	for i, v := range logits {
+		if v > logits[best] {
+			best = i
+		}
+	}
+	return best, nil
+}
+
+// Accuracy 計算資料集上的分類正確率
+func (q *QuantizedModel) Accuracy(data []TrainingData) (float64, error) {
+	if len(data) == 0 {
+		return 0, fmt.Errorf("Empty dataset")
+	}
+	correct := 0
+	for _, sample := range data {
+		prediction, err := q.Classify(columnData(sample.Input))
+		if err != nil {
+			return 0, err
+		}
+		label, err := Argmax(sample.Target)
+		if err != nil {
+			return 0, err
+		}
+		if prediction == label {
+			correct++
+		}
+	}
+	return float64(correct) / float64(len(data)), nil
+}
+
+// SaveQuantized 以 little-endian binary 格式儲存量化模型
+//
+//	"NNQ8" | version | inputs | classes | channels | height | width | layer count
+//	每一層：rows | cols | input scale | weight scales[rows] | bias[rows] | weight[rows*cols]
+func SaveQuantized(q *QuantizedModel, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := writeQuantized(file, q); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// writeQuantized 依 SaveQuantized 的格式把量化模型寫入 out
+func writeQuantized(out io.Writer, q *QuantizedModel) error {
+	w := bufio.NewWriter(out)
+	header := []uint32{
+		quantizedVersion,
+		uint32(q.Inputs), uint32(q.OutputClass),
+		uint32(q.InputShape.Channels), uint32(q.InputShape.Height), uint32(q.InputShape.Width),
+		uint32(len(q.Layers)),
+	}
+	if _, err := w.WriteString(quantizedMagic); err != nil {
+		return err
+	}
+	fields := []any{header}
+	for _, layer := range q.Layers {
+		fields = append(fields,
+			[]uint32{uint32(layer.Rows), uint32(layer.Cols)},
+			layer.InputScale, layer.WeightScale, layer.Bias, layer.Weight)
+	}
+	for _, field := range fields {
+		if err := binary.Write(w, binary.LittleEndian, field); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// LoadQuantized 讀取 SaveQuantized 寫入的檔案
+func LoadQuantized(filename string) (*QuantizedModel, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	r := bufio.NewReader(file)
+
+	magic := make([]byte, len(quantizedMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != quantizedMagic {
+		return nil, fmt.Errorf("%s is not a quantized model", filename)
+	}
+	header := make([]uint32, 7)
+	if err := binary.Read(r, binary.LittleEndian, header); err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	if header[0] != quantizedVersion {
+		return nil, fmt.Errorf("Unsupported quantized model version %d", header[0])
+	}
+	// 檔頭的數值都來自檔案，配置前先檢查範圍與剩下的檔案大小
+	remaining := info.Size() - int64(len(quantizedMagic)) - quantizedHeaderBytes
+	inputs, classes, layers := int64(header[1]), int64(header[2]), int64(header[6])
+	if inputs == 0 || inputs > maxQuantizedWeights || classes == 0 || classes > maxQuantizedWeights {
+		return nil, fmt.Errorf("Quantized model has %d inputs and %d classes", inputs, classes)
+	}
+	if layers == 0 || layers > maxQuantizedLayers || layers*quantizedLayerBytes > remaining {
+		return nil, fmt.Errorf("Quantized model has %d layers in %d bytes", layers, info.Size())
+	}
+	shape := ImageShape{Channels: int(header[3]), Height: int(header[4]), Width: int(header[5])}
+	if int64(shape.Channels) > inputs || int64(shape.Height) > inputs || int64(shape.Width) > inputs ||
+		(shape.Size() != 0 && int64(shape.Size()) != inputs) {
+		return nil, fmt.Errorf("Input shape %dx%dx%d does not match %d inputs", shape.Channels, shape.Height, shape.Width, inputs)
+	}
+	q := &QuantizedModel{
+		Inputs:      int(inputs),
+		OutputClass: int(classes),
+		InputShape:  shape,
+		Layers:      make([]QuantizedLayer, layers),
+	}
+
+	cols := q.Inputs
+	for l := range q.Layers {
+		dims := make([]uint32, 2)
+		if err := binary.Read(r, binary.LittleEndian, dims); err != nil {
+			return nil, fmt.Errorf("failed to read layer %d: %w", l, err)
+		}
+		rows := int(dims[0])
+		if int(dims[1]) != cols || rows == 0 || rows > maxQuantizedWeights || int64(rows)*int64(cols) > maxQuantizedWeights {
+			return nil, fmt.Errorf("Layer %d is %dx%d, expected %d inputs", l, dims[0], dims[1], cols)
+		}
+		// weight scales 與 bias 各 4 bytes，權重各 1 byte
+		size := quantizedLayerBytes + int64(rows)*8 + int64(rows)*int64(cols)
+		if size > remaining {
+			return nil, fmt.Errorf("Layer %d needs %d bytes, only %d left in the file", l, size, remaining)
+		}
+		remaining -= size
+		layer := QuantizedLayer{
+			Rows:        rows,
+			Cols:        cols,
+			WeightScale: make([]float32, rows),
+			Bias:        make([]int32, rows),
+			Weight:      make([]int8, rows*cols),
+		}
+		for _, field := range []any{&layer.InputScale, layer.WeightScale, layer.Bias, layer.Weight} {
+			if err := binary.Read(r, binary.LittleEndian, field); err != nil {
+				return nil, fmt.Errorf("failed to read layer %d: %w", l, err)
+			}
+		}
+		q.Layers[l] = layer
+		cols = rows
+	}
+	if cols != q.OutputClass {
+		return nil, fmt.Errorf("Output layer has %d rows, model has %d classes", cols, q.OutputClass)
+	}
+	q.prepare()
+	return q, nil
+}
@@ -0,0 +1,132 @@
+package nn
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestQuantizeInt8Accuracy(t *testing.T) {
+	data := learnableDataset(4, 700, 16, 4)
+	trainSet, testSet := data[:500], data[500:]
+	network, err := NewNeuralNetwork(16, 4, []int{32, 16}, 0.05)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := TrainingLoop(context.Background(), network, TrainingConfig{Epochs: 5, BatchSize: 8, Seed: 1}, trainSet, testSet); err != nil {
+		t.Fatal(err)
+	}
+	quantized, err := QuantizeInt8(network, trainSet[:200])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metrics, err := Evaluate(network, testSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	accuracy, err := quantized.Accuracy(testSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := math.Abs(metrics["accuracy"] - accuracy); diff > 0.03 {
+		t.Errorf("float accuracy %.4f, int8 accuracy %.4f", metrics["accuracy"], accuracy)
+	}
+
+	path := filepath.Join(t.TempDir(), "model.q8")
+	if err := SaveQuantized(quantized, path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadQuantized(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sample := range testSet[:20] {
+		want, _ := quantized.Forward(columnData(sample.Input))
+		got, err := loaded.Forward(columnData(sample.Input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(want, got) {
+			t.Fatalf("loaded model gives %v, want %v", got, want)
+		}
+	}
+}
+
+func TestRequantizeMatchesFloat(t *testing.T) {
+	for _, m := range []float64{0.75, 0.0123, 3.5e-5, 1.9} {
+		multiplier, shift := quantizeMultiplier(m)
+		for _, acc := range []int32{0, -50, 1, 37, 1000, 80000} {
+			want := math.Min(127, math.Round(math.Max(0, float64(acc)*m)))
+			if got := requantizeReLU(acc, multiplier, shift); float64(got) != want {
+				t.Errorf("requantize(%d * %v) = %d, want %v", acc, m, got, want)
+			}
+		}
+	}
+}
+
+func TestLoadQuantizedRejectsOtherFiles(t *testing.T) {
+	network, err := NewNeuralNetwork(4, 2, []int{3}, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "model.json")
+	if err := SaveModel(network, path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadQuantized(path); err == nil {
+		t.Error("loading a JSON model as a quantized model should fail")
+	}
+}
+
+func TestLoadQuantizedRejectsCorruptHeaders(t *testing.T) {
+	network, err := NewNeuralNetwork(4, 2, []int{3}, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := QuantizeInt8(network, learnableDataset(2, 20, 4, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "model.q8")
+	if err := SaveQuantized(q, path); err != nil {
+		t.Fatal(err)
+	}
+	valid, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadQuantized(path); err != nil {
+		t.Fatalf("valid file: %v", err)
+	}
+
+	// 檔頭的欄位在 magic 之後：version、inputs、classes、channels、height、width、layers
+	header := func(fields map[int]uint32) []byte {
+		data := slices.Clone(valid)
+		for i, v := range fields {
+			binary.LittleEndian.PutUint32(data[len(quantizedMagic)+4*i:], v)
+		}
+		return data
+	}
+	cases := map[string][]byte{
+		"huge layer count":   header(map[int]uint32{6: math.MaxUint32}),
+		"layers beyond file": header(map[int]uint32{6: 1000}),
+		"huge inputs":        header(map[int]uint32{1: math.MaxUint32}),
+		"shape mismatch":     header(map[int]uint32{3: 1, 4: 3, 5: 3}),
+		"truncated":          valid[:len(valid)-5],
+	}
+	for name, data := range cases {
+		file := filepath.Join(dir, name+".q8")
+		if err := os.WriteFile(file, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadQuantized(file); err == nil {
+			t.Errorf("%s: loading should fail", name)
+		}
+	}
+}
@@ -1,184 +1,204 @@
 package nn
 
 import (
+	"context"
 	"encoding/csv"
 	"fmt"
 	"io"
+	"maps"
 	"math"
+	"math/rand"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
 
+	"golang-neural-network/internal/numeric"
+
+	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/mat"
 )
 
 
 func crossEntropyLoss(pred *mat.Dense, truth *mat.Dense) (float64, error) {
-	// pred and truth should both be one-hot encoded vector
-	r, _ := pred.Dims()
+	// pred 為 softmax 後的機率，truth 為 one-hot（每個 column 一個樣本），回傳所有樣本的 loss 總和
+	// 訓練時請用 SoftmaxCrossEntropy 直接從 logits 計算，避免 log(0)
+	r, c := pred.Dims()
 	losssum := 0.0
-	for i := 0; i < r; i++ {
-		predValue := pred.At(i, 0) 
-		truthValue := truth.At(i, 0)
-		// 防止 log(0) 導致 -Inf
-		predValue = math.Max(predValue, 1e-15)
-		losssum += -truthValue * math.Log(predValue)
+	for j := 0; j < c; j++ {
+		for i := 0; i < r; i++ {
+			predValue := pred.At(i, j)
+			truthValue := truth.At(i, j)
+			// 防止 log(0) 導致 -Inf
+			predValue = math.Max(predValue, 1e-15)
+			losssum += -truthValue * math.Log(predValue)
+		}
 	}
 	return losssum, nil
 }
 
-func (nn *NeuralNetwork) forwardWithCache(input *mat.Dense) (*mat.Dense, []*mat.Dense, error) {
-	// 用於訓練的forward版本 會記錄每層輸出以供backpropagation使用
-	layerOutputs := []*mat.Dense{input}  // 保存輸入層
-	current := input
-
-	// 處理所有隱藏層
-	for _, layer := range nn.Hidden {
-		weighted := mat.Dense{}
-		weighted.Mul(layer.weight, current)
-		weighted.Add(&weighted, layer.bias)
-
-		activated := relu(&weighted)
-		layerOutputs = append(layerOutputs, activated)
-		current = activated
-	}
-	// 處理輸出層
-	output := mat.Dense{}
-	output.Mul(nn.OutputWeight, current)
-	output.Add(&output, nn.OutputBias)
-
-	return &output, layerOutputs, nil
+// SoftmaxCrossEntropy 融合 softmax 與 cross-entropy，直接從 logits 計算（每個 column 一個樣本）
+// loss = -Σ t_i log_softmax(z)_i，log_softmax 以每個 column 的 log-sum-exp 計算
+// 同時回傳 backward 的結果 dL/dz_j = p_j Σ_i t_i - t_j（one-hot 時即為 p - t）
+func SoftmaxCrossEntropy(logits *mat.Dense, target *mat.Dense) (float64, *mat.Dense) {
+	r, c := logits.Dims()
+	grad := mat.NewDense(r, c, nil)
+	return softmaxCrossEntropyInto(logits, target, grad), grad
 }
 
-
-func reluDerivative(m *mat.Dense) *mat.Dense {
-	r, c := m.Dims()
-	new_matrix := mat.NewDense(r, c, nil)
-	for i := 0; i < r; i++ {
-		for j := 0; j < c; j++ {
-			value := m.At(i, j)
-			if value <= 0 {
-				// x <= 0,  relu(x) = 0 導數等於 0
-				new_matrix.Set(i, j, 0)
-			} else {
-				// x > 0, relu(x) = x 導數等於 1
-				new_matrix.Set(i, j, 1)
+// softmaxCrossEntropyInto 與 SoftmaxCrossEntropy 相同，但梯度寫入呼叫端提供的 grad
+func softmaxCrossEntropyInto(logits *mat.Dense, target *mat.Dense, grad *mat.Dense) float64 {
+	r, c := logits.Dims()
+	loss := 0.0
+	for j := 0; j < c; j++ {
+		max, logSum := numeric.ColumnLogSumExp(logits, j)
+		targetSum := 0.0
+		for i := 0; i < r; i++ {
+			t := target.At(i, j)
+			if t != 0 {
+				loss -= t * ((logits.At(i, j) - max) - logSum)
 			}
+			targetSum += t
+		}
+		for i := 0; i < r; i++ {
+			p := math.Exp((logits.At(i, j) - max) - logSum)
+			grad.Set(i, j, p*targetSum-target.At(i, j))
 		}
 	}
-	return new_matrix
+	return loss
+}
+
+// gradients 保存一次 backward 的所有參數梯度（形狀與對應的參數相同）
+// loss.backward() in pytorch 的結果，之後再由 applyGradients 一次更新
+type gradients struct {
+	hiddenWeights []*mat.Dense
+	hiddenBiases  []*mat.Dense
+	outputWeight  *mat.Dense
+	outputBias    *mat.Dense
 }
 
-func hadamardProduct(matA, matB *mat.Dense) (*mat.Dense, error){
-	rA, cA := matA.Dims()
-	rB, cB := matB.Dims()
-	if rA != rB || cA != cB {
-		return nil, fmt.Errorf("Matrices of different shape can't perform hadamardProduct")
+func newGradients(nn *NeuralNetwork) *gradients {
+	g := &gradients{
+		hiddenWeights: make([]*mat.Dense, len(nn.Hidden)),
+		hiddenBiases:  make([]*mat.Dense, len(nn.Hidden)),
 	}
-	result := mat.NewDense(rA, cA, nil)
-	for i := 0; i < rA; i++{
-		for j:=0; j < cA; j++{
-			result.Set(i, j, matA.At(i, j) * matB.At(i, j)) 
-		}
+	for i, layer := range nn.Hidden {
+		g.hiddenWeights[i] = mat.NewDense(layer.weight.RawMatrix().Rows, layer.weight.RawMatrix().Cols, nil)
+		g.hiddenBiases[i] = mat.NewDense(layer.bias.RawMatrix().Rows, 1, nil)
 	}
-	return  result, nil
+	r, c := nn.OutputWeight.Dims()
+	g.outputWeight = mat.NewDense(r, c, nil)
+	g.outputBias = mat.NewDense(r, 1, nil)
+	return g
 }
 
-func (nn *NeuralNetwork) backPropagation(pred *mat.Dense, traget *mat.Dense, layerOutputs []*mat.Dense){
-	// IMPORTANT!!!: Compute ALL gradients first using ORIGINAL weights, then apply updates
-
-	layercount := len(nn.Hidden)
-	// Storage for gradients (will apply all at once at the end)
-	// loss.backward() in pytorch
-	hiddenWeightGrads := make([]*mat.Dense, layercount)
-	hiddenBiasGrads := make([]*mat.Dense, layercount)
-
-	// outputError = pred - target (for softmax + cross-entropy)
-	var outputError mat.Dense
-	outputError.Sub(pred, traget)
+func (g *gradients) zero() {
+	for i := range g.hiddenWeights {
+		g.hiddenWeights[i].Zero()
+		g.hiddenBiases[i].Zero()
+	}
+	g.outputWeight.Zero()
+	g.outputBias.Zero()
+}
 
-	// Compute output layer gradients
-	var outputWeightGrad mat.Dense
-	outputWeightGrad.Mul(&outputError, layerOutputs[len(layerOutputs)-1].T())
+// add 將 other 的梯度累加進 g
+func (g *gradients) add(other *gradients) {
+	for i := range g.hiddenWeights {
+		floats.Add(g.hiddenWeights[i].RawMatrix().Data, other.hiddenWeights[i].RawMatrix().Data)
+		floats.Add(g.hiddenBiases[i].RawMatrix().Data, other.hiddenBiases[i].RawMatrix().Data)
+	}
+	floats.Add(g.outputWeight.RawMatrix().Data, other.outputWeight.RawMatrix().Data)
+	floats.Add(g.outputBias.RawMatrix().Data, other.outputBias.RawMatrix().Data)
+}
 
-	// Save a copy of original output weights for gradient computation
-	origOutputWeight := mat.DenseCopyOf(nn.OutputWeight)
+// matrices 依 Graph 參數的順序回傳所有梯度矩陣
+func (g *gradients) matrices() []*mat.Dense {
+	var result []*mat.Dense
+	for i := range g.hiddenWeights {
+		result = append(result, g.hiddenWeights[i], g.hiddenBiases[i])
+	}
+	return append(result, g.outputWeight, g.outputBias)
+}
 
-	// Compute hidden layer gradients (backwards, using original weights)
-	var prevLayerError *mat.Dense
+// maxDiff 與 other 對應元素之間最大的差距
+func (g *gradients) maxDiff(other *gradients) float64 {
+	diff := 0.0
+	theirs := other.matrices()
+	for i, m := range g.matrices() {
+		diff = math.Max(diff, floats.Distance(m.RawMatrix().Data, theirs[i].RawMatrix().Data, math.Inf(1)))
+	}
+	return diff
+}
 
-	for i := layercount-1; i >= 0; i-- {
-		var layerError mat.Dense
+// maxAbs 所有梯度中最大的絕對值
+func (g *gradients) maxAbs() float64 {
+	result := 0.0
+	for _, m := range g.matrices() {
+		result = math.Max(result, floats.Norm(m.RawMatrix().Data, math.Inf(1)))
+	}
+	return result
+}
 
-		if i == layercount-1 {
-			// Use original output weights
-			layerError.Mul(origOutputWeight.T(), &outputError)
-		} else {
-			// Use original weights of layer i+1 (not yet updated because we haven't applied updates)
-			layerError.Mul(nn.Hidden[i+1].weight.T(), prevLayerError)
-		}
+// norm 所有梯度的 L2 norm
+func (g *gradients) norm() float64 {
+	sum := 0.0
+	add := func(m *mat.Dense) {
+		n := floats.Norm(m.RawMatrix().Data, 2)
+		sum += n * n
+	}
+	for i := range g.hiddenWeights {
+		add(g.hiddenWeights[i])
+		add(g.hiddenBiases[i])
+	}
+	add(g.outputWeight)
+	add(g.outputBias)
+	return math.Sqrt(sum)
+}
 
-		reluDeriv := reluDerivative(layerOutputs[i+1])
-		layerErrorPtr, _ := hadamardProduct(&layerError, reluDeriv)
-		prevLayerError = layerErrorPtr
+// backPropagation 由 ws.outputError（loss 對輸出層 logits 的梯度 dL/dz）反向傳播，把參數梯度累加進 grads
+// 不同輸出頭的差異都在 lossAndGradient 裡處理
+// 這裡不會修改權重，多個 goroutine 可以同時對同一個網路做 backward
+func (nn *NeuralNetwork) backPropagation(ws *workspace, input []float64, grads *gradients) {
+	// IMPORTANT!!!: Compute ALL gradients first using ORIGINAL weights, then apply updates (see applyGradients)
+	ws.backward(nn, input, grads)
+}
 
-		// Compute weight gradient for this layer
-		var layerGrad mat.Dense
-		layerGrad.Mul(layerErrorPtr, layerOutputs[i].T())
-		hiddenWeightGrads[i] = mat.DenseCopyOf(&layerGrad)
-		hiddenBiasGrads[i] = mat.DenseCopyOf(layerErrorPtr)
-	}
+// applyGradients 用梯度下降一次更新所有參數：W -= learningRate * scale * grad
+// optimizer.step() in pytorch，scale 用於 batch 取平均（1 / batch size）
+// 直接在權重的 backing slice 上更新，不配置新的矩陣
+func (nn *NeuralNetwork) applyGradients(grads *gradients, scale float64) {
+	step := -nn.LearningRate * scale
 
-	// Now apply all updates at once
 	// Update output layer
-	// optimizer.step() in pytorch
-	var scaledOutputWeightGrad mat.Dense
-	scaledOutputWeightGrad.Scale(nn.LearningRate, &outputWeightGrad)
-	r, c := nn.OutputWeight.Dims()
-	newOutputWeight := mat.NewDense(r, c, nil)
-	newOutputWeight.Sub(nn.OutputWeight, &scaledOutputWeightGrad)
-	nn.OutputWeight = newOutputWeight
-
-	var scaledOutputBiasGrad mat.Dense
-	scaledOutputBiasGrad.Scale(nn.LearningRate, &outputError)
-	rb, cb := nn.OutputBias.Dims()
-	newOutputBias := mat.NewDense(rb, cb, nil)
-	newOutputBias.Sub(nn.OutputBias, &scaledOutputBiasGrad)
-	nn.OutputBias = newOutputBias
+	floats.AddScaled(nn.OutputWeight.RawMatrix().Data, step, grads.outputWeight.RawMatrix().Data)
+	floats.AddScaled(nn.OutputBias.RawMatrix().Data, step, grads.outputBias.RawMatrix().Data)
 
 	// Update hidden layers
-	for i := 0; i < layercount; i++ {
-		var scaledWeightGrad mat.Dense
-		scaledWeightGrad.Scale(nn.LearningRate, hiddenWeightGrads[i])
-
-		wr, wc := nn.Hidden[i].weight.Dims()
-		updatedWeight := mat.NewDense(wr, wc, nil)
-		updatedWeight.Sub(nn.Hidden[i].weight, &scaledWeightGrad)
-		nn.Hidden[i].weight = updatedWeight
-
-		var scaledBiasGrad mat.Dense
-		scaledBiasGrad.Scale(nn.LearningRate, hiddenBiasGrads[i])
-
-		br, bc := nn.Hidden[i].bias.Dims()
-		updatedBias := mat.NewDense(br, bc, nil)
-		updatedBias.Sub(nn.Hidden[i].bias, &scaledBiasGrad)
-		nn.Hidden[i].bias = updatedBias
+	for i := range nn.Hidden {
+		floats.AddScaled(nn.Hidden[i].weight.RawMatrix().Data, step, grads.hiddenWeights[i].RawMatrix().Data)
+		floats.AddScaled(nn.Hidden[i].bias.RawMatrix().Data, step, grads.hiddenBiases[i].RawMatrix().Data)
 	}
-}   
+}
 
+// sampleGradients 對單一樣本做 forward + backward，梯度累加進 grads，回傳 loss
+// 所有中間結果都放在 ws 裡，不配置記憶體
+func (nn *NeuralNetwork) sampleGradients(ws *workspace, input *mat.Dense, target *mat.Dense, grads *gradients) (float64, error) {
+	if r, c := input.Dims(); r != nn.Inputs || c != 1 {
+		return 0, fmt.Errorf("Input is %dx%d, model expects %dx1", r, c, nn.Inputs)
+	}
+	x := columnData(input)
 
-func (nn *NeuralNetwork) train(input *mat.Dense, target *mat.Dense) (float64, error) {
 	//foward
-	logits, layerOutputs, _ := nn.forwardWithCache(input)
+	ws.forward(nn, x)
 
-	// softmax to decode preditction from pred matrix
-	pred := Softmax(logits)
-
-	//record loss
-	loss, _ := crossEntropyLoss(pred, target)
+	//record loss, and its gradient w.r.t. the output logits
+	loss, err := nn.lossInto(ws.logitsMat, target, ws.outputErrorMat)
+	if err != nil {
+		return 0, err
+	}
 
 	//backpropagation
-	nn.backPropagation(pred, target, layerOutputs)
-
+	nn.backPropagation(ws, x, grads)
 	return loss, nil
 }
 
@@ -187,7 +207,10 @@ type TrainingData struct{
 	Target *mat.Dense
 }
 
-func LoadingDataFromCSV(filename string) ([]TrainingData, error){
+// LoadingDataFromCSV 讀取 label,pixel1,pixel2,... 格式的 CSV
+// 輸入大小由欄位數決定；numClasses <= 0 時，類別數由檔案中最大的 label 推斷
+// 讀取驗證集時應傳入訓練集的類別數，避免兩者的 target 大小不一致
+func LoadingDataFromCSV(filename string, numClasses int) ([]TrainingData, error){
 	file, err := os.Open(filename)
 	if err != nil{
 		return nil, fmt.Errorf("Can't open file %s", filename)
@@ -195,7 +218,9 @@ func LoadingDataFromCSV(filename string) ([]TrainingData, error){
 	defer file.Close()
 
 	reader := csv.NewReader(file)
-	var data []TrainingData
+	var labels []int
+	var inputs []*mat.Dense
+	inputSize := 0
 	for {
 		record, err := reader.Read()
 		if err == io.EOF{
@@ -204,92 +229,384 @@ func LoadingDataFromCSV(filename string) ([]TrainingData, error){
 		if err != nil{
 			return nil, fmt.Errorf("Error reading csv %v", err)
 		}
-		var td TrainingData
-		target := mat.NewDense(10, 1, nil)
-		label, _ := strconv.Atoi(record[0])
-		target.Set(label, 0, 1.0)
-		td.Target = target
+		if inputSize == 0 {
+			inputSize = len(record) - 1
+		}
+		if inputSize <= 0 || len(record)-1 != inputSize {
+			return nil, fmt.Errorf("Row %d has %d pixels, expected %d", len(labels)+1, len(record)-1, inputSize)
+		}
+		label, err := strconv.Atoi(record[0])
+		if err != nil || label < 0 {
+			return nil, fmt.Errorf("Invalid label %q in row %d", record[0], len(labels)+1)
+		}
 
-		input := mat.NewDense(784, 1, nil)
-		for i:=0; i<784; i++{
+		input := mat.NewDense(inputSize, 1, nil)
+		for i:=0; i<inputSize; i++{
 			pixel, _ := strconv.Atoi(record[1:][i])
 			input.Set(i, 0, float64(pixel)/255.0) 
 		}
-		td.Input = input
-		data = append(data, td)
+		labels = append(labels, label)
+		inputs = append(inputs, input)
+	}
+
+	if numClasses <= 0 {
+		for _, label := range labels {
+			numClasses = max(numClasses, label+1)
+		}
+	}
+
+	data := make([]TrainingData, len(labels))
+	for i, label := range labels {
+		if label >= numClasses {
+			return nil, fmt.Errorf("Label %d in row %d is out of range for %d classes", label, i+1, numClasses)
+		}
+		target := mat.NewDense(numClasses, 1, nil)
+		target.Set(label, 0, 1.0)
+		data[i] = TrainingData{Input: inputs[i], Target: target}
 	}
 	return data, nil
 }
 
-func TrainingLoop(nn *NeuralNetwork, epoch int, trainingset []TrainingData, testset[]TrainingData) error {
+// DatasetShape 回傳資料集的輸入大小與類別數（target 的列數）
+func DatasetShape(data []TrainingData) (int, int, error) {
+	if len(data) == 0 {
+		return 0, 0, fmt.Errorf("Empty dataset")
+	}
+	inputs, _ := data[0].Input.Dims()
+	classes, _ := data[0].Target.Dims()
+	return inputs, classes, nil
+}
+
+// TrainingConfig 訓練參數
+type TrainingConfig struct {
+	Epochs        int
+	BatchSize     int        // mini-batch 大小，<= 1 代表逐筆 SGD
+	Workers       int        // 同時計算梯度的 goroutine 數量，<= 1 代表單執行緒
+	Seed          int64      // 每個 epoch 打亂訓練集順序用的 seed，0 代表維持原本順序
+	Autodiff      bool       // 選用：以 autodiff 的計算圖計算梯度（見 Graph）；預設為較快、不配置記憶體的手寫 backPropagation
+	CheckBackward bool       // 選用：第一個 batch 另外以 Graph 計算梯度，與手寫的 backPropagation 不一致時回傳錯誤
+	Callbacks     []Callback // 依序呼叫；TrainingLoop 本身不輸出任何東西，需要進度時加入 ProgressLogger
+
+	// Adversarial 不為 nil 時進行對抗訓練，並在驗證集的對抗樣本上另外驗證（adversarial_accuracy 等指標）
+	Adversarial *AdversarialConfig
+}
+
+// TrainingLoop 訓練網路，每個 epoch 結束後在 testset 上驗證（testset 為空時略過驗證）
+// callback 呼叫 StopTraining 時會在目前的 batch 結束後停止並回傳 nil
+// ctx 在每個 batch 之前檢查：被取消時完成目前的 batch 後停止，略過這個 epoch 的驗證，
+// 同樣呼叫 OnTrainEnd（state.Interrupted 為 true），最後回傳 ctx.Err()
+func TrainingLoop(ctx context.Context, nn *NeuralNetwork, config TrainingConfig, trainingset []TrainingData, testset[]TrainingData) error {
 	// training loop
+	if len(trainingset) == 0 {
+		return fmt.Errorf("Empty training set")
+	}
+	if (config.Autodiff || config.CheckBackward) && nn.isFloat32() {
+		return fmt.Errorf("Autodiff training and backward checks require float64 precision")
+	}
+	batchSize := max(config.BatchSize, 1)
+	var attackRng *rand.Rand
+	if config.Adversarial != nil {
+		adversarial := *config.Adversarial
+		if err := adversarial.Validate(); err != nil {
+			return err
+		}
+		config.Adversarial = &adversarial
+		nn.Adversarial = &adversarial
+		// PGD 的隨機起點，與打亂順序的 seed 分開
+		attackRng = rand.New(rand.NewSource(config.Seed + 1))
+	}
+	trainer := newParallelTrainer(nn, config.Workers, batchSize)
+	trainer.autodiff = config.Autodiff
+	trainer.verify = config.CheckBackward && !config.Autodiff
+	defer trainer.close()
+
+	order := make([]int, len(trainingset))
+	for i := range order {
+		order[i] = i
+	}
+	var shuffler *rand.Rand
+	if config.Seed != 0 {
+		shuffler = rand.New(rand.NewSource(config.Seed))
+	}
+
+	callbacks := callbackList(config.Callbacks)
+	state := &TrainingState{
+		Network:       nn,
+		Config:        config,
+		ValidationSet: testset,
+		Batches:       (len(trainingset) + batchSize - 1) / batchSize,
+		trainer:       trainer,
+	}
+	if err := callbacks.each(func(c Callback) error { return c.OnTrainBegin(state) }); err != nil {
+		return err
+	}
 
-	for i := 0; i < epoch; i++ {
-		//遍例所有training sample
-		lossSum := 0.0
-		for _, sample := range trainingset {
-			singleLoss, err := nn.train(sample.Input, sample.Target)
+	batch := make([]TrainingData, 0, batchSize)
+	for i := 0; i < config.Epochs && !state.stop; i++ {
+		if ctx.Err() != nil {
+			state.Interrupted = true
+			break
+		}
+		state.Epoch, state.Batch = i+1, 0
+		if err := callbacks.each(func(c Callback) error { return c.OnEpochBegin(state) }); err != nil {
+			return err
+		}
+		if shuffler != nil {
+			shuffler.Shuffle(len(order), func(a, b int) { order[a], order[b] = order[b], order[a] })
+		}
+
+		//遍例所有training sample，每 batchSize 筆更新一次
+		lossSum, seen, correct := 0.0, 0, 0
+		for start := 0; start < len(order) && !state.stop; start += batchSize {
+			if ctx.Err() != nil {
+				state.Interrupted = true
+				break
+			}
+			batch = batch[:0]
+			for _, idx := range order[start:min(start+batchSize, len(order))] {
+				batch = append(batch, trainingset[idx])
+			}
+			if config.Adversarial != nil {
+				var err error
+				if batch, err = nn.adversarialBatch(batch, *config.Adversarial, attackRng); err != nil {
+					return fmt.Errorf("Error During Training: %w", err)
+				}
+			}
+			batchLoss, err := trainer.step(batch)
 			if err != nil {
-				return fmt.Errorf("Error During Training")
+				return fmt.Errorf("Error During Training: %w", err)
+			}
+			lossSum += batchLoss
+			seen += len(batch)
+			correct += trainer.correct
+
+			state.Batch++
+			state.Step++
+			state.BatchLoss = batchLoss / float64(len(batch))
+			state.GradNorm = trainer.gradNorm
+			if err := callbacks.each(func(c Callback) error { return c.OnBatchEnd(state) }); err != nil {
+				return err
 			}
-			lossSum += singleLoss
 		}
-		avgLoss := lossSum / float64(len(trainingset))
-		fmt.Printf("Epoch 【%d/%d】| Average training Loss on this epoch %.4f\n", i+1, epoch, avgLoss)
-	
+		// float32 訓練時，把這個 epoch 的權重寫回 float64 矩陣
+		nn.syncFromFloat32()
+		if state.Interrupted {
+			break
+		}
+		state.TrainLoss = lossSum / float64(max(seen, 1))
+		if trainer.classify {
+			state.TrainAccuracy = float64(correct) / float64(max(seen, 1))
+		}
+
 		// validation loop
-		acc, err := validate(nn, testset)
-		if err != nil {
-			return fmt.Errorf("Error During Validation")
+		state.Validation = nil
+		if len(testset) > 0 {
+			metrics, err := Evaluate(nn, testset)
+			if err != nil {
+				return fmt.Errorf("Error During Validation: %w", err)
+			}
+			if config.Adversarial != nil {
+				adversarial, err := nn.adversarialValidation(testset, *config.Adversarial, attackRng)
+				if err != nil {
+					return fmt.Errorf("Error During Validation: %w", err)
+				}
+				maps.Copy(metrics, adversarial)
+			}
+			state.Validation = metrics
+			if err := callbacks.each(func(c Callback) error { return c.OnValidationEnd(state) }); err != nil {
+				return err
+			}
+		}
+		if err := callbacks.each(func(c Callback) error { return c.OnEpochEnd(state) }); err != nil {
+			return err
 		}
-		fmt.Printf("Validation 【%d/%d】 | Accuracy on validation set on this epoch: %.2f\n", i+1, epoch, acc)
 	}
 
+	if err := callbacks.each(func(c Callback) error { return c.OnTrainEnd(state) }); err != nil {
+		return err
+	}
+	if state.Interrupted {
+		return ctx.Err()
+	}
 	return nil
 }
 
+// argmax 回傳最大值的索引（相同時取第一個）
+func argmax(values []float64) int {
+	maxIdx := 0
+	for i, value := range values {
+		if value > values[maxIdx] {
+			maxIdx = i
+		}
+	}
+	return maxIdx
+}
+
+// Argmax 回傳向量（n x 1 或 1 x n）中最大值的索引
+// 批次輸入（每一個 column 為一個樣本）請使用 ArgmaxColumns
 func Argmax(input *mat.Dense) (int, error) {
 	r, c := input.Dims()
-	if r!=10 || c != 1 {
-		return 0, fmt.Errorf("Not a row vector, not avaliable in mnist task.")
+	if r != 1 && c != 1 {
+		return 0, fmt.Errorf("Argmax expects a vector, got %dx%d matrix", r, c)
 	}
+	return argmax(vectorValues(input)), nil
+}
 
-	maxValue := input.At(0, 0)
-	maxIdx := 0
-	for i:=0; i<r; i++{
-		value := input.At(i, 0)
-		if value > maxValue{
-			maxValue = value
-			maxIdx = i
+// ArgmaxColumns 對每一個 column 做 argmax，回傳每個樣本的預測索引
+func ArgmaxColumns(input *mat.Dense) []int {
+	r, c := input.Dims()
+	result := make([]int, c)
+	for j := 0; j < c; j++ {
+		maxIdx := 0
+		for i := 1; i < r; i++ {
+			if input.At(i, j) > input.At(maxIdx, j) {
+				maxIdx = i
+			}
 		}
+		result[j] = maxIdx
 	}
-	return maxIdx, nil
+	return result
 }
 
+// TopK 回傳每個 column 中最大的 k 個值的索引（由大到小）
+// 向量（包含 1 x n 的 row vector）視為單一樣本，結果只有一組
+func TopK(input *mat.Dense, k int) ([][]int, error) {
+	r, c := input.Dims()
+	if r == 1 && c > 1 {
+		r, c = c, 1
+		input = mat.NewDense(r, 1, vectorValues(input))
+	}
+	if k <= 0 || k > r {
+		return nil, fmt.Errorf("k must be between 1 and %d, got %d", r, k)
+	}
 
-func validate(nn *NeuralNetwork, testset []TrainingData) (float64, error) {
+	result := make([][]int, c)
+	for j := 0; j < c; j++ {
+		indices := make([]int, r)
+		for i := range indices {
+			indices[i] = i
+		}
+		column := mat.Col(nil, j, input)
+		sort.SliceStable(indices, func(a, b int) bool {
+			return column[indices[a]] > column[indices[b]]
+		})
+		result[j] = indices[:k]
+	}
+	return result, nil
+}
+
+// vectorValues 將 n x 1 或 1 x n 的矩陣攤平成 slice
+func vectorValues(input *mat.Dense) []float64 {
+	r, c := input.Dims()
+	values := make([]float64, 0, r*c)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			values = append(values, input.At(i, j))
+		}
+	}
+	return values
+}
+
+
+// Metrics 驗證指標，依輸出頭不同包含不同項目
+type Metrics map[string]float64
+
+// String 依名稱排序輸出，例如 "accuracy: 0.9612 | loss: 0.1320"
+func (m Metrics) String() string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s: %.4f", name, m[name])
+	}
+	return strings.Join(parts, " | ")
+}
+
+// Evaluate 計算資料集（通常是驗證集）上的指標
+//   softmax: loss, accuracy
+//   sigmoid: loss, accuracy（每個標籤以 0.5 為門檻）, exact_match（所有標籤都對）
+//   linear:  loss, mse, mae
+func Evaluate(nn *NeuralNetwork, testset []TrainingData) (Metrics, error) {
+	if len(testset) == 0 {
+		return nil, fmt.Errorf("Empty validation set")
+	}
+	activation := nn.outputActivation()
+	lossSum := 0.0
+	correct, labelCorrect, labelCount := 0, 0, 0
+	squaredErr, absErr := 0.0, 0.0
+
+	// 驗證時一樣使用 workspace，整個迴圈不配置記憶體
+	var ws *workspace
+	var ws32 *workspace32
+	var logitsMat, errMat *mat.Dense
+	if nn.isFloat32() {
+		ws32 = newWorkspace32(nn.f32, nn.Inputs)
+		logitsMat, errMat = ws32.logitsMat, ws32.outputErrorMat
+	} else {
+		ws = newWorkspace(nn)
+		logitsMat, errMat = ws.logitsMat, ws.outputErrorMat
+	}
+	logits := logitsMat.RawMatrix().Data
 
-	correct := 0
 	for _, sample := range testset {
-		logit, err := nn.Forward(sample.Input)
-		if err != nil{
-			return 0, fmt.Errorf("Error during Inference")
+		if r, c := sample.Input.Dims(); r != nn.Inputs || c != 1 {
+			return nil, fmt.Errorf("Error during Inference: input is %dx%d, model expects %dx1", r, c, nn.Inputs)
 		}
-		pred, err := Argmax(Softmax(logit))
-		if err != nil {
-			return 0, err
+		if ws32 != nil {
+			ws32.forward(nn.f32, columnData(sample.Input))
+		} else {
+			ws.forward(nn, columnData(sample.Input))
 		}
-
-		ans, err := Argmax(sample.Target)
+		loss, err := nn.lossInto(logitsMat, sample.Target, errMat)
 		if err != nil {
-			return 0, err
+			return nil, err
+		}
+		lossSum += loss
+		target := columnData(sample.Target)
+
+		switch activation {
+		case OutputSigmoid:
+			// sigmoid(z) >= 0.5 等價於 z >= 0
+			allCorrect := true
+			for i, z := range logits {
+				if (z >= 0) == (target[i] >= 0.5) {
+					labelCorrect++
+				} else {
+					allCorrect = false
+				}
+			}
+			labelCount += len(logits)
+			if allCorrect {
+				correct++
+			}
+		case OutputLinear:
+			for i, z := range logits {
+				diff := z - target[i]
+				squaredErr += diff * diff / float64(len(logits))
+				absErr += math.Abs(diff) / float64(len(logits))
+			}
+		default:
+			// softmax 不改變大小順序，直接對 logits 取 argmax
+			if floats.MaxIdx(logits) == floats.MaxIdx(target) {
+				correct++
+			}
 		}
-		if pred == ans{
-			correct++
-		}	
+	}
 
+	n := float64(len(testset))
+	metrics := Metrics{"loss": lossSum / n}
+	switch activation {
+	case OutputSigmoid:
+		metrics["accuracy"] = float64(labelCorrect) / float64(labelCount)
+		metrics["exact_match"] = float64(correct) / n
+	case OutputLinear:
+		metrics["mse"] = squaredErr / n
+		metrics["mae"] = absErr / n
+	default:
+		metrics["accuracy"] = float64(correct) / n
 	}
-	accuracy := float64(correct) / float64(len(testset)) 
-	return accuracy, nil
+	return metrics, nil
 }
-
@@ -0,0 +1,58 @@
+package nn
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// syntheticDataset 產生可重現的小型分類資料集
+func syntheticDataset(seed int64, samples, inputs, classes int) []TrainingData {
+	rng := rand.New(rand.NewSource(seed))
+	data := make([]TrainingData, samples)
+	for i := range data {
+		input := mat.NewDense(inputs, 1, nil)
+		for j := 0; j < inputs; j++ {
+			input.Set(j, 0, rng.Float64())
+		}
+		target := mat.NewDense(classes, 1, nil)
+		target.Set(rng.Intn(classes), 0, 1)
+		data[i] = TrainingData{Input: input, Target: target}
+	}
+	return data
+}
+
+func TestParallelTrainingMatchesSingleWorker(t *testing.T) {
+	data := syntheticDataset(1, 53, 12, 3)
+	base, err := NewNeuralNetwork(12, 3, []int{10, 6}, 0.05)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	train := func(workers int) *NeuralNetwork {
+		network := base.Clone()
+		config := TrainingConfig{Epochs: 2, BatchSize: 20, Workers: workers, Seed: 42}
+		if err := TrainingLoop(context.Background(), network, config, data, data[:10]); err != nil {
+			t.Fatal(err)
+		}
+		return network
+	}
+
+	want := train(1)
+	for _, workers := range []int{2, 3, 8} {
+		got := train(workers)
+		for i := range want.Hidden {
+			if !mat.Equal(got.Hidden[i].weight, want.Hidden[i].weight) || !mat.Equal(got.Hidden[i].bias, want.Hidden[i].bias) {
+				t.Errorf("workers=%d: hidden layer %d differs from single-threaded training", workers, i)
+			}
+		}
+		if !mat.Equal(got.OutputWeight, want.OutputWeight) || !mat.Equal(got.OutputBias, want.OutputBias) {
+			t.Errorf("workers=%d: output layer differs from single-threaded training", workers)
+		}
+	}
+	if mat.Equal(want.OutputWeight, base.OutputWeight) {
+		t.Error("training did not update the output weights")
+	}
+}
@@ -0,0 +1,137 @@
+package nn
+
+import (
+	"gonum.org/v1/gonum/blas"
+	"gonum.org/v1/gonum/blas/blas64"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// workspace forward/backward 重複使用的 buffer，訓練時每個 worker 各自擁有一個
+// 建立之後的 forward + backward 不會再配置記憶體
+// 同一個 workspace 同時只能給一個 goroutine 使用
+type workspace struct {
+	activations [][]float64 // activations[i] 為第 i 個隱藏層 ReLU 後的輸出
+	deltas      [][]float64 // deltas[i] 為 loss 對第 i 個隱藏層 pre-activation 的梯度
+	logits      []float64
+	outputError []float64 // dL/dz，loss 對輸出層 logits 的梯度
+
+	// 與 logits / outputError 共用 backing slice，給需要 *mat.Dense 的 loss 函數使用
+	logitsMat      *mat.Dense
+	outputErrorMat *mat.Dense
+}
+
+func newWorkspace(nn *NeuralNetwork) *workspace {
+	ws := &workspace{
+		activations: make([][]float64, len(nn.Hidden)),
+		deltas:      make([][]float64, len(nn.Hidden)),
+		logits:      make([]float64, nn.OutputClass),
+		outputError: make([]float64, nn.OutputClass),
+	}
+	for i, layer := range nn.Hidden {
+		rows, _ := layer.weight.Dims()
+		ws.activations[i] = make([]float64, rows)
+		ws.deltas[i] = make([]float64, rows)
+	}
+	ws.logitsMat = mat.NewDense(nn.OutputClass, 1, ws.logits)
+	ws.outputErrorMat = mat.NewDense(nn.OutputClass, 1, ws.outputError)
+	return ws
+}
+
+// forward 計算單一樣本的 logits，並保留每層輸出給 backward 使用
+func (ws *workspace) forward(nn *NeuralNetwork, input []float64) []float64 {
+	current := input
+	for i, layer := range nn.Hidden {
+		out := ws.activations[i]
+		copy(out, layer.bias.RawMatrix().Data)
+		gemv(layer.weight, current, out)
+		reluInPlace(out)
+		current = out
+	}
+	copy(ws.logits, nn.OutputBias.RawMatrix().Data)
+	gemv(nn.OutputWeight, current, ws.logits)
+	return ws.logits
+}
+
+// backward 由 ws.outputError 反向傳播，把參數梯度累加進 grads
+// 只讀取權重，所以多個 worker 可以同時對同一個網路做 backward
+func (ws *workspace) backward(nn *NeuralNetwork, input []float64, grads *gradients) {
+	layercount := len(nn.Hidden)
+	last := input
+	if layercount > 0 {
+		last = ws.activations[layercount-1]
+	}
+
+	// output layer: dW = outputError * a^T, db = outputError
+	ger(ws.outputError, last, grads.outputWeight)
+	floats.Add(grads.outputBias.RawMatrix().Data, ws.outputError)
+
+	for i := layercount - 1; i >= 0; i-- {
+		delta := ws.deltas[i]
+		if i == layercount-1 {
+			gemvT(nn.OutputWeight, ws.outputError, delta)
+		} else {
+			gemvT(nn.Hidden[i+1].weight, ws.deltas[i+1], delta)
+		}
+		reluBackward(delta, ws.activations[i])
+
+		prev := input
+		if i > 0 {
+			prev = ws.activations[i-1]
+		}
+		ger(delta, prev, grads.hiddenWeights[i])
+		floats.Add(grads.hiddenBiases[i].RawMatrix().Data, delta)
+	}
+}
+
+// columnData 回傳 n x 1 矩陣的 backing slice，不複製
+// 其他形狀（例如 view 或 row vector）才複製成新的 slice
+func columnData(m *mat.Dense) []float64 {
+	raw := m.RawMatrix()
+	if raw.Cols == 1 && raw.Stride == 1 {
+		return raw.Data[:raw.Rows]
+	}
+	return mat.Col(nil, 0, m)
+}
+
+// gemv y += W x（W 必須是連續儲存的矩陣）
+func gemv(w *mat.Dense, x, y []float64) {
+	raw := w.RawMatrix()
+	blas64.Gemv(blas.NoTrans, 1, raw,
+		blas64.Vector{N: raw.Cols, Inc: 1, Data: x}, 1,
+		blas64.Vector{N: raw.Rows, Inc: 1, Data: y})
+}
+
+// gemvT y = W^T x
+func gemvT(w *mat.Dense, x, y []float64) {
+	raw := w.RawMatrix()
+	blas64.Gemv(blas.Trans, 1, raw,
+		blas64.Vector{N: raw.Rows, Inc: 1, Data: x}, 0,
+		blas64.Vector{N: raw.Cols, Inc: 1, Data: y})
+}
+
+// ger A += x y^T（rank-1 update，用來累加權重梯度）
+func ger(x, y []float64, a *mat.Dense) {
+	raw := a.RawMatrix()
+	blas64.Ger(1,
+		blas64.Vector{N: raw.Rows, Inc: 1, Data: x},
+		blas64.Vector{N: raw.Cols, Inc: 1, Data: y}, raw)
+}
+
+// reluInPlace x = max(x, 0)
+func reluInPlace(x []float64) {
+	for i, v := range x {
+		if v < 0 {
+			x[i] = 0
+		}
+	}
+}
+
+// reluBackward delta *= relu'(x)，由 ReLU 的輸出判斷：輸出 <= 0 時導數為 0，否則為 1
+func reluBackward(delta, activation []float64) {
+	for i, a := range activation {
+		if a <= 0 {
+			delta[i] = 0
+		}
+	}
+}
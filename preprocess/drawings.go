@@ -0,0 +1,70 @@
+package preprocess
+
+import (
+	"fmt"
+	"golang-neural-network/nn"
+	"image"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// Drawing 一張存下來的手寫圖片與其標籤
+type Drawing struct {
+	Label int
+	Path  string
+	Image image.Image
+}
+
+// LoadDrawings 讀取 dir/<label>/*.png，例如手寫板存下的 drawings/7/1760000000.png
+func LoadDrawings(dir string) ([]Drawing, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*", "*.png"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	var drawings []Drawing
+	for _, path := range paths {
+		label, err := strconv.Atoi(filepath.Base(filepath.Dir(path)))
+		if err != nil {
+			continue
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		img, _, err := image.Decode(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", path, err)
+		}
+		drawings = append(drawings, Drawing{Label: label, Path: path, Image: img})
+	}
+	if len(drawings) == 0 {
+		return nil, fmt.Errorf("No drawings found in %s, expected <label>/*.png", dir)
+	}
+	return drawings, nil
+}
+
+// Evaluate 以 options 預處理每張手寫圖片後預測，回傳預測正確的數量
+func Evaluate(model *nn.NeuralNetwork, drawings []Drawing, options Options) (int, error) {
+	shape := model.ImageShape()
+	correct := 0
+	for _, d := range drawings {
+		processed, _ := Process(d.Image, shape, options)
+		probs, err := model.Predict(Input(processed, shape))
+		if err != nil {
+			return 0, err
+		}
+		prediction, err := nn.Argmax(probs)
+		if err != nil {
+			return 0, err
+		}
+		if prediction == d.Label {
+			correct++
+		}
+	}
+	return correct, nil
+}
@@ -0,0 +1,41 @@
+package preprocess
+
+import (
+	"golang-neural-network/nn"
+	"testing"
+)
+
+// testdata/drawings 是以手寫板的筆刷（24x24 的正方形）在 560x560 畫布上畫出的 100 個數字，
+// 大小、位置、長寬比與傾斜都不同
+func TestEvaluateSavedDrawings(t *testing.T) {
+	drawings, err := LoadDrawings("testdata/drawings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	model, err := nn.LoadModel("../models/basic.json")
+	if err != nil {
+		t.Skipf("no model: %v", err)
+	}
+	accuracy := map[string]int{}
+	for _, spec := range []string{"legacy", "mnist", "mass,lanczos", "mass,area,deskew,stroke"} {
+		options, err := Parse(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		correct, err := Evaluate(model, drawings, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		accuracy[spec] = correct
+		t.Logf("%-24s %d/%d", spec, correct, len(drawings))
+	}
+	if accuracy["mnist"] <= accuracy["legacy"] {
+		t.Errorf("mnist preprocessing %d correct, legacy %d", accuracy["mnist"], accuracy["legacy"])
+	}
+}
+
+func TestLoadDrawingsRejectsEmptyDir(t *testing.T) {
+	if _, err := LoadDrawings(t.TempDir()); err == nil {
+		t.Error("expected an error for a directory without drawings")
+	}
+}
@@ -0,0 +1,51 @@
+package preprocess
+
+import (
+	"golang-neural-network/nn"
+	"image"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Input 依模型的通道數把預處理後的影像轉成輸入矩陣
+func Input(img *image.RGBA, shape nn.ImageShape) *mat.Dense {
+	if shape.Channels == 3 {
+		return rgbInput(img)
+	}
+	return grayscaleInput(img)
+}
+
+// grayscaleInput 將圖片轉為灰階並標準化到 0-1，返回 (H*W)x1 矩陣
+// MNIST 是黑底白字，手寫的是白底黑字，所以反轉：黑色筆跡 -> 1.0，白色背景 -> 0.0
+func grayscaleInput(img *image.RGBA) *mat.Dense {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	data := make([]float64, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			r, g, b, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			gray := (r + g + b) / 3
+			data[y*width+x] = 1.0 - float64(gray)/65535.0
+		}
+	}
+	return mat.NewDense(width*height, 1, data)
+}
+
+// rgbInput 將彩色圖片標準化到 0-1，返回 (3*H*W)x1 矩陣
+// 排列方式與 CIFAR-10 相同：先放完整的 R 通道，再放 G，最後 B
+func rgbInput(img *image.RGBA) *mat.Dense {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	plane := width * height
+	data := make([]float64, 3*plane)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			r, g, b, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			idx := y*width + x
+			data[idx] = float64(r) / 65535.0
+			data[plane+idx] = float64(g) / 65535.0
+			data[2*plane+idx] = float64(b) / 65535.0
+		}
+	}
+	return mat.NewDense(3*plane, 1, data)
+}
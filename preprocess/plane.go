@@ -0,0 +1,238 @@
+package preprocess
+
+import (
+	"image"
+	"math"
+)
+
+// inkThreshold 墨水量超過此值的像素算是筆跡（與原本手寫板的 60000/65535 判斷相同）
+const inkThreshold = 1 - 60000.0/65535.0
+
+// plane 單通道的墨水量影像，0 為背景、1 為筆跡，row-major
+type plane struct {
+	width, height int
+	values        []float64
+}
+
+func newPlane(width, height int) *plane {
+	return &plane{width: width, height: height, values: make([]float64, width*height)}
+}
+
+func (p *plane) at(x, y int) float64 {
+	return p.values[y*p.width+x]
+}
+
+func (p *plane) set(x, y int, v float64) {
+	p.values[y*p.width+x] = v
+}
+
+// inkPlane 把淺色背景、深色筆跡的影像轉成墨水量（1 - 灰階）
+func inkPlane(img image.Image) *plane {
+	bounds := img.Bounds()
+	p := newPlane(bounds.Dx(), bounds.Dy())
+	for y := 0; y < p.height; y++ {
+		for x := 0; x < p.width; x++ {
+			r, g, b, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			p.set(x, y, 1-float64(r+g+b)/3/65535)
+		}
+	}
+	return p
+}
+
+// boundingBox 筆跡的邊界框，沒有筆跡時回傳空的 Rectangle
+func (p *plane) boundingBox() image.Rectangle {
+	box := image.Rectangle{}
+	for y := 0; y < p.height; y++ {
+		for x := 0; x < p.width; x++ {
+			if p.at(x, y) > inkThreshold {
+				box = box.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return box
+}
+
+// crop 取出 r 範圍內的像素，超出 plane 的部分為背景
+func (p *plane) crop(r image.Rectangle) *plane {
+	out := newPlane(r.Dx(), r.Dy())
+	for y := 0; y < out.height; y++ {
+		for x := 0; x < out.width; x++ {
+			sx, sy := r.Min.X+x, r.Min.Y+y
+			if sx >= 0 && sx < p.width && sy >= 0 && sy < p.height {
+				out.set(x, y, p.at(sx, sy))
+			}
+		}
+	}
+	return out
+}
+
+// trim 裁掉四周沒有筆跡的部分
+func (p *plane) trim() *plane {
+	box := p.boundingBox()
+	if box.Empty() {
+		return p
+	}
+	return p.crop(box)
+}
+
+// centerOfMass 以像素中心 (x+0.5, y+0.5) 計算的墨水質心
+func (p *plane) centerOfMass() (cx, cy float64) {
+	total := 0.0
+	for y := 0; y < p.height; y++ {
+		for x := 0; x < p.width; x++ {
+			v := p.at(x, y)
+			total += v
+			cx += v * (float64(x) + 0.5)
+			cy += v * (float64(y) + 0.5)
+		}
+	}
+	if total == 0 {
+		return float64(p.width) / 2, float64(p.height) / 2
+	}
+	return cx / total, cy / total
+}
+
+// skew 以二階 central moments 估計的傾斜 mu11 / mu02，限制在 [-1, 1]
+func (p *plane) skew() float64 {
+	cx, cy := p.centerOfMass()
+	var mu11, mu02 float64
+	for y := 0; y < p.height; y++ {
+		for x := 0; x < p.width; x++ {
+			v := p.at(x, y)
+			dx, dy := float64(x)+0.5-cx, float64(y)+0.5-cy
+			mu11 += v * dx * dy
+			mu02 += v * dy * dy
+		}
+	}
+	if mu02 < 1e-9 {
+		return 0
+	}
+	return max(-1, min(1, mu11/mu02))
+}
+
+// deskew 以水平 shear 把傾斜的筆跡轉正：輸出 (x, y) 取自輸入 (x + skew * (y - cy), y)
+func (p *plane) deskew(skew float64) *plane {
+	if skew == 0 {
+		return p
+	}
+	_, cy := p.centerOfMass()
+	pad := int(math.Ceil(math.Abs(skew)*float64(p.height))) + 1
+	out := newPlane(p.width+2*pad, p.height)
+	for y := 0; y < out.height; y++ {
+		shift := skew * (float64(y) + 0.5 - cy)
+		for x := 0; x < out.width; x++ {
+			// 水平方向的線性插值
+			sx := float64(x-pad) + shift
+			x0 := int(math.Floor(sx))
+			frac := sx - float64(x0)
+			v := 0.0
+			if x0 >= 0 && x0 < p.width {
+				v += (1 - frac) * p.at(x0, y)
+			}
+			if x0+1 >= 0 && x0+1 < p.width {
+				v += frac * p.at(x0+1, y)
+			}
+			out.set(x, y, v)
+		}
+	}
+	return out.trim()
+}
+
+// strokeWidth 以面積與邊界像素數估計的筆畫寬度：寬 w、長 L 的筆畫面積約 wL，邊界約 2L
+func (p *plane) strokeWidth() float64 {
+	area, boundary := 0, 0
+	ink := func(x, y int) bool {
+		return x >= 0 && x < p.width && y >= 0 && y < p.height && p.at(x, y) >= 0.5
+	}
+	for y := 0; y < p.height; y++ {
+		for x := 0; x < p.width; x++ {
+			if !ink(x, y) {
+				continue
+			}
+			area++
+			if !ink(x-1, y) || !ink(x+1, y) || !ink(x, y-1) || !ink(x, y+1) {
+				boundary++
+			}
+		}
+	}
+	if boundary == 0 {
+		return 0
+	}
+	return 2 * float64(area) / float64(boundary)
+}
+
+// setStrokeWidth 以 dilation 或 erosion 把筆畫寬度調整到 width，結果為二值影像
+func (p *plane) setStrokeWidth(width float64) *plane {
+	current := p.strokeWidth()
+	radius := math.Round((width - current) / 2)
+	if current == 0 || radius == 0 {
+		return p
+	}
+	pad := int(max(radius, 0)) + 1
+	padded := p.crop(image.Rect(-pad, -pad, p.width+pad, p.height+pad))
+	mask := make([]bool, len(padded.values))
+	for i, v := range padded.values {
+		mask[i] = v >= 0.5
+	}
+	out := newPlane(padded.width, padded.height)
+	if radius > 0 {
+		// dilation：與筆跡的距離不超過 radius 的像素
+		distance := distanceTransform(mask, padded.width, padded.height, true)
+		for i, d := range distance {
+			if d <= radius {
+				out.values[i] = 1
+			}
+		}
+	} else {
+		// erosion：與背景的距離超過 -radius 的像素，筆畫太細時保留原本的筆跡
+		distance := distanceTransform(mask, padded.width, padded.height, false)
+		kept := 0
+		for i, d := range distance {
+			if mask[i] && d > -radius {
+				out.values[i] = 1
+				kept++
+			}
+		}
+		if kept == 0 {
+			return p
+		}
+	}
+	return out.trim()
+}
+
+// distanceTransform 每個像素到最近的目標像素的距離（8 鄰域 chamfer 近似歐氏距離）
+// toMask 為 true 時目標為 mask 中的像素，否則為 mask 以外的像素（影像外視為背景）
+func distanceTransform(mask []bool, width, height int, toMask bool) []float64 {
+	const diagonal = math.Sqrt2
+	inf := float64(width + height)
+	distance := make([]float64, len(mask))
+	for i, m := range mask {
+		if m == toMask {
+			distance[i] = 0
+		} else {
+			distance[i] = inf
+		}
+	}
+	get := func(x, y int) float64 {
+		if x < 0 || x >= width || y < 0 || y >= height {
+			if toMask {
+				return inf
+			}
+			return 0
+		}
+		return distance[y*width+x]
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := y*width + x
+			distance[i] = min(distance[i], get(x-1, y)+1, get(x, y-1)+1, get(x-1, y-1)+diagonal, get(x+1, y-1)+diagonal)
+		}
+	}
+	for y := height - 1; y >= 0; y-- {
+		for x := width - 1; x >= 0; x-- {
+			i := y*width + x
+			distance[i] = min(distance[i], get(x+1, y)+1, get(x, y+1)+1, get(x+1, y+1)+diagonal, get(x-1, y+1)+diagonal)
+		}
+	}
+	return distance
+}
@@ -0,0 +1,214 @@
+// Package preprocess 把手寫的數字（淺色背景、深色筆跡，任意大小）轉成模型輸入
+// 可選擇置中方式、縮小的重新取樣方法、是否 deskew 與統一筆畫寬度
+package preprocess
+
+import (
+	"fmt"
+	"golang-neural-network/nn"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+)
+
+// Centering 數字放進模型輸入時對齊中心的方式
+type Centering string
+
+const (
+	CenterBox  Centering = "box"  // 邊界框置中（原本手寫板的做法）
+	CenterMass Centering = "mass" // 墨水質心放在中心（原始 MNIST 的做法）
+)
+
+// Resampling 把數字縮小到 20x20 區域時的重新取樣方法
+type Resampling string
+
+const (
+	ResampleBilinear Resampling = "bilinear" // 固定比例取樣，縮小很多時有 aliasing
+	ResampleArea     Resampling = "area"     // 平均每個輸出像素涵蓋的面積
+	ResampleLanczos  Resampling = "lanczos"  // Lanczos-3
+)
+
+// 步驟名稱，用於 Parse 與 String
+const (
+	stepDeskew = "deskew"
+	stepStroke = "stroke"
+)
+
+// strokeRatio 統一筆畫寬度時，筆畫寬度佔數字邊界框長邊的比例（MNIST 約 2.5 像素 / 20 像素）
+const strokeRatio = 0.12
+
+// Options 預處理的步驟
+type Options struct {
+	Centering  Centering
+	Resampling Resampling
+	Deskew     bool // 以 image moments 修正傾斜
+	Stroke     bool // 把筆畫寬度調整到 strokeRatio
+}
+
+// 預設的組合
+var (
+	// Legacy 原本手寫板的預處理
+	Legacy = Options{Centering: CenterBox, Resampling: ResampleBilinear}
+	// MNIST 與原始 MNIST 相同：anti-aliased 縮小後以質心置中，手寫板與 predict 預設使用
+	MNIST = Options{Centering: CenterMass, Resampling: ResampleArea}
+)
+
+// Presets 可以用名稱指定的組合
+var Presets = map[string]Options{
+	"legacy": Legacy,
+	"mnist":  MNIST,
+}
+
+// Parse 解析預設組合的名稱，或以逗號分隔的步驟，例如 "mass,lanczos,deskew,stroke"
+// 沒有指定的置中方式與重新取樣方法使用 MNIST 的設定
+func Parse(spec string) (Options, error) {
+	if options, ok := Presets[spec]; ok {
+		return options, nil
+	}
+	options := MNIST
+	for _, step := range strings.Split(spec, ",") {
+		switch step = strings.TrimSpace(step); step {
+		case string(CenterBox), string(CenterMass):
+			options.Centering = Centering(step)
+		case string(ResampleBilinear), string(ResampleArea), string(ResampleLanczos):
+			options.Resampling = Resampling(step)
+		case stepDeskew:
+			options.Deskew = true
+		case stepStroke:
+			options.Stroke = true
+		default:
+			return Options{}, fmt.Errorf("Unknown preprocessing step %q, use box, mass, bilinear, area, lanczos, deskew, stroke or one of legacy, mnist", step)
+		}
+	}
+	return options, nil
+}
+
+// String 以 Parse 接受的格式描述所有步驟
+func (o Options) String() string {
+	steps := []string{string(o.Centering), string(o.Resampling)}
+	if o.Deskew {
+		steps = append(steps, stepDeskew)
+	}
+	if o.Stroke {
+		steps = append(steps, stepStroke)
+	}
+	return strings.Join(steps, ",")
+}
+
+// Info 預處理實際套用的裁切、縮放與置中
+type Info struct {
+	Found   bool            // 是否有筆跡
+	Box     image.Rectangle // 筆跡在原圖中的邊界框
+	Skew    float64         // deskew 修正的傾斜，未 deskew 時為 0
+	Stroke  float64         // 調整前估計的筆畫寬度（原圖像素），未調整時為 0
+	Scale   float64         // 縮放的比例
+	Placed  image.Rectangle // 縮放後的數字在模型輸入中的位置
+	CenterX float64         // 對齊到輸入中心的點（邊界框中心或質心），模型輸入的座標
+	CenterY float64
+}
+
+// Process 依 options 把 src 預處理成模型輸入大小、白色背景深色筆跡的影像
+// 數字縮放到長邊為輸入短邊的 20/28（MNIST 時為 20x20）再放進輸入中
+// 彩色模型（3 通道）只依 options.Resampling 把整張影像縮放到輸入大小
+func Process(src image.Image, shape nn.ImageShape, options Options) (*image.RGBA, Info) {
+	if shape.Channels == 3 {
+		return processColor(src, shape, options.Resampling)
+	}
+	width, height := shape.Width, shape.Height
+	ink := inkPlane(src)
+	info := Info{Box: ink.boundingBox()}
+	if info.Box.Empty() {
+		return render(newPlane(width, height)), info
+	}
+	info.Found = true
+	digit := ink.crop(info.Box)
+
+	if options.Deskew {
+		info.Skew = digit.skew()
+		digit = digit.deskew(info.Skew)
+	}
+	if options.Stroke {
+		info.Stroke = digit.strokeWidth()
+		digit = digit.setStrokeWidth(strokeRatio * float64(max(digit.width, digit.height)))
+	}
+
+	// 保持長寬比縮放
+	target := float64(min(width, height)) * 20.0 / 28.0
+	info.Scale = target / float64(max(digit.width, digit.height))
+	newWidth := max(1, min(width, int(float64(digit.width)*info.Scale)))
+	newHeight := max(1, min(height, int(float64(digit.height)*info.Scale)))
+	resized := digit.resize(newWidth, newHeight, options.Resampling, false)
+
+	offsetX, offsetY := (width-newWidth)/2, (height-newHeight)/2
+	if options.Centering == CenterMass {
+		// 質心移到輸入中心，但不讓數字超出輸入的範圍
+		cx, cy := resized.centerOfMass()
+		offsetX = max(0, min(width-newWidth, int(math.Round(float64(width)/2-cx))))
+		offsetY = max(0, min(height-newHeight, int(math.Round(float64(height)/2-cy))))
+		info.CenterX, info.CenterY = float64(offsetX)+cx, float64(offsetY)+cy
+	} else {
+		info.CenterX = float64(offsetX) + float64(newWidth)/2
+		info.CenterY = float64(offsetY) + float64(newHeight)/2
+	}
+	info.Placed = image.Rect(offsetX, offsetY, offsetX+newWidth, offsetY+newHeight)
+
+	out := newPlane(width, height)
+	for y := 0; y < newHeight; y++ {
+		for x := 0; x < newWidth; x++ {
+			out.set(offsetX+x, offsetY+y, resized.at(x, y))
+		}
+	}
+	return render(out), info
+}
+
+// processColor 彩色影像（例如 CIFAR-10 的照片）不是筆跡，保留 R、G、B 三個通道，
+// 不裁切、置中、deskew 或調整筆畫，整張直接縮放到 H x W
+func processColor(src image.Image, shape nn.ImageShape, method Resampling) (*image.RGBA, Info) {
+	width, height := shape.Width, shape.Height
+	bounds := src.Bounds()
+	if bounds.Empty() {
+		return render(newPlane(width, height)), Info{}
+	}
+	var channels [3]*plane
+	for c := range channels {
+		channels[c] = newPlane(bounds.Dx(), bounds.Dy())
+	}
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			r, g, b, _ := src.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			channels[0].set(x, y, float64(r)/65535)
+			channels[1].set(x, y, float64(g)/65535)
+			channels[2].set(x, y, float64(b)/65535)
+		}
+	}
+	for c := range channels {
+		channels[c] = channels[c].resize(width, height, method, true)
+	}
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	level := func(c, x, y int) uint8 { return uint8(math.Round(255 * channels[c].at(x, y))) }
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetRGBA(x, y, color.RGBA{R: level(0, x, y), G: level(1, x, y), B: level(2, x, y), A: 255})
+		}
+	}
+	return img, Info{
+		Found:   true,
+		Box:     image.Rect(0, 0, bounds.Dx(), bounds.Dy()),
+		Scale:   float64(width) / float64(bounds.Dx()),
+		Placed:  image.Rect(0, 0, width, height),
+		CenterX: float64(width) / 2,
+		CenterY: float64(height) / 2,
+	}
+}
+
+// render 把墨水量轉回白色背景、黑色筆跡的影像
+func render(p *plane) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, p.width, p.height))
+	for y := 0; y < p.height; y++ {
+		for x := 0; x < p.width; x++ {
+			gray := uint8(math.Round(255 * (1 - max(0, min(1, p.at(x, y))))))
+			img.SetRGBA(x, y, color.RGBA{R: gray, G: gray, B: gray, A: 255})
+		}
+	}
+	return img
+}
@@ -0,0 +1,188 @@
+package preprocess
+
+import (
+	"golang-neural-network/nn"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"testing"
+)
+
+// canvas 白色背景的畫布，rects 以黑色填滿
+func canvas(size int, rects ...image.Rectangle) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	for _, r := range rects {
+		draw.Draw(img, r, image.Black, image.Point{}, draw.Src)
+	}
+	return img
+}
+
+func TestParse(t *testing.T) {
+	options, err := Parse("lanczos, deskew,stroke")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Options{Centering: CenterMass, Resampling: ResampleLanczos, Deskew: true, Stroke: true}
+	if options != want {
+		t.Errorf("Parse = %+v, want %+v", options, want)
+	}
+	if options.String() != "mass,lanczos,deskew,stroke" {
+		t.Errorf("String = %q", options.String())
+	}
+	if legacy, _ := Parse("legacy"); legacy != Legacy || legacy.String() != "box,bilinear" {
+		t.Errorf("legacy preset %+v", legacy)
+	}
+	if _, err := Parse("mass,blur"); err == nil {
+		t.Error("expected an error for an unknown step")
+	}
+}
+
+func TestProcessBlank(t *testing.T) {
+	img, info := Process(canvas(100), nn.MNISTShape, MNIST)
+	if info.Found || img.Bounds().Dx() != 28 {
+		t.Fatalf("info %+v, size %v", info, img.Bounds())
+	}
+	if Input(img, nn.MNISTShape).At(400, 0) != 0 {
+		t.Error("blank canvas produced ink")
+	}
+}
+
+func TestProcessCentering(t *testing.T) {
+	// L 形：墨水集中在左邊，質心偏離邊界框中心
+	src := canvas(560, image.Rect(100, 100, 180, 400), image.Rect(100, 320, 300, 400))
+
+	_, box := Process(src, nn.MNISTShape, Options{Centering: CenterBox, Resampling: ResampleArea})
+	if box.Box != image.Rect(100, 100, 300, 400) || box.Placed.Dy() != 20 {
+		t.Fatalf("box centering %+v", box)
+	}
+	if math.Abs(box.CenterX-14) > 0.5 || math.Abs(box.CenterY-14) > 0.5 {
+		t.Errorf("bounding box center at (%v, %v)", box.CenterX, box.CenterY)
+	}
+
+	img, mass := Process(src, nn.MNISTShape, MNIST)
+	input := Input(img, nn.MNISTShape)
+	var total, cx, cy float64
+	for i := 0; i < 784; i++ {
+		v := input.At(i, 0)
+		total += v
+		cx += v * (float64(i%28) + 0.5)
+		cy += v * (float64(i/28) + 0.5)
+	}
+	cx, cy = cx/total, cy/total
+	if math.Abs(cx-14) > 0.5 || math.Abs(cy-14) > 0.5 {
+		t.Errorf("center of mass at (%.2f, %.2f), want (14, 14)", cx, cy)
+	}
+	if math.Abs(cx-mass.CenterX) > 0.01 || math.Abs(cy-mass.CenterY) > 0.01 {
+		t.Errorf("info center (%v, %v), measured (%v, %v)", mass.CenterX, mass.CenterY, cx, cy)
+	}
+	if mass.Placed.Min.X <= box.Placed.Min.X {
+		t.Errorf("mass centering should move the L to the right: %v vs %v", mass.Placed, box.Placed)
+	}
+}
+
+func TestAreaResamplingAvoidsAliasing(t *testing.T) {
+	// 1 像素寬的直線條紋，面積平均後每個像素都是一半的墨水
+	stripes := newPlane(200, 200)
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 200; x += 2 {
+			stripes.set(x, y, 1)
+		}
+	}
+	for _, method := range []Resampling{ResampleArea, ResampleLanczos} {
+		// 邊緣外視為背景，只檢查內部的像素
+		small := stripes.resize(20, 20, method, false)
+		for y := 1; y < 19; y++ {
+			for x := 1; x < 19; x++ {
+				if v := small.at(x, y); math.Abs(v-0.5) > 0.05 {
+					t.Fatalf("%s: value %v at (%d, %d), want 0.5", method, v, x, y)
+				}
+			}
+		}
+	}
+	// 固定比例取樣只會取到條紋或空隙
+	aliased := stripes.resize(20, 20, ResampleBilinear, false)
+	extreme := 0
+	for _, v := range aliased.values {
+		if v < 0.1 || v > 0.9 {
+			extreme++
+		}
+	}
+	if extreme == 0 {
+		t.Error("expected aliasing with bilinear sampling")
+	}
+}
+
+func TestDeskew(t *testing.T) {
+	// 向右傾斜的粗線
+	slanted := newPlane(120, 200)
+	for y := 0; y < 200; y++ {
+		x0 := 80 - y*2/5
+		for x := x0; x < x0+20; x++ {
+			slanted.set(x, y, 1)
+		}
+	}
+	skew := slanted.skew()
+	if skew > -0.3 {
+		t.Fatalf("skew %v, want about -0.4", skew)
+	}
+	upright := slanted.deskew(skew)
+	if s := upright.skew(); math.Abs(s) > 0.02 {
+		t.Errorf("skew after deskew %v", s)
+	}
+	if upright.width > 30 {
+		t.Errorf("deskewed width %d, want about 20", upright.width)
+	}
+}
+
+func TestSetStrokeWidth(t *testing.T) {
+	line := newPlane(200, 200)
+	for y := 20; y < 180; y++ {
+		for x := 99; x < 101; x++ {
+			line.set(x, y, 1)
+		}
+	}
+	if w := line.strokeWidth(); math.Abs(w-2) > 0.5 {
+		t.Fatalf("stroke width %v, want 2", w)
+	}
+	thick := line.setStrokeWidth(12)
+	if w := thick.strokeWidth(); math.Abs(w-12) > 2 {
+		t.Errorf("stroke width after dilation %v, want 12", w)
+	}
+	thin := thick.setStrokeWidth(4)
+	if w := thin.strokeWidth(); math.Abs(w-4) > 1.5 {
+		t.Errorf("stroke width after erosion %v, want 4", w)
+	}
+}
+
+func TestInputGrayscale(t *testing.T) {
+	img := canvas(4, image.Rect(1, 0, 2, 1))
+	img.Set(2, 0, color.RGBA{R: 128, G: 128, B: 128, A: 255})
+	input := Input(img, nn.ImageShape{Channels: 1, Height: 4, Width: 4})
+	if input.At(0, 0) != 0 || input.At(1, 0) != 1 || math.Abs(input.At(2, 0)-0.498) > 0.01 {
+		t.Errorf("input %v %v %v", input.At(0, 0), input.At(1, 0), input.At(2, 0))
+	}
+}
+
+func TestProcessKeepsColorChannels(t *testing.T) {
+	// 左半紅色、右半藍色
+	src := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	draw.Draw(src, image.Rect(0, 0, 32, 64), &image.Uniform{C: color.RGBA{R: 255, A: 255}}, image.Point{}, draw.Src)
+	draw.Draw(src, image.Rect(32, 0, 64, 64), &image.Uniform{C: color.RGBA{B: 255, A: 255}}, image.Point{}, draw.Src)
+
+	for _, options := range []Options{MNIST, Legacy, {Centering: CenterMass, Resampling: ResampleLanczos, Deskew: true, Stroke: true}} {
+		img, _ := Process(src, nn.CIFAR10Shape, options)
+		input := Input(img, nn.CIFAR10Shape)
+		if rows, _ := input.Dims(); rows != 3*32*32 {
+			t.Fatalf("%s: %d inputs", options, rows)
+		}
+		plane := 32 * 32
+		left, right := 16*32+4, 16*32+27
+		red := func(i int) float64 { return input.At(i, 0) }
+		blue := func(i int) float64 { return input.At(2*plane+i, 0) }
+		if red(left) < 0.99 || blue(left) > 0.01 || red(right) > 0.01 || blue(right) < 0.99 {
+			t.Errorf("%s: left R=%.2f B=%.2f, right R=%.2f B=%.2f", options, red(left), blue(left), red(right), blue(right))
+		}
+	}
+}
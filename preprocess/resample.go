@@ -0,0 +1,121 @@
+package preprocess
+
+import "math"
+
+// tap 一個輸出像素取用的輸入像素與權重
+type tap struct {
+	index  int
+	weight float64
+}
+
+// lanczosA Lanczos kernel 的視窗大小
+const lanczosA = 3
+
+// resize 把 plane 縮放到 width x height，先水平再垂直各做一次一維重新取樣
+// clampEdges 為 false 時 plane 外視為背景（裁切下來的筆跡），true 時以邊緣的像素延伸（整張彩色影像）
+func (p *plane) resize(width, height int, method Resampling, clampEdges bool) *plane {
+	columns := axisTaps(p.width, width, method, clampEdges)
+	rows := axisTaps(p.height, height, method, clampEdges)
+
+	horizontal := newPlane(width, p.height)
+	for y := 0; y < p.height; y++ {
+		for x, taps := range columns {
+			v := 0.0
+			for _, t := range taps {
+				v += t.weight * p.at(t.index, y)
+			}
+			horizontal.set(x, y, v)
+		}
+	}
+	out := newPlane(width, height)
+	for y, taps := range rows {
+		for x := 0; x < width; x++ {
+			v := 0.0
+			for _, t := range taps {
+				v += t.weight * horizontal.at(x, t.index)
+			}
+			// Lanczos 的負權重可能超出範圍
+			out.set(x, y, max(0, min(1, v)))
+		}
+	}
+	return out
+}
+
+// axisTaps 一個方向上由 src 個像素縮放到 dst 個像素時，每個輸出像素的權重
+func axisTaps(src, dst int, method Resampling, clampEdges bool) [][]tap {
+	taps := make([][]tap, dst)
+	ratio := float64(src) / float64(dst)
+	for i := range taps {
+		switch method {
+		case ResampleArea:
+			taps[i] = areaTaps(src, ratio, i)
+		case ResampleLanczos:
+			taps[i] = lanczosTaps(src, ratio, i, clampEdges)
+		default:
+			taps[i] = bilinearTaps(src, dst, i)
+		}
+	}
+	return taps
+}
+
+// bilinearTaps 原本手寫板的雙線性插值：以固定比例 (src-1)/dst 取樣，縮小很多時會跳過大部分像素而產生 aliasing
+func bilinearTaps(src, dst, i int) []tap {
+	pos := float64(i) * float64(src-1) / float64(dst)
+	i0 := int(pos)
+	i1 := min(i0+1, src-1)
+	frac := pos - float64(i0)
+	return []tap{{i0, 1 - frac}, {i1, frac}}
+}
+
+// areaTaps 輸出像素涵蓋的輸入區間 [i*ratio, (i+1)*ratio) 內各像素的重疊比例
+func areaTaps(src int, ratio float64, i int) []tap {
+	lo, hi := float64(i)*ratio, float64(i+1)*ratio
+	var taps []tap
+	for j := int(math.Floor(lo)); j < int(math.Ceil(hi)) && j < src; j++ {
+		overlap := min(hi, float64(j+1)) - max(lo, float64(j))
+		if overlap > 0 {
+			taps = append(taps, tap{j, overlap / ratio})
+		}
+	}
+	return taps
+}
+
+// lanczosTaps Lanczos-3 重新取樣，縮小時 kernel 依比例放寬以達到 anti-aliasing
+func lanczosTaps(src int, ratio float64, i int, clampEdges bool) []tap {
+	stretch := max(ratio, 1)
+	center := (float64(i)+0.5)*ratio - 0.5
+	support := lanczosA * stretch
+	var taps []tap
+	total := 0.0
+	for j := int(math.Floor(center - support)); j <= int(math.Ceil(center+support)); j++ {
+		w := lanczos((float64(j) - center) / stretch)
+		if w == 0 {
+			continue
+		}
+		// 範圍外：以邊緣的像素延伸，或是背景而只計入權重的總和
+		total += w
+		index := j
+		if j < 0 || j >= src {
+			if !clampEdges {
+				continue
+			}
+			index = max(0, min(src-1, j))
+		}
+		taps = append(taps, tap{index, w})
+	}
+	for k := range taps {
+		taps[k].weight /= total
+	}
+	return taps
+}
+
+func lanczos(x float64) float64 {
+	switch {
+	case x == 0:
+		return 1
+	case math.Abs(x) >= lanczosA:
+		return 0
+	}
+	px := math.Pi * x
+	return lanczosA * math.Sin(px) * math.Sin(px/lanczosA) / (px * px)
+}
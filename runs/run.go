@@ -0,0 +1,185 @@
+// Package runs 管理訓練紀錄的目錄：每次訓練一個目錄，包含設定、metrics log、模型與評估報告
+package runs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang-neural-network/nn"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// run 目錄中的檔案
+const (
+	ConfigFile  = "config.json"
+	MetricsFile = "metrics.jsonl"
+	ModelFile   = "model.json"
+	ReportFile  = "report.json"
+)
+
+// 訓練結束的狀態
+const (
+	StatusCompleted   = "completed"
+	StatusInterrupted = "interrupted"
+	StatusFailed      = "failed"
+)
+
+// Config 訓練時實際使用的設定（預設值都已經套用）
+type Config struct {
+	Dataset      string                `json:"dataset"`
+	Inputs       int                   `json:"inputs"`
+	Classes      int                   `json:"classes"`
+	Hidden       []int                 `json:"hidden"`
+	LearningRate float64               `json:"learning_rate"`
+	Epochs       int                   `json:"epochs"`
+	BatchSize    int                   `json:"batch_size"`
+	Workers      int                   `json:"workers"`
+	Seed         int64                 `json:"seed"`
+	Precision    string                `json:"precision"`
+	Loss         string                `json:"loss"`
+	Adversarial  *nn.AdversarialConfig `json:"adversarial,omitempty"`
+	Started      time.Time             `json:"started"`
+}
+
+// Report 訓練結束後的評估報告
+type Report struct {
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	Epochs     int        `json:"epochs"` // 完成的 epoch 數
+	Steps      int        `json:"steps"`
+	TrainLoss  float64    `json:"train_loss"`
+	Validation nn.Metrics `json:"validation,omitempty"` // 最終模型在驗證集上的指標
+	Duration   float64    `json:"duration_s"`
+	Model      string     `json:"model,omitempty"` // 另外儲存到 models/ 的路徑
+}
+
+// Run 一次訓練的目錄，Report 在訓練結束前為 nil
+type Run struct {
+	ID     string
+	Dir    string
+	Config Config
+	Report *Report
+}
+
+// Create 在 root 底下建立新的 run 目錄並寫入 config.json
+// 目錄名稱為開始時間加上資料集名稱，例如 20240131-142501-MNIST
+func Create(root string, config Config) (*Run, error) {
+	if config.Started.IsZero() {
+		config.Started = time.Now()
+	}
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, err
+	}
+	base := config.Started.Format("20060102-150405")
+	if config.Dataset != "" {
+		base += "-" + config.Dataset
+	}
+	id := base
+	for i := 2; ; i++ {
+		err := os.Mkdir(filepath.Join(root, id), 0755)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		id = fmt.Sprintf("%s-%d", base, i)
+	}
+	run := &Run{ID: id, Dir: filepath.Join(root, id), Config: config}
+	if err := writeJSON(run.Path(ConfigFile), config); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// Path run 目錄中某個檔案的路徑
+func (r *Run) Path(name string) string {
+	return filepath.Join(r.Dir, name)
+}
+
+// WriteReport 寫入 report.json
+func (r *Run) WriteReport(report Report) error {
+	if err := writeJSON(r.Path(ReportFile), report); err != nil {
+		return err
+	}
+	r.Report = &report
+	return nil
+}
+
+// Metrics 讀取 run 的 metrics log
+func (r *Run) Metrics() ([]nn.MetricsRecord, error) {
+	file, err := os.Open(r.Path(MetricsFile))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return nn.ReadMetrics(file)
+}
+
+// Load 讀取一個 run 目錄，沒有 report.json 時 Report 為 nil（訓練中或異常結束）
+func Load(dir string) (*Run, error) {
+	run := &Run{ID: filepath.Base(dir), Dir: dir}
+	if err := readJSON(run.Path(ConfigFile), &run.Config); err != nil {
+		return nil, err
+	}
+	var report Report
+	err := readJSON(run.Path(ReportFile), &report)
+	switch {
+	case err == nil:
+		run.Report = &report
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, err
+	}
+	return run, nil
+}
+
+// List 依開始時間列出 root 底下所有的 run，不是 run 的目錄會略過
+func List(root string) ([]*Run, error) {
+	entries, err := os.ReadDir(root)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var result []*Run
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		run, err := Load(filepath.Join(root, entry.Name()))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Reading run %s: %w", entry.Name(), err)
+		}
+		result = append(result, run)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Config.Started.Before(result[j].Config.Started)
+	})
+	return result, nil
+}
+
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
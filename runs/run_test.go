@@ -0,0 +1,60 @@
+package runs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCreateAndList(t *testing.T) {
+	root := t.TempDir()
+	started := time.Date(2024, 1, 31, 14, 25, 1, 0, time.UTC)
+	config := Config{Dataset: "MNIST", Hidden: []int{16, 8}, LearningRate: 0.01, Epochs: 3, Started: started}
+
+	first, err := Create(root, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 同一秒開始的第二個 run 不能覆蓋第一個
+	second, err := Create(root, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.ID != "20240131-142501-MNIST" || second.ID != "20240131-142501-MNIST-2" {
+		t.Fatalf("ids = %q, %q", first.ID, second.ID)
+	}
+	report := Report{Status: StatusCompleted, Epochs: 3, Steps: 30, TrainLoss: 0.2, Validation: map[string]float64{"accuracy": 0.9}}
+	if err := first.WriteReport(report); err != nil {
+		t.Fatal(err)
+	}
+	// 不是 run 的目錄與檔案會被略過
+	if err := os.Mkdir(filepath.Join(root, "notes"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := List(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("got %d runs, want 2", len(list))
+	}
+	got := list[0]
+	if got.ID != first.ID || len(got.Config.Hidden) != 2 || !got.Config.Started.Equal(started) {
+		t.Errorf("loaded config = %+v", got.Config)
+	}
+	if got.Report == nil || got.Report.Validation["accuracy"] != 0.9 || got.Report.Steps != 30 {
+		t.Errorf("loaded report = %+v", got.Report)
+	}
+	if list[1].Report != nil {
+		t.Errorf("run without report.json has report %+v", list[1].Report)
+	}
+}
+
+func TestListMissingRoot(t *testing.T) {
+	list, err := List(filepath.Join(t.TempDir(), "runs"))
+	if err != nil || list != nil {
+		t.Errorf("List = %v, %v; want nil, nil", list, err)
+	}
+}
@@ -0,0 +1,121 @@
+package tensorboard
+
+import (
+	"fmt"
+	"golang-neural-network/chart"
+	"golang-neural-network/nn"
+	"strings"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Callback 在 TrainingLoop 中把訓練過程寫入 TensorBoard event 檔
+//
+//	scalars:    train/batch_loss, train/grad_norm, train/learning_rate（每個 step）
+//	            train/loss, train/accuracy, val/<metric>（每個 epoch）
+//	histograms: weights/<layer>, gradients/<layer>（每 HistogramEvery 個 step）
+//	images:     misclassified/<n> 與 misclassified/labels（每個 epoch，驗證集中分類錯誤的影像）
+type Callback struct {
+	nn.BaseCallback
+	Writer         *Writer
+	HistogramEvery int // 每幾個 step 記錄一次直方圖，<= 0 時為每個 epoch 的最後一個 step
+	Images         int // 每個 epoch 最多記錄幾張分類錯誤的影像，0 代表不記錄
+}
+
+func (c *Callback) OnBatchEnd(state *nn.TrainingState) error {
+	step := int64(state.Step)
+	scalars := []struct {
+		tag   string
+		value float64
+	}{
+		{"train/batch_loss", state.BatchLoss},
+		{"train/grad_norm", state.GradNorm},
+		{"train/learning_rate", state.Network.LearningRate},
+	}
+	for _, s := range scalars {
+		if err := c.Writer.Scalar(s.tag, step, s.value); err != nil {
+			return err
+		}
+	}
+
+	every := c.HistogramEvery
+	histograms := state.Batch == state.Batches
+	if every > 0 {
+		histograms = state.Step%every == 0
+	}
+	if !histograms {
+		return nil
+	}
+	for _, p := range state.Network.Parameters() {
+		if err := c.Writer.Histogram("weights/"+p.Name, step, p.Data); err != nil {
+			return err
+		}
+	}
+	for _, g := range state.Gradients() {
+		if err := c.Writer.Histogram("gradients/"+g.Name, step, g.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Callback) OnEpochEnd(state *nn.TrainingState) error {
+	step := int64(state.Step)
+	if err := c.Writer.Scalar("train/loss", step, state.TrainLoss); err != nil {
+		return err
+	}
+	if state.Network.IsClassifier() {
+		if err := c.Writer.Scalar("train/accuracy", step, state.TrainAccuracy); err != nil {
+			return err
+		}
+	}
+	for name, value := range state.Validation {
+		if err := c.Writer.Scalar("val/"+name, step, value); err != nil {
+			return err
+		}
+	}
+	if c.Images > 0 && state.Network.IsClassifier() {
+		if err := c.misclassified(state); err != nil {
+			return err
+		}
+	}
+	return c.Writer.Flush()
+}
+
+func (c *Callback) OnTrainEnd(state *nn.TrainingState) error {
+	return c.Writer.Flush()
+}
+
+// misclassified 記錄驗證集中前 c.Images 張分類錯誤的影像，以及對應的正確與預測類別
+func (c *Callback) misclassified(state *nn.TrainingState) error {
+	network := state.Network
+	shape := network.ImageShape()
+	step := int64(state.Step)
+	table := []string{"| image | label | predicted |", "|---|---|---|"}
+	count := 0
+	for _, sample := range state.ValidationSet {
+		if count == c.Images {
+			break
+		}
+		output, err := network.Predict(sample.Input)
+		if err != nil {
+			return err
+		}
+		predicted, _ := nn.Argmax(output)
+		label, _ := nn.Argmax(sample.Target)
+		if predicted == label {
+			continue
+		}
+		tag := fmt.Sprintf("misclassified/%d", count)
+		img := chart.TensorImage(mat.Col(nil, 0, sample.Input), shape.Channels, shape.Height, shape.Width)
+		if err := c.Writer.Image(tag, step, img); err != nil {
+			return err
+		}
+		table = append(table, fmt.Sprintf("| %s | %d | %d |", tag, label, predicted))
+		count++
+	}
+	if count == 0 {
+		return nil
+	}
+	return c.Writer.Text("misclassified/labels", step, strings.Join(table, "\n"))
+}
@@ -0,0 +1,118 @@
+package tensorboard
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// 只實作寫出 Event 需要的 protobuf wire format
+// 欄位編號來自 tensorflow/core/util/event.proto 與 tensorflow/core/framework/summary.proto
+
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// Event
+const (
+	eventWallTime    = 1
+	eventStep        = 2
+	eventFileVersion = 3
+	eventSummary     = 5
+)
+
+// Summary 與 Summary.Value
+const (
+	summaryValue = 1
+
+	valueTag         = 1
+	valueSimpleValue = 2
+	valueImage       = 4
+	valueHisto       = 5
+	valueTensor      = 8
+	valueMetadata    = 9
+)
+
+// Summary.Image
+const (
+	imageHeight     = 1
+	imageWidth      = 2
+	imageColorspace = 3
+	imageEncoded    = 4
+)
+
+// HistogramProto
+const (
+	histoMin         = 1
+	histoMax         = 2
+	histoNum         = 3
+	histoSum         = 4
+	histoSumSquares  = 5
+	histoBucketLimit = 6
+	histoBucket      = 7
+)
+
+// SummaryMetadata、PluginData、TensorProto、TensorShapeProto
+const (
+	metadataPluginData = 1
+	pluginName         = 1
+
+	tensorDtype     = 1
+	tensorShape     = 2
+	tensorStringVal = 8
+	shapeDim        = 2
+	dimSize         = 1
+
+	dtString = 7
+)
+
+// protoBuffer 依序附加欄位的 protobuf 訊息
+type protoBuffer []byte
+
+func (b *protoBuffer) varint(v uint64) {
+	*b = binary.AppendUvarint(*b, v)
+}
+
+func (b *protoBuffer) key(field, wireType int) {
+	b.varint(uint64(field<<3 | wireType))
+}
+
+func (b *protoBuffer) int64(field int, v int64) {
+	b.key(field, wireVarint)
+	b.varint(uint64(v))
+}
+
+func (b *protoBuffer) double(field int, v float64) {
+	b.key(field, wireFixed64)
+	*b = binary.LittleEndian.AppendUint64(*b, math.Float64bits(v))
+}
+
+func (b *protoBuffer) float(field int, v float32) {
+	b.key(field, wireFixed32)
+	*b = binary.LittleEndian.AppendUint32(*b, math.Float32bits(v))
+}
+
+func (b *protoBuffer) bytes(field int, v []byte) {
+	b.key(field, wireBytes)
+	b.varint(uint64(len(v)))
+	*b = append(*b, v...)
+}
+
+func (b *protoBuffer) string(field int, v string) {
+	b.bytes(field, []byte(v))
+}
+
+func (b *protoBuffer) message(field int, m protoBuffer) {
+	b.bytes(field, m)
+}
+
+// packedDoubles repeated double 使用 packed 編碼
+func (b *protoBuffer) packedDoubles(field int, values []float64) {
+	var packed protoBuffer
+	for _, v := range values {
+		packed = binary.LittleEndian.AppendUint64(packed, math.Float64bits(v))
+	}
+	b.bytes(field, packed)
+}
@@ -0,0 +1,33 @@
+// Package tensorboard 寫出 TensorBoard 可以讀取的 event 檔（TFRecord 格式），不依賴 Python 或 protobuf 套件
+package tensorboard
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// maskedCRC TFRecord 使用的 masked CRC32-C
+func maskedCRC(data []byte) uint32 {
+	crc := crc32.Checksum(data, castagnoli)
+	return ((crc >> 15) | (crc << 17)) + 0xa282ead8
+}
+
+// writeRecord 以 TFRecord 格式寫入一筆資料：
+//
+//	uint64 length | uint32 masked_crc32c(length) | data | uint32 masked_crc32c(data)
+func writeRecord(w io.Writer, data []byte) error {
+	header := make([]byte, 12)
+	binary.LittleEndian.PutUint64(header, uint64(len(data)))
+	binary.LittleEndian.PutUint32(header[8:], maskedCRC(header[:8]))
+	footer := make([]byte, 4)
+	binary.LittleEndian.PutUint32(footer, maskedCRC(data))
+	for _, part := range [][]byte{header, data, footer} {
+		if _, err := w.Write(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package tensorboard
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// histogramBuckets 直方圖的 bucket 數量（TensorBoard 顯示時會再重新分組）
+const histogramBuckets = 30
+
+// Writer 寫出一個 event 檔，用 `tensorboard --logdir <dir>` 查看
+// 不是 goroutine-safe，TrainingLoop 的 callback 都在同一個 goroutine 呼叫
+type Writer struct {
+	path string
+	file *os.File
+	buf  *bufio.Writer
+}
+
+// NewWriter 在 dir 建立 events.out.tfevents.<timestamp>.<hostname>，並寫入檔案版本
+func NewWriter(dir string) (*Writer, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		host = "localhost"
+	}
+	path := filepath.Join(dir, fmt.Sprintf("events.out.tfevents.%d.%s", time.Now().Unix(), host))
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w := &Writer{path: path, file: file, buf: bufio.NewWriter(file)}
+	var event protoBuffer
+	event.double(eventWallTime, wallTime())
+	event.string(eventFileVersion, "brain.Event:2")
+	if err := writeRecord(w.buf, event); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return w, w.Flush()
+}
+
+// Path event 檔的路徑
+func (w *Writer) Path() string {
+	return w.path
+}
+
+func wallTime() float64 {
+	return float64(time.Now().UnixNano()) / 1e9
+}
+
+// writeSummary 寫入一個只包含一個 Summary.Value 的 Event
+func (w *Writer) writeSummary(step int64, value protoBuffer) error {
+	var summary protoBuffer
+	summary.message(summaryValue, value)
+	var event protoBuffer
+	event.double(eventWallTime, wallTime())
+	event.int64(eventStep, step)
+	event.message(eventSummary, summary)
+	return writeRecord(w.buf, event)
+}
+
+// Scalar 寫入一個純量，例如 loss 或 learning rate
+func (w *Writer) Scalar(tag string, step int64, value float64) error {
+	var v protoBuffer
+	v.string(valueTag, tag)
+	v.float(valueSimpleValue, float32(value))
+	return w.writeSummary(step, v)
+}
+
+// Histogram 寫入 values 的分佈，例如一層的權重或梯度
+func (w *Writer) Histogram(tag string, step int64, values []float64) error {
+	if len(values) == 0 {
+		return nil
+	}
+	var v protoBuffer
+	v.string(valueTag, tag)
+	v.message(valueHisto, histogram(values))
+	return w.writeSummary(step, v)
+}
+
+// histogram 等寬分組的 HistogramProto，bucket_limit 為每個 bucket 的右邊界
+func histogram(values []float64) protoBuffer {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	sum, sumSquares := 0.0, 0.0
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+		sum += v
+		sumSquares += v * v
+	}
+	buckets := histogramBuckets
+	if lo == hi {
+		buckets = 1
+	}
+	width := (hi - lo) / float64(buckets)
+	limits := make([]float64, buckets)
+	counts := make([]float64, buckets)
+	for i := range limits {
+		limits[i] = lo + float64(i+1)*width
+	}
+	limits[buckets-1] = hi
+	for _, v := range values {
+		i := buckets - 1
+		if width > 0 {
+			i = min(int((v-lo)/width), buckets-1)
+		}
+		counts[i]++
+	}
+
+	var h protoBuffer
+	h.double(histoMin, lo)
+	h.double(histoMax, hi)
+	h.double(histoNum, float64(len(values)))
+	h.double(histoSum, sum)
+	h.double(histoSumSquares, sumSquares)
+	h.packedDoubles(histoBucketLimit, limits)
+	h.packedDoubles(histoBucket, counts)
+	return h
+}
+
+// Image 以 PNG 寫入一張影像
+func (w *Writer) Image(tag string, step int64, img image.Image) error {
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, img); err != nil {
+		return err
+	}
+	colorspace := int64(3) // RGB
+	if _, ok := img.(*image.Gray); ok {
+		colorspace = 1
+	}
+	bounds := img.Bounds()
+	var im protoBuffer
+	im.int64(imageHeight, int64(bounds.Dy()))
+	im.int64(imageWidth, int64(bounds.Dx()))
+	im.int64(imageColorspace, colorspace)
+	im.bytes(imageEncoded, encoded.Bytes())
+
+	var v protoBuffer
+	v.string(valueTag, tag)
+	v.message(valueImage, im)
+	return w.writeSummary(step, v)
+}
+
+// Text 寫入一段文字（TensorBoard 的 Text 分頁，支援 Markdown）
+func (w *Writer) Text(tag string, step int64, text string) error {
+	var plugin protoBuffer
+	plugin.string(pluginName, "text")
+	var metadata protoBuffer
+	metadata.message(metadataPluginData, plugin)
+
+	var dim protoBuffer
+	dim.int64(dimSize, 1)
+	var shape protoBuffer
+	shape.message(shapeDim, dim)
+	var tensor protoBuffer
+	tensor.int64(tensorDtype, dtString)
+	tensor.message(tensorShape, shape)
+	tensor.string(tensorStringVal, text)
+
+	var v protoBuffer
+	v.string(valueTag, tag)
+	v.message(valueMetadata, metadata)
+	v.message(valueTensor, tensor)
+	return w.writeSummary(step, v)
+}
+
+// Flush 把緩衝的 event 寫入檔案，TensorBoard 才看得到
+func (w *Writer) Flush() error {
+	return w.buf.Flush()
+}
+
+func (w *Writer) Close() error {
+	if err := w.Flush(); err != nil {
+		w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
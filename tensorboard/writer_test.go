@@ -0,0 +1,228 @@
+package tensorboard
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"golang-neural-network/nn"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// readRecords 讀取 TFRecord 檔並檢查每一筆的 CRC
+func readRecords(t *testing.T, path string) [][]byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var records [][]byte
+	for len(data) > 0 {
+		if len(data) < 16 {
+			t.Fatalf("truncated record: %d bytes left", len(data))
+		}
+		length := binary.LittleEndian.Uint64(data)
+		if got := binary.LittleEndian.Uint32(data[8:]); got != maskedCRC(data[:8]) {
+			t.Fatalf("length crc = %x, want %x", got, maskedCRC(data[:8]))
+		}
+		record := data[12 : 12+length]
+		if got := binary.LittleEndian.Uint32(data[12+length:]); got != maskedCRC(record) {
+			t.Fatalf("data crc = %x, want %x", got, maskedCRC(record))
+		}
+		records = append(records, record)
+		data = data[16+length:]
+	}
+	return records
+}
+
+// field 解碼後的 protobuf 欄位：varint / fixed 欄位放在 num，length-delimited 欄位放在 raw
+type field struct {
+	num uint64
+	raw []byte
+}
+
+// decode 把一個 protobuf 訊息解成 欄位編號 -> 值（依出現順序）
+func decode(t *testing.T, b []byte) map[int][]field {
+	t.Helper()
+	fields := map[int][]field{}
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		b = b[n:]
+		number, wire := int(key>>3), int(key&7)
+		switch wire {
+		case wireVarint:
+			v, n := binary.Uvarint(b)
+			fields[number] = append(fields[number], field{num: v})
+			b = b[n:]
+		case wireFixed64:
+			fields[number] = append(fields[number], field{num: binary.LittleEndian.Uint64(b)})
+			b = b[8:]
+		case wireFixed32:
+			fields[number] = append(fields[number], field{num: uint64(binary.LittleEndian.Uint32(b))})
+			b = b[4:]
+		case wireBytes:
+			length, n := binary.Uvarint(b)
+			fields[number] = append(fields[number], field{raw: b[n : n+int(length)]})
+			b = b[n+int(length):]
+		default:
+			t.Fatalf("unexpected wire type %d", wire)
+		}
+	}
+	return fields
+}
+
+// eventValue 回傳 event 的 step 與其中唯一的 Summary.Value
+func eventValue(t *testing.T, record []byte) (int64, map[int][]field) {
+	t.Helper()
+	event := decode(t, record)
+	summary := decode(t, event[eventSummary][0].raw)
+	return int64(event[eventStep][0].num), decode(t, summary[summaryValue][0].raw)
+}
+
+func TestMaskedCRC(t *testing.T) {
+	// CRC-32C 的標準檢查值
+	if got := crc32.Checksum([]byte("123456789"), castagnoli); got != 0xe3069283 {
+		t.Fatalf("crc32c = %x", got)
+	}
+	crc := uint32(0xe3069283)
+	if got, want := maskedCRC([]byte("123456789")), ((crc>>15)|(crc<<17))+0xa282ead8; got != want {
+		t.Errorf("masked crc = %x, want %x", got, want)
+	}
+}
+
+func TestWriterEvents(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewWriter(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(filepath.Base(w.Path()), "events.out.tfevents.") {
+		t.Errorf("event file name %q", w.Path())
+	}
+	if err := w.Scalar("train/loss", 7, 0.25); err != nil {
+		t.Fatal(err)
+	}
+	values := []float64{-1, 0, 0, 0.5, 2}
+	if err := w.Histogram("weights/output/weight", 8, values); err != nil {
+		t.Fatal(err)
+	}
+	img := image.NewGray(image.Rect(0, 0, 3, 2))
+	img.Pix[1] = 200
+	if err := w.Image("misclassified/0", 9, img); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Text("misclassified/labels", 9, "| a | b |"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	records := readRecords(t, w.Path())
+	if len(records) != 5 {
+		t.Fatalf("got %d records, want 5", len(records))
+	}
+	if version := decode(t, records[0])[eventFileVersion]; len(version) != 1 || string(version[0].raw) != "brain.Event:2" {
+		t.Errorf("first event has no file version")
+	}
+
+	step, scalar := eventValue(t, records[1])
+	if step != 7 || string(scalar[valueTag][0].raw) != "train/loss" || math.Float32frombits(uint32(scalar[valueSimpleValue][0].num)) != 0.25 {
+		t.Errorf("scalar event = step %d, %v", step, scalar)
+	}
+
+	_, histo := eventValue(t, records[2])
+	h := decode(t, histo[valueHisto][0].raw)
+	if math.Float64frombits(h[histoNum][0].num) != 5 || math.Float64frombits(h[histoMin][0].num) != -1 || math.Float64frombits(h[histoMax][0].num) != 2 {
+		t.Errorf("histogram = %v", h)
+	}
+	counts := h[histoBucket][0].raw
+	total := 0.0
+	for i := 0; i < len(counts); i += 8 {
+		total += math.Float64frombits(binary.LittleEndian.Uint64(counts[i:]))
+	}
+	if total != 5 || len(counts) != 8*histogramBuckets {
+		t.Errorf("bucket counts sum to %v over %d buckets", total, len(counts)/8)
+	}
+
+	_, imageValue := eventValue(t, records[3])
+	im := decode(t, imageValue[valueImage][0].raw)
+	decoded, err := png.Decode(bytes.NewReader(im[imageEncoded][0].raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if im[imageWidth][0].num != 3 || im[imageHeight][0].num != 2 || decoded.Bounds().Dx() != 3 {
+		t.Errorf("image = %v", im)
+	}
+
+	_, text := eventValue(t, records[4])
+	plugin := decode(t, decode(t, text[valueMetadata][0].raw)[metadataPluginData][0].raw)
+	tensor := decode(t, text[valueTensor][0].raw)
+	if string(plugin[pluginName][0].raw) != "text" || string(tensor[tensorStringVal][0].raw) != "| a | b |" {
+		t.Errorf("text event = %v", text)
+	}
+}
+
+func TestCallbackWritesTrainingSummaries(t *testing.T) {
+	// 隨機資料幾乎不可能全部分類正確，一定會有分類錯誤的影像
+	rng := rand.New(rand.NewSource(1))
+	var data []nn.TrainingData
+	for i := 0; i < 24; i++ {
+		input := mat.NewDense(16, 1, nil)
+		for j := 0; j < 16; j++ {
+			input.Set(j, 0, rng.Float64())
+		}
+		target := mat.NewDense(3, 1, nil)
+		target.Set(rng.Intn(3), 0, 1)
+		data = append(data, nn.TrainingData{Input: input, Target: target})
+	}
+	network, err := nn.NewNeuralNetwork(16, 3, []int{4}, 0.05)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewWriter(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	callback := &Callback{Writer: w, Images: 2}
+	config := nn.TrainingConfig{Epochs: 2, BatchSize: 8, Callbacks: []nn.Callback{callback}}
+	if err := nn.TrainingLoop(context.Background(), network, config, data, data[:12]); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tags := map[string]int{}
+	for _, record := range readRecords(t, w.Path())[1:] {
+		_, value := eventValue(t, record)
+		tags[string(value[valueTag][0].raw)]++
+	}
+	want := map[string]int{
+		"train/batch_loss":       6,
+		"train/learning_rate":    6,
+		"train/loss":             2,
+		"train/accuracy":         2,
+		"val/accuracy":           2,
+		"weights/hidden0/weight": 2,
+		"gradients/output/bias":  2,
+		"misclassified/0":        2,
+		"misclassified/1":        2,
+		"misclassified/labels":   2,
+	}
+	for tag, count := range want {
+		if tags[tag] != count {
+			t.Errorf("tag %s written %d times, want %d (all tags: %v)", tag, tags[tag], count, tags)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package visualize
+
+import (
+	"fmt"
+	"golang-neural-network/chart"
+	"golang-neural-network/nn"
+	"image"
+	"image/color"
+	"math/rand"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// 解釋方法的名稱，CLI 的 --explain 與手寫板的選單共用
+const (
+	MethodSaliency            = "saliency"
+	MethodSmoothGrad          = "smoothgrad"
+	MethodIntegratedGradients = "integrated-gradients"
+)
+
+// Methods 所有解釋方法
+var Methods = []string{MethodSaliency, MethodSmoothGrad, MethodIntegratedGradients}
+
+// Explain 以預設參數執行指定的解釋方法：SmoothGrad 50 份 15% 雜訊，integrated gradients 64 步
+// class < 0 時解釋模型預測的類別
+func Explain(model *nn.NeuralNetwork, input *mat.Dense, class int, method string) (nn.Attribution, error) {
+	switch method {
+	case MethodSaliency:
+		return nn.Saliency(model, input, class)
+	case MethodSmoothGrad:
+		return nn.SmoothGrad(model, input, class, 50, 0.15, rand.New(rand.NewSource(1)))
+	case MethodIntegratedGradients:
+		return nn.IntegratedGradients(model, input, class, 64)
+	default:
+		return nn.Attribution{}, fmt.Errorf("Unknown explanation method %q", method)
+	}
+}
+
+// HeatmapImage 把 0 到 1 的 heatmap（row-major）以黑、紅、黃、白的色階轉成影像
+func HeatmapImage(heat []float64, height, width int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for i, v := range heat {
+		img.SetNRGBA(i%width, i/width, hotColor(v))
+	}
+	return img
+}
+
+// hotColor 0 為黑色，依序經過紅色、黃色到 1 的白色
+func hotColor(t float64) color.NRGBA {
+	t = max(0, min(1, t))
+	channel := func(v float64) uint8 { return uint8(max(0, min(1, v)) * 255) }
+	return color.NRGBA{R: channel(3 * t), G: channel(3*t - 1), B: channel(3*t - 2), A: 255}
+}
+
+// Overlay 把 heatmap 疊在調暗的模型輸入影像上，貢獻越大越不透明
+func Overlay(input []float64, shape nn.ImageShape, heat []float64) image.Image {
+	base := chart.TensorImage(input, shape.Channels, shape.Height, shape.Width)
+	img := image.NewNRGBA(base.Bounds())
+	for i, v := range heat {
+		x, y := i%shape.Width, i/shape.Width
+		r, g, b, _ := base.At(x, y).RGBA()
+		hot := hotColor(0.35 + 0.65*v)
+		alpha := max(0, min(1, v))
+		blend := func(under uint32, over uint8) uint8 {
+			return uint8(0.6*float64(under>>8)*(1-alpha) + float64(over)*alpha)
+		}
+		img.SetNRGBA(x, y, color.NRGBA{R: blend(r, hot.R), G: blend(g, hot.G), B: blend(b, hot.B), A: 255})
+	}
+	return img
+}
+
+// ExplanationStrip 輸入、heatmap 與疊加結果三張並排，每張放大 scale 倍
+// heat 可以來自 nn.Attribution 或 nn.OcclusionMap 的 Heatmap
+func ExplanationStrip(input []float64, shape nn.ImageShape, heat []float64, scale int) *image.RGBA {
+	return chart.Tile([]image.Image{
+		chart.TensorImage(input, shape.Channels, shape.Height, shape.Width),
+		HeatmapImage(heat, shape.Height, shape.Width),
+		Overlay(input, shape, heat),
+	}, 3, scale, 2)
+}
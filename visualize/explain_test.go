@@ -0,0 +1,45 @@
+package visualize
+
+import (
+	"golang-neural-network/nn"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestExplainMethods(t *testing.T) {
+	model, err := nn.NewNeuralNetwork(784, 10, []int{16}, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := mat.NewDense(784, 1, nil)
+	for i := 300; i < 400; i++ {
+		input.Set(i, 0, 1)
+	}
+	for _, method := range Methods {
+		attribution, err := Explain(model, input, 3, method)
+		if err != nil {
+			t.Fatalf("%s: %v", method, err)
+		}
+		if attribution.Class != 3 || len(attribution.Values) != 784 {
+			t.Errorf("%s: class %d, %d values", method, attribution.Class, len(attribution.Values))
+		}
+		strip := ExplanationStrip(mat.Col(nil, 0, input), attribution.Shape, attribution.Heatmap(), 2)
+		// 三張 56x56 的影像與 2 像素的分隔線
+		if strip.Bounds().Dx() != 3*58+2 || strip.Bounds().Dy() != 58+2 {
+			t.Errorf("%s: strip size %v", method, strip.Bounds())
+		}
+	}
+	if _, err := Explain(model, input, 3, "lime"); err == nil {
+		t.Error("expected an error for an unknown method")
+	}
+}
+
+func TestHotColor(t *testing.T) {
+	if c := hotColor(0); c.R != 0 || c.G != 0 || c.B != 0 {
+		t.Errorf("hotColor(0) = %v, want black", c)
+	}
+	if c := hotColor(1); c.R != 255 || c.G != 255 || c.B != 255 {
+		t.Errorf("hotColor(1) = %v, want white", c)
+	}
+}
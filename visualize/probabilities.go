@@ -0,0 +1,99 @@
+package visualize
+
+import (
+	"fmt"
+	"golang-neural-network/chart"
+	"image/color"
+	"sort"
+	"strings"
+)
+
+// 預測類別的機率低於 UncertainBelow 時視為不確定，並標出機率至少 RunnerUpAbove 的其他類別
+const (
+	UncertainBelow = 0.8
+	RunnerUpAbove  = 0.05
+)
+
+// 機率長條圖的顏色：預測類別、候補類別與其他類別
+var (
+	predictedColor = chart.Palette[0]
+	runnerUpColor  = chart.Palette[1]
+	otherColor     = color.RGBA{R: 190, G: 190, B: 190, A: 255}
+)
+
+// RunnerUps 模型不確定時，依機率由大到小回傳預測類別以外值得注意的類別，確定時回傳 nil
+func RunnerUps(probs []float64) []int {
+	predicted := argmax(probs)
+	if predicted < 0 || probs[predicted] >= UncertainBelow {
+		return nil
+	}
+	var classes []int
+	for i, p := range probs {
+		if i != predicted && p >= RunnerUpAbove {
+			classes = append(classes, i)
+		}
+	}
+	sort.SliceStable(classes, func(a, b int) bool { return probs[classes[a]] > probs[classes[b]] })
+	return classes
+}
+
+// ProbabilityChart 所有類別機率的長條圖，預測類別與候補類別以不同顏色標出並顯示百分比
+// labels 為 nil 時使用索引
+func ProbabilityChart(probs []float64, labels []string) chart.BarChart {
+	bars := chart.BarChart{
+		Title:       ProbabilitySummary(probs, labels),
+		XLabel:      "class",
+		Labels:      labels,
+		Values:      probs,
+		Colors:      make([]color.Color, len(probs)),
+		Annotations: make([]string, len(probs)),
+		Max:         1,
+	}
+	for i := range probs {
+		bars.Colors[i] = otherColor
+	}
+	highlight := func(i int, col color.Color) {
+		bars.Colors[i] = col
+		bars.Annotations[i] = fmt.Sprintf("%.0f%%", probs[i]*100)
+	}
+	if predicted := argmax(probs); predicted >= 0 {
+		highlight(predicted, predictedColor)
+	}
+	for _, i := range RunnerUps(probs) {
+		highlight(i, runnerUpColor)
+	}
+	return bars
+}
+
+// ProbabilitySummary 一行預測結果，不確定時列出候補類別，例如 "Uncertain: 3 (45%) or 5 (30%)"
+func ProbabilitySummary(probs []float64, labels []string) string {
+	predicted := argmax(probs)
+	if predicted < 0 {
+		return "No prediction"
+	}
+	label := func(i int) string {
+		if i < len(labels) {
+			return labels[i]
+		}
+		return fmt.Sprint(i)
+	}
+	if probs[predicted] >= UncertainBelow {
+		return fmt.Sprintf("Prediction: %s (%.0f%%)", label(predicted), probs[predicted]*100)
+	}
+	parts := []string{fmt.Sprintf("%s (%.0f%%)", label(predicted), probs[predicted]*100)}
+	for _, i := range RunnerUps(probs) {
+		parts = append(parts, fmt.Sprintf("%s (%.0f%%)", label(i), probs[i]*100))
+	}
+	return "Uncertain: " + strings.Join(parts, " or ")
+}
+
+// argmax 最大值的索引，空切片時回傳 -1
+func argmax(values []float64) int {
+	best := -1
+	for i, v := range values {
+		if best < 0 || v > values[best] {
+			best = i
+		}
+	}
+	return best
+}
@@ -0,0 +1,42 @@
+package visualize
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRunnerUps(t *testing.T) {
+	cases := []struct {
+		probs []float64
+		want  []int
+	}{
+		{[]float64{0.01, 0.95, 0.04}, nil},
+		{[]float64{0.1, 0.45, 0.02, 0.3, 0.13}, []int{3, 4, 0}},
+		{[]float64{0.5, 0.48, 0.02}, []int{1}},
+		{nil, nil},
+	}
+	for _, tc := range cases {
+		if got := RunnerUps(tc.probs); !slices.Equal(got, tc.want) {
+			t.Errorf("RunnerUps(%v) = %v, want %v", tc.probs, got, tc.want)
+		}
+	}
+}
+
+func TestProbabilityChart(t *testing.T) {
+	probs := []float64{0.1, 0.45, 0.02, 0.3, 0.13}
+	bars := ProbabilityChart(probs, []string{"a", "b", "c", "d", "e"})
+	if bars.Title != "Uncertain: b (45%) or d (30%) or e (13%) or a (10%)" {
+		t.Errorf("title %q", bars.Title)
+	}
+	if bars.Colors[1] != predictedColor || bars.Colors[3] != runnerUpColor || bars.Colors[2] != otherColor {
+		t.Errorf("colors %v", bars.Colors)
+	}
+	if bars.Annotations[1] != "45%" || bars.Annotations[2] != "" {
+		t.Errorf("annotations %q", bars.Annotations)
+	}
+
+	confident := ProbabilityChart([]float64{0.02, 0.9, 0.08}, nil)
+	if confident.Title != "Prediction: 1 (90%)" || confident.Colors[2] != otherColor {
+		t.Errorf("confident chart %q %v", confident.Title, confident.Colors)
+	}
+}
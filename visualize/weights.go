@@ -0,0 +1,83 @@
+// Package visualize 把模型的權重轉成影像與圖表，供 CLI 與手寫板共用
+package visualize
+
+import (
+	"fmt"
+	"golang-neural-network/chart"
+	"golang-neural-network/nn"
+	"image"
+	"math"
+)
+
+// FeatureImages 把第一層權重的每一列（一個單元連到所有輸入的權重）依模型的輸入形狀轉成一張影像
+// 每張影像各自依最大絕對值正規化：0 為灰色，正權重偏白，負權重偏黑
+func FeatureImages(model *nn.NeuralNetwork) ([]image.Image, error) {
+	shape := model.ImageShape()
+	if shape.Height == 1 {
+		return nil, fmt.Errorf("Model input of size %d is not an image", model.Inputs)
+	}
+	weight := model.Parameters()[0]
+	images := make([]image.Image, weight.Rows)
+	for i := range images {
+		images[i] = signedImage(weight.Data[i*weight.Cols:(i+1)*weight.Cols], shape)
+	}
+	return images, nil
+}
+
+// FeatureGrid 把 FeatureImages 排成一張網格影像，每張放大 scale 倍
+func FeatureGrid(model *nn.NeuralNetwork, scale int) (*image.RGBA, error) {
+	images, err := FeatureImages(model)
+	if err != nil {
+		return nil, err
+	}
+	return chart.Tile(images, 0, scale, 1), nil
+}
+
+// signedImage 把通道優先（C x H x W）排列的權重以 [-m, m] 對應到 0-255，m 為最大絕對值
+func signedImage(values []float64, shape nn.ImageShape) image.Image {
+	m := 0.0
+	for _, v := range values {
+		m = math.Max(m, math.Abs(v))
+	}
+	pixel := func(v float64) uint8 {
+		if m == 0 {
+			return 128
+		}
+		return uint8(math.Round((v/m + 1) * 127.5))
+	}
+	plane := shape.Height * shape.Width
+	rect := image.Rect(0, 0, shape.Width, shape.Height)
+	if shape.Channels != 3 {
+		img := image.NewGray(rect)
+		for i := 0; i < plane; i++ {
+			img.Pix[i] = pixel(values[i])
+		}
+		return img
+	}
+	img := image.NewNRGBA(rect)
+	for i := 0; i < plane; i++ {
+		img.Pix[4*i] = pixel(values[i])
+		img.Pix[4*i+1] = pixel(values[plane+i])
+		img.Pix[4*i+2] = pixel(values[2*plane+i])
+		img.Pix[4*i+3] = 255
+	}
+	return img
+}
+
+// WeightHistograms 每一層的權重與 bias 各一張直方圖，weight 與 bias 兩欄並排
+func WeightHistograms(model *nn.NeuralNetwork) chart.Panels {
+	panels := chart.Panels{Title: "Weight and bias distributions", Columns: 2}
+	for _, p := range model.Parameters() {
+		panels.Charts = append(panels.Charts, chart.Histogram{
+			Title:  fmt.Sprintf("%s (%dx%d)", p.Name, p.Rows, p.Cols),
+			Values: p.Data,
+			Bins:   40,
+		})
+	}
+	return panels
+}
+
+// HistogramHeight WeightHistograms 的建議高度，每層一列
+func HistogramHeight(model *nn.NeuralNetwork) int {
+	return 28 + 220*len(model.Parameters())/2
+}
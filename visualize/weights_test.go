@@ -0,0 +1,67 @@
+package visualize
+
+import (
+	"golang-neural-network/nn"
+	"image"
+	"testing"
+)
+
+func TestFeatureImages(t *testing.T) {
+	model, err := nn.NewNeuralNetwork(784, 10, []int{12, 8}, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	images, err := FeatureImages(model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(images) != 12 {
+		t.Fatalf("got %d images, want one per first-layer unit", len(images))
+	}
+	gray := images[0].(*image.Gray)
+	if gray.Bounds().Dx() != 28 || gray.Bounds().Dy() != 28 {
+		t.Fatalf("image size %v", gray.Bounds())
+	}
+	// 最大絕對值的權重一定對應到 0 或 255
+	weight := model.Parameters()[0]
+	extreme := 0
+	for i, v := range weight.Data[:784] {
+		if v*v > weight.Data[extreme]*weight.Data[extreme] {
+			extreme = i
+		}
+	}
+	if p := gray.Pix[extreme]; p != 0 && p != 255 {
+		t.Errorf("largest weight maps to %d", p)
+	}
+
+	grid, err := FeatureGrid(model, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 4 x 3 的網格，每格 56 像素加 1 像素分隔線
+	if grid.Bounds().Dx() != 4*57+1 || grid.Bounds().Dy() != 3*57+1 {
+		t.Errorf("grid size %v", grid.Bounds())
+	}
+}
+
+func TestFeatureImagesRejectsNonImageInput(t *testing.T) {
+	model, err := nn.NewNeuralNetwork(5, 2, []int{3}, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FeatureImages(model); err == nil {
+		t.Error("expected an error for a 5-value input")
+	}
+}
+
+func TestWeightHistograms(t *testing.T) {
+	model, err := nn.NewNeuralNetwork(784, 10, []int{16}, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	panels := WeightHistograms(model)
+	// hidden0 與 output 各有 weight 與 bias
+	if len(panels.Charts) != 4 {
+		t.Errorf("got %d histograms", len(panels.Charts))
+	}
+}